@@ -0,0 +1,173 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+
+	"github.com/bbq191/dotfiles-go/internal/config"
+	"github.com/bbq191/dotfiles-go/internal/installer"
+	"github.com/spf13/cobra"
+)
+
+var (
+	bundleOutput  string
+	bundleProfile string
+	bundleDryRun  bool
+)
+
+// bundleCmd 离线安装包命令
+var bundleCmd = &cobra.Command{
+	Use:   "bundle",
+	Short: "创建和安装离线包（适用于无网络的机器）",
+}
+
+// bundleCreateCmd 创建离线包命令
+var bundleCreateCmd = &cobra.Command{
+	Use:   "create [包名...]",
+	Short: "下载清单中的包文件到离线包目录",
+	Long: `下载包清单（或指定包）的包文件到目录中，供无网络的机器安装。
+
+执行内容:
+  • 官方仓库包及其完整依赖通过 pacman -Syw 下载到 repo/
+    （使用空的临时数据库，本机已安装的依赖也会下载）
+  • AUR 包审查 PKGBUILD 后通过 yay -G 和 makepkg 构建到 aur/
+  • 写入 bundle.json（包列表和校验和）和 SHA256SUMS
+
+AUR 包的构建依赖（makedepends 等）需要已安装在本机，缺少时会列出并停止，
+不会自动安装到本机。
+
+示例:
+  dotfiles bundle create -o ./bundle                 # 打包当前平台适用的清单包
+  dotfiles bundle create -o ./bundle --profile work  # 打包 profile 选中的包
+  dotfiles bundle create -o ./bundle git neovim      # 只打包指定的包`,
+	RunE: runBundleCreate,
+}
+
+// bundleInstallCmd 安装离线包命令
+var bundleInstallCmd = &cobra.Command{
+	Use:   "install <目录>",
+	Short: "校验离线包后通过 pacman -U 安装",
+	Long: `校验离线包中所有文件的 SHA256，全部通过后使用 pacman -U 安装，不访问网络。
+
+清单中的路径必须位于离线包目录内并与 SHA256SUMS 一致，目录中多出的包文件会导致校验失败。
+只有清单中的包作为显式安装，新安装的依赖标记为依赖（pacman -Qdt 可识别孤立包）。
+
+示例:
+  dotfiles bundle install ./bundle            # 校验并安装
+  dotfiles bundle install ./bundle --dry-run  # 只校验，不安装`,
+	Args: cobra.ExactArgs(1),
+	RunE: runBundleInstall,
+}
+
+func init() {
+	rootCmd.AddCommand(bundleCmd)
+	bundleCmd.AddCommand(bundleCreateCmd)
+	bundleCmd.AddCommand(bundleInstallCmd)
+
+	bundleCreateCmd.Flags().StringVarP(&bundleOutput, "output", "o", "dotfiles-bundle", "离线包输出目录")
+	bundleCreateCmd.Flags().StringVar(&bundleProfile, "profile", "", "使用包清单中的 profile（默认 shared.json 中的 profile）")
+	bundleInstallCmd.Flags().BoolVar(&bundleDryRun, "dry-run", false, "只校验离线包，不安装")
+}
+
+func runBundleCreate(cmd *cobra.Command, args []string) error {
+	logger := GetLogger()
+
+	if _, err := exec.LookPath("pacman"); err != nil {
+		return fmt.Errorf("❌ 离线包仅支持使用 pacman 的系统")
+	}
+	if bundleProfile != "" && len(args) > 0 {
+		return fmt.Errorf("❌ --profile 不能与包名同时使用")
+	}
+
+	exportXDGEnvironment(logger)
+	dotfilesConfig, err := config.NewConfigLoader(getConfigDir(), logger).LoadConfig()
+	if err != nil {
+		return fmt.Errorf("加载配置失败: %w", err)
+	}
+	if dotfilesConfig.Packages == nil {
+		return fmt.Errorf("包配置未正确加载")
+	}
+
+	inst := installer.NewInstaller(logger)
+	inst.InitializeManagers()
+	inst.SetPackagesConfig(dotfilesConfig.Packages)
+	inst.SetPlatformInfo(detectPlatformInfo(logger))
+	configureAURReview(inst, dotfilesConfig.Packages, logger)
+
+	packages := args
+	profile := ""
+	if len(packages) == 0 {
+		packages = inst.ManifestPackageNames()
+		if profile = activeProfile(bundleProfile, dotfilesConfig); profile != "" {
+			if packages, err = inst.ProfilePackageNames(profile); err != nil {
+				return fmt.Errorf("❌ %w", err)
+			}
+			fmt.Printf("🧩 使用 profile %s\n", profile)
+		}
+	}
+	if len(packages) == 0 {
+		return fmt.Errorf("❌ 没有需要打包的包")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// pacman -Syw 需要 root 权限
+	elevator := startElevation(ctx, logger)
+	defer elevator.Stop()
+
+	fmt.Printf("📦 创建离线包: %d 个包 → %s\n", len(packages), bundleOutput)
+	manifest, err := inst.CreateBundle(ctx, packages, installer.BundleOptions{Dir: bundleOutput, Profile: profile})
+	if err != nil {
+		return fmt.Errorf("❌ 创建离线包失败: %w", err)
+	}
+
+	repo, aur := 0, 0
+	for _, pkg := range manifest.Packages {
+		if pkg.Source == installer.BundleSourceAUR {
+			aur++
+		} else {
+			repo++
+		}
+	}
+	fmt.Printf("✅ 离线包已创建: 官方仓库 %d 个, AUR %d 个, 共 %d 个文件\n", repo, aur, len(manifest.Files))
+	if len(manifest.Skipped) > 0 {
+		fmt.Printf("⚠️  未打包 %d 个包（没有 pacman/yay 映射）: %v\n", len(manifest.Skipped), manifest.Skipped)
+	}
+	return nil
+}
+
+func runBundleInstall(cmd *cobra.Command, args []string) error {
+	logger := GetLogger()
+	dir := args[0]
+
+	// 校验通过后才请求 root 权限
+	manifest, err := installer.VerifyBundle(dir)
+	if err != nil {
+		return fmt.Errorf("❌ %w", err)
+	}
+
+	if bundleDryRun {
+		fmt.Printf("✅ 离线包校验通过: %d 个包, %d 个文件（创建于 %s）\n",
+			len(manifest.Packages), len(manifest.Files), manifest.Created.Format("2006-01-02 15:04:05"))
+		for _, pkg := range manifest.Packages {
+			fmt.Printf("  • %s (%s: %s)\n", pkg.Name, pkg.Source, pkg.Package)
+		}
+		return nil
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	elevator := startElevation(ctx, logger)
+	defer elevator.Stop()
+
+	if err := installer.NewInstaller(logger).InstallBundle(ctx, dir, manifest); err != nil {
+		return fmt.Errorf("❌ %w", err)
+	}
+	fmt.Printf("✅ 已从离线包安装 %d 个包\n", len(manifest.Packages))
+	return nil
+}
@@ -0,0 +1,368 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/bbq191/dotfiles-go/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	configShowFormat   string
+	configShowResolved bool
+	configConvertTo    string
+	configConvertOut   string
+	configConvertForce bool
+	configMigrateDry   bool
+	configMigrateYes   bool
+)
+
+// configCmd 配置管理命令
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "查看和管理配置文件",
+}
+
+// configShowCmd 显示合并后的配置命令
+var configShowCmd = &cobra.Command{
+	Use:   "show packages | show --resolved [配置]",
+	Short: "显示合并后的配置及每项的来源文件",
+	Long: `显示按 extends/include 合并后的配置，并标注每项来自哪个文件。
+
+--resolved 显示当前平台实际使用的完整配置（shared、zsh_integration、packages、advanced_functions，
+可以只指定其中一种）：路径值按平台取值，环境变量已展开，并补充加载时的默认值。
+每个叶子值标注来源: 文件:行号、选用的平台键、读取的环境变量或默认值；密钥引用不解密。
+
+包配置文件可以通过顶层指令组合其他文件（路径相对于当前文件）:
+  "extends": "linux.json"            继承的基础文件
+  "include": ["common/dev.json"]     额外合并的文件，按顺序覆盖 extends
+
+各配置层中的同名文件按顺序合并，后面的层优先:
+  /etc/dotfiles → $XDG_CONFIG_HOME/dotfiles → configs/ → configs/hosts/<主机名>/
+密钥不分层: 所有层中的 {{ secret }} 引用都从 configs/secrets.age 解密。
+
+合并规则: 对象逐键深度合并，数组和标量整体替换，后合并的文件优先。
+删除继承的项: 将值设为 "$delete"，或在对象中写 "$delete": true。
+
+示例:
+  dotfiles config show packages                 # 带来源标注的合并结果
+  dotfiles config show packages --format json   # 只输出合并后的 JSON
+  dotfiles config show --resolved               # 当前平台解析后的完整配置
+  dotfiles config show --resolved shared --format yaml`,
+	Args:      cobra.MaximumNArgs(1),
+	ValidArgs: config.SchemaNames(),
+	RunE:      runConfigShow,
+}
+
+// configConvertCmd 配置文件格式转换命令
+var configConvertCmd = &cobra.Command{
+	Use:   "convert <文件>",
+	Short: "在 JSON、YAML 和 TOML 之间转换配置文件",
+	Long: `将配置文件转换为另一种格式，源格式由扩展名判断（.json、.yaml、.yml、.toml）。
+
+shared、zsh_integration、advanced_functions 和 packages/* 都可以使用任意一种格式，
+同名文件只能保留一种格式。转换保持键的顺序（TOML 输入按键名排序），不保留注释，
+但 schema 引用（见 dotfiles schema）会转换为目标格式的写法；
+TOML 不支持 null，转换为 TOML 时省略 null 值。extends/include 中的文件名不会自动修改。
+
+示例:
+  dotfiles config convert configs/shared.json --to yaml           # 生成 configs/shared.yaml
+  dotfiles config convert configs/packages/arch.json --to toml -o -  # 输出到标准输出
+  dotfiles config convert configs/shared.yaml --to json --force    # 覆盖已存在的 shared.json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runConfigConvert,
+}
+
+// configMigrateCmd 配置格式迁移命令
+var configMigrateCmd = &cobra.Command{
+	Use:   "migrate [文件...]",
+	Short: "将旧版本格式的配置文件升级到当前版本",
+	Long: `配置文件用顶层的 "version" 键声明格式版本（没有时按 1.0.0 处理），
+加载到旧版本的文件时会提示运行本命令。
+
+默认检查各配置层目录中的 shared、zsh_integration、advanced_functions 和 packages/*，
+也可以指定文件。先显示每个文件的修改说明和差异，确认后原地写入，
+原文件备份为 <文件>.<时间>.bak。JSON 保留原有的缩进和键顺序，YAML 保留注释，
+TOML 的注释（schema 引用除外）不会保留。
+
+示例:
+  dotfiles config migrate --dry-run                 # 只显示差异
+  dotfiles config migrate                           # 确认后升级
+  dotfiles config migrate configs/shared.json -y    # 不询问直接升级指定文件`,
+	RunE: runConfigMigrate,
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configShowCmd)
+	configCmd.AddCommand(configConvertCmd)
+	configCmd.AddCommand(configMigrateCmd)
+
+	configShowCmd.Flags().StringVar(&configShowFormat, "format", "annotated", "输出格式: annotated、json 或 yaml（yaml 只用于 --resolved）")
+	configShowCmd.Flags().BoolVar(&configShowResolved, "resolved", false, "显示当前平台解析后的完整配置及每个值的来源")
+	configConvertCmd.Flags().StringVar(&configConvertTo, "to", "", "目标格式: json、yaml 或 toml")
+	configConvertCmd.Flags().StringVarP(&configConvertOut, "output", "o", "", "输出文件（默认替换源文件扩展名，- 表示标准输出）")
+	configConvertCmd.Flags().BoolVar(&configConvertForce, "force", false, "覆盖已存在的输出文件")
+	_ = configConvertCmd.MarkFlagRequired("to")
+	configMigrateCmd.Flags().BoolVar(&configMigrateDry, "dry-run", false, "只显示差异，不写入文件")
+	configMigrateCmd.Flags().BoolVarP(&configMigrateYes, "yes", "y", false, "不询问，直接写入")
+}
+
+func runConfigShow(cmd *cobra.Command, args []string) error {
+	if configShowResolved {
+		return runConfigShowResolved(args)
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("❌ 请指定要显示的配置: packages，或使用 --resolved 显示完整配置")
+	}
+	if args[0] != "packages" {
+		return fmt.Errorf("❌ 不支持的配置: %s（可用: packages）", args[0])
+	}
+
+	logger := GetLogger()
+	configDir := getConfigDir()
+	_, composed, err := config.NewConfigLoader(configDir, logger).LoadPackagesComposition()
+	if err != nil {
+		return fmt.Errorf("加载包配置失败: %w", err)
+	}
+
+	switch configShowFormat {
+	case "json":
+		data, err := json.MarshalIndent(composed.Data, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+	case "annotated":
+		files := make([]string, 0, len(composed.Files))
+		for _, file := range composed.Files {
+			files = append(files, relativeConfigPath(configDir, file))
+		}
+		fmt.Printf("📦 包配置合并顺序: %s\n\n", strings.Join(files, " → "))
+
+		var lines []annotatedLine
+		appendAnnotatedObject(&lines, composed, composed.Data, "", 0, configDir)
+		printAnnotatedLines(lines)
+	default:
+		return fmt.Errorf("❌ 不支持的输出格式: %s（可用: annotated, json）", configShowFormat)
+	}
+	return nil
+}
+
+func runConfigConvert(cmd *cobra.Command, args []string) error {
+	source := args[0]
+	from, err := config.FormatFromPath(source)
+	if err != nil {
+		return fmt.Errorf("❌ %w", err)
+	}
+	to, err := config.ParseFormat(configConvertTo)
+	if err != nil {
+		return fmt.Errorf("❌ 不支持的目标格式: %s（可用: json, yaml, toml）", configConvertTo)
+	}
+
+	data, err := os.ReadFile(source)
+	if err != nil {
+		return fmt.Errorf("❌ 读取 %s 失败: %w", source, err)
+	}
+	converted, err := config.ConvertConfig(data, from, to)
+	if err != nil {
+		return fmt.Errorf("❌ 转换 %s 失败: %w", source, err)
+	}
+
+	output := configConvertOut
+	if output == "-" {
+		_, err := os.Stdout.Write(converted)
+		return err
+	}
+	if output == "" {
+		output = strings.TrimSuffix(source, filepath.Ext(source)) + "." + string(to)
+	}
+	if filepath.Clean(output) == filepath.Clean(source) {
+		return fmt.Errorf("❌ 输出文件与源文件相同: %s", output)
+	}
+	if _, err := os.Stat(output); err == nil && !configConvertForce {
+		return fmt.Errorf("❌ %s 已存在，使用 --force 覆盖", output)
+	}
+
+	if err := os.WriteFile(output, converted, 0644); err != nil {
+		return fmt.Errorf("❌ 写入 %s 失败: %w", output, err)
+	}
+	fmt.Printf("✅ 已转换: %s → %s\n", source, output)
+	if strings.TrimSuffix(output, filepath.Ext(output)) == strings.TrimSuffix(source, filepath.Ext(source)) {
+		fmt.Printf("💡 同名配置只能保留一种格式，确认无误后请删除 %s\n", source)
+	}
+	return nil
+}
+
+func runConfigMigrate(cmd *cobra.Command, args []string) error {
+	configDir := getConfigDir()
+	files, err := migrateConfigFiles(configDir, args)
+	if err != nil {
+		return fmt.Errorf("❌ %w", err)
+	}
+
+	var migrations []*config.Migration
+	for _, file := range files {
+		migration, err := config.MigrateConfigFile(file)
+		if err != nil {
+			return fmt.Errorf("❌ %w", err)
+		}
+		if !migration.Changed() {
+			continue
+		}
+		migrations = append(migrations, migration)
+
+		name := relativeConfigPath(configDir, file.Path)
+		fmt.Printf("📄 %s: %s → %s\n", name, migration.From, config.CurrentConfigVersion)
+		for _, change := range migration.Changes {
+			fmt.Printf("   • %s\n", change)
+		}
+		fmt.Printf("\n%s\n", config.UnifiedDiff(name, migration.Before, migration.After))
+	}
+
+	if len(migrations) == 0 {
+		fmt.Printf("✅ %d 个配置文件都已是当前格式版本 %s\n", len(files), config.CurrentConfigVersion)
+		return nil
+	}
+	if configMigrateDry {
+		fmt.Printf("💡 预览模式，%d 个文件未修改\n", len(migrations))
+		return nil
+	}
+	if !configMigrateYes {
+		if !isTerminal() {
+			return fmt.Errorf("❌ 非交互环境请使用 --yes 确认写入，或使用 --dry-run 预览")
+		}
+		confirmed := false
+		prompt := &survey.Confirm{Message: fmt.Sprintf("升级以上 %d 个文件?", len(migrations))}
+		if err := survey.AskOne(prompt, &confirmed); err != nil || !confirmed {
+			fmt.Println("已取消")
+			return nil
+		}
+	}
+
+	for _, migration := range migrations {
+		backup, err := migration.Apply()
+		if err != nil {
+			return fmt.Errorf("❌ %w", err)
+		}
+		fmt.Printf("✅ 已升级 %s（备份: %s）\n", relativeConfigPath(configDir, migration.File.Path), relativeConfigPath(configDir, backup))
+	}
+	return nil
+}
+
+// migrateConfigFiles 返回要迁移的配置文件：指定的文件，或各配置层目录中的所有配置文件
+func migrateConfigFiles(configDir string, paths []string) ([]config.ConfigFile, error) {
+	var files []config.ConfigFile
+	for _, path := range paths {
+		kind, err := config.ConfigKindFromPath(path)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, config.ConfigFile{Path: path, Kind: kind})
+	}
+	if len(paths) > 0 {
+		return files, nil
+	}
+
+	for _, layer := range config.NewConfigLoader(configDir, GetLogger()).ActiveLayers() {
+		if layer.Dir == "" {
+			continue
+		}
+		layerFiles, err := config.ConfigFiles(layer.Dir, config.SchemaNames())
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, layerFiles...)
+	}
+	return files, nil
+}
+
+// annotatedLine 带来源标注的一行输出
+type annotatedLine struct {
+	text   string
+	source string
+}
+
+// appendAnnotatedObject 以 JSON 形式输出对象，来源与上级不同的键标注来源文件
+func appendAnnotatedObject(lines *[]annotatedLine, composed *config.ComposedConfig, object map[string]interface{}, path string, depth int, configDir string) {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	indent := strings.Repeat("  ", depth+1)
+	if depth == 0 {
+		*lines = append(*lines, annotatedLine{text: "{"})
+	}
+	for idx, key := range keys {
+		childPath := key
+		if path != "" {
+			childPath = path + "." + key
+		}
+		source := composed.Source(childPath)
+		if path != "" && source == composed.Source(path) {
+			source = ""
+		}
+		comma := ","
+		if idx == len(keys)-1 {
+			comma = ""
+		}
+
+		keyJSON, _ := json.Marshal(key)
+		if child, ok := object[key].(map[string]interface{}); ok && len(child) > 0 {
+			*lines = append(*lines, annotatedLine{text: fmt.Sprintf("%s%s: {", indent, keyJSON), source: relativeConfigPath(configDir, source)})
+			appendAnnotatedObject(lines, composed, child, childPath, depth+1, configDir)
+			*lines = append(*lines, annotatedLine{text: indent + "}" + comma})
+			continue
+		}
+
+		value, _ := json.Marshal(object[key])
+		*lines = append(*lines, annotatedLine{text: fmt.Sprintf("%s%s: %s%s", indent, keyJSON, value, comma), source: relativeConfigPath(configDir, source)})
+	}
+	if depth == 0 {
+		*lines = append(*lines, annotatedLine{text: "}"})
+	}
+}
+
+// printAnnotatedLines 对齐输出来源标注
+func printAnnotatedLines(lines []annotatedLine) {
+	width := 0
+	for _, line := range lines {
+		if line.source != "" && len([]rune(line.text)) > width {
+			width = len([]rune(line.text))
+		}
+	}
+	if width > 72 {
+		width = 72
+	}
+
+	for _, line := range lines {
+		if line.source == "" {
+			fmt.Println(line.text)
+			continue
+		}
+		padding := width - len([]rune(line.text))
+		if padding < 0 {
+			padding = 0
+		}
+		fmt.Printf("%s%s  # %s\n", line.text, strings.Repeat(" ", padding), line.source)
+	}
+}
+
+// relativeConfigPath 返回相对于配置目录的路径，无法计算时原样返回
+func relativeConfigPath(configDir, path string) string {
+	if path == "" {
+		return ""
+	}
+	if rel, err := filepath.Rel(configDir, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
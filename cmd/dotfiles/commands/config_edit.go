@@ -0,0 +1,252 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/bbq191/dotfiles-go/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	configGetLayer     string
+	configEditLayer    string
+	configEditPlatform string
+)
+
+// configPathHelp get/set/unset 共用的路径说明
+const configPathHelp = `路径用点分隔，第一段选择配置文件（省略时为 shared）:
+  user.email                      shared
+  zsh.proxy.active_profile        zsh_integration
+  packages.categories.dev.priority  当前平台的包配置
+  functions.mkcd.description      advanced_functions
+键名中的点写作 \.，如 packages.categories.dev.packages.python3\.12
+
+paths.* 等路径值可以是字符串，也可以按平台（linux、macos、windows、default）或 shell 分别设置，
+使用 --platform 或直接写平台键，如 paths.projects.windows。`
+
+// configGetCmd 读取配置项
+var configGetCmd = &cobra.Command{
+	Use:   "get <路径>",
+	Short: "读取配置项",
+	Long: `读取配置项的值：字符串原样输出，其他值输出为 JSON。默认读取各配置层合并后的值，
+--layer 只读取指定配置层的文件。
+
+` + configPathHelp + `
+
+示例:
+  dotfiles config get user.email
+  dotfiles config get paths.projects --platform windows
+  dotfiles config get zsh.proxy --layer host`,
+	Args: cobra.ExactArgs(1),
+	RunE: runConfigGet,
+
+	SilenceUsage: true,
+}
+
+// configSetCmd 设置配置项
+var configSetCmd = &cobra.Command{
+	Use:   "set <路径> <值>",
+	Short: "设置配置项（保留文件的格式和键顺序）",
+	Long: `设置配置项并写回配置层中的文件（默认 repo 层，文件不存在时创建 .json）。
+值按字段类型解析：字符串原样使用，布尔值和数字按字面解析，对象和数组写成 JSON。
+写入前按 JSON Schema 检查，修改引入问题时不写入。
+
+只修改涉及的键：JSON 保留原有的缩进、空行和键顺序，YAML 保留注释，
+TOML 的注释（schema 引用除外）不会保留。
+
+` + configPathHelp + `
+
+示例:
+  dotfiles config set zsh.proxy.active_profile work
+  dotfiles config set paths.projects 'D:\Projects' --platform windows
+  dotfiles config set zsh.performance.async_loading false --layer host
+  dotfiles config set environment '{"LANG": "en_US.UTF-8"}'`,
+	Args: cobra.ExactArgs(2),
+	RunE: runConfigSet,
+
+	SilenceUsage: true,
+}
+
+// configUnsetCmd 删除配置项
+var configUnsetCmd = &cobra.Command{
+	Use:   "unset <路径>",
+	Short: "删除配置层文件中的配置项",
+	Long: `从配置层中的文件（默认 repo 层）删除配置项，合并后将使用其他层的值。
+要在合并结果中删除下层继承的项，请使用 set <路径> '$delete'。
+
+` + configPathHelp,
+	Args: cobra.ExactArgs(1),
+	RunE: runConfigUnset,
+
+	SilenceUsage: true,
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{configGetCmd, configSetCmd, configUnsetCmd} {
+		configCmd.AddCommand(cmd)
+		cmd.Flags().StringVar(&configEditPlatform, "platform", "", "路径值的平台或 shell 键（linux、macos、windows、default、zsh、bash、powershell）")
+	}
+	configGetCmd.Flags().StringVar(&configGetLayer, "layer", "", "只读取指定配置层: system、user、repo 或 host")
+	configSetCmd.Flags().StringVar(&configEditLayer, "layer", config.LayerRepo, "写入的配置层: system、user、repo 或 host")
+	configUnsetCmd.Flags().StringVar(&configEditLayer, "layer", config.LayerRepo, "修改的配置层: system、user、repo 或 host")
+}
+
+func runConfigGet(cmd *cobra.Command, args []string) error {
+	path, err := config.ParseConfigPath(args[0])
+	if err != nil {
+		return fmt.Errorf("❌ %w", err)
+	}
+	if configEditPlatform != "" && !path.IsPathValue() {
+		return fmt.Errorf("❌ --platform 只能用于路径值（如 paths.projects），%s 不是", args[0])
+	}
+
+	loader := config.NewConfigLoader(getConfigDir(), GetLogger())
+	var value any
+	var found bool
+	if configGetLayer == "" {
+		composed, err := loader.Compose(path.Kind)
+		if err != nil {
+			return fmt.Errorf("❌ 加载 %s 失败: %w", path.Kind, err)
+		}
+		value, found = config.LookupValue(composed.Data, path.Keys)
+	} else {
+		doc, err := loadLayerDocument(loader, configGetLayer, path.Kind)
+		if err != nil {
+			return err
+		}
+		if doc != nil {
+			value, found = doc.Get(path.Keys)
+		}
+	}
+	if !found {
+		return fmt.Errorf("❌ 未设置: %s", args[0])
+	}
+
+	if configEditPlatform != "" {
+		resolved, err := config.ResolvePathValue(value, configEditPlatform)
+		if err != nil {
+			return fmt.Errorf("❌ %s: %w", args[0], err)
+		}
+		value = resolved
+	}
+	fmt.Println(config.FormatConfigValue(value))
+	return nil
+}
+
+func runConfigSet(cmd *cobra.Command, args []string) error {
+	path, err := configEditPath(args[0])
+	if err != nil {
+		return err
+	}
+	value, err := path.ParseConfigValue(args[1])
+	if err != nil {
+		return fmt.Errorf("❌ %w", err)
+	}
+
+	configDir := getConfigDir()
+	loader := config.NewConfigLoader(configDir, GetLogger())
+	doc, err := loadLayerDocument(loader, configEditLayer, path.Kind)
+	if err != nil {
+		return err
+	}
+	if doc == nil {
+		file, _ := loader.LayerFile(configEditLayer, path.Kind)
+		if doc, err = config.NewConfigDocument(file); err != nil {
+			return fmt.Errorf("❌ %w", err)
+		}
+	}
+
+	if err := editConfigDocument(doc, path.Kind, func() error { return config.SetConfigValue(doc, path, value) }); err != nil {
+		return err
+	}
+	fmt.Printf("✅ %s = %s（%s）\n", args[0], config.FormatConfigValue(value), relativeConfigPath(configDir, doc.Path))
+	return nil
+}
+
+func runConfigUnset(cmd *cobra.Command, args []string) error {
+	path, err := configEditPath(args[0])
+	if err != nil {
+		return err
+	}
+
+	configDir := getConfigDir()
+	doc, err := loadLayerDocument(config.NewConfigLoader(configDir, GetLogger()), configEditLayer, path.Kind)
+	if err != nil {
+		return err
+	}
+	if doc == nil {
+		return fmt.Errorf("❌ 配置层 %s 中没有 %s 配置文件", configEditLayer, path.Kind)
+	}
+	if _, found := doc.Get(path.Keys); !found {
+		return fmt.Errorf("❌ %s 中没有 %s", relativeConfigPath(configDir, doc.Path), args[0])
+	}
+
+	if err := editConfigDocument(doc, path.Kind, func() error {
+		_, err := doc.Delete(path.Keys)
+		return err
+	}); err != nil {
+		return err
+	}
+	fmt.Printf("✅ 已删除 %s（%s）\n", args[0], relativeConfigPath(configDir, doc.Path))
+	return nil
+}
+
+// configEditPath 解析路径，--platform 时在路径值之后追加平台键
+func configEditPath(arg string) (config.ConfigPath, error) {
+	path, err := config.ParseConfigPath(arg)
+	if err != nil {
+		return path, fmt.Errorf("❌ %w", err)
+	}
+	if configEditPlatform == "" {
+		return path, nil
+	}
+	if !path.IsPathValue() {
+		return path, fmt.Errorf("❌ --platform 只能用于路径值（如 paths.projects），%s 不是", arg)
+	}
+	path.Keys = append(path.Keys, configEditPlatform)
+	return path, nil
+}
+
+// loadLayerDocument 读取配置层中的配置文件，文件不存在时返回 nil
+func loadLayerDocument(loader *config.ConfigLoader, layer, kind string) (*config.ConfigDocument, error) {
+	file, err := loader.LayerFile(layer, kind)
+	if err != nil {
+		return nil, fmt.Errorf("❌ %w", err)
+	}
+	doc, err := config.LoadConfigDocument(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("❌ 读取 %s 失败: %w", file, err)
+	}
+	return doc, nil
+}
+
+// editConfigDocument 执行修改并按 schema 检查，只有修改没有引入新问题时才写入文件
+func editConfigDocument(doc *config.ConfigDocument, kind string, edit func() error) error {
+	before, err := doc.CheckSchema(kind)
+	if err != nil {
+		return fmt.Errorf("❌ %w", err)
+	}
+	if err := edit(); err != nil {
+		return fmt.Errorf("❌ %w", err)
+	}
+	after, err := doc.CheckSchema(kind)
+	if err != nil {
+		return fmt.Errorf("❌ %w", err)
+	}
+
+	if introduced := after.Introduced(before); len(introduced) > 0 {
+		for _, finding := range introduced {
+			fmt.Fprintf(os.Stderr, "❌ %s\n", finding)
+		}
+		return fmt.Errorf("❌ 修改不符合 %s，未写入 %s", config.SchemaFileName(kind), doc.Path)
+	}
+	if err := doc.Save(); err != nil {
+		return fmt.Errorf("❌ %w", err)
+	}
+	return nil
+}
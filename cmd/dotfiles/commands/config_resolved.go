@@ -0,0 +1,245 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bbq191/dotfiles-go/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+// resolvedJSON --resolved --format json 的输出结构
+type resolvedJSON struct {
+	Platform     string                        `json:"platform"`
+	PathPlatform string                        `json:"path_platform"`
+	Config       map[string]interface{}        `json:"config"`
+	Files        map[string][]string           `json:"files"`
+	Notes        map[string]string             `json:"notes,omitempty"`
+	Sources      map[string]config.ValueSource `json:"sources"` // 配置种类.路径 -> 来源
+}
+
+func runConfigShowResolved(args []string) error {
+	if len(args) > 0 && !slices.Contains(config.SchemaNames(), args[0]) {
+		return fmt.Errorf("❌ 不支持的配置: %s（可用: %s）", args[0], strings.Join(config.SchemaNames(), ", "))
+	}
+
+	configDir := getConfigDir()
+	resolved, err := config.NewConfigLoader(configDir, GetLogger()).Resolve()
+	if err != nil {
+		return fmt.Errorf("❌ 加载配置失败: %w", err)
+	}
+	var sections []*config.ResolvedSection
+	for _, section := range resolved.Sections {
+		if len(args) == 0 || section.Kind == args[0] {
+			sections = append(sections, section)
+		}
+	}
+	if len(sections) == 0 {
+		return fmt.Errorf("❌ 没有 %s 配置文件", args[0])
+	}
+
+	switch configShowFormat {
+	case "annotated":
+		fmt.Printf("🖥️  平台: %s（路径值使用 %s 键）\n", resolved.Platform, resolved.PathPlatform)
+		for _, section := range sections {
+			files := make([]string, 0, len(section.Files))
+			for _, file := range section.Files {
+				files = append(files, relativeConfigPath(configDir, file))
+			}
+			fmt.Printf("\n📄 %s: %s\n", section.Kind, strings.Join(files, " → "))
+			if section.Note != "" {
+				fmt.Printf("ℹ️  %s\n", section.Note)
+			}
+
+			var lines []annotatedLine
+			appendResolvedObject(&lines, section, section.Data, "", 0, configDir)
+			printAnnotatedLines(lines)
+		}
+	case "json":
+		output := resolvedJSON{
+			Platform:     resolved.Platform,
+			PathPlatform: resolved.PathPlatform,
+			Config:       make(map[string]interface{}),
+			Files:        make(map[string][]string),
+			Sources:      make(map[string]config.ValueSource),
+		}
+		for _, section := range sections {
+			output.Config[section.Kind] = section.Data
+			for _, file := range section.Files {
+				output.Files[section.Kind] = append(output.Files[section.Kind], relativeConfigPath(configDir, file))
+			}
+			if section.Note != "" {
+				if output.Notes == nil {
+					output.Notes = make(map[string]string)
+				}
+				output.Notes[section.Kind] = section.Note
+			}
+			for path, source := range section.Sources {
+				source.File = relativeConfigPath(configDir, source.File)
+				output.Sources[section.Kind+"."+path] = source
+			}
+		}
+		data, err := json.MarshalIndent(output, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+	case "yaml":
+		root := &yaml.Node{Kind: yaml.MappingNode}
+		for _, section := range sections {
+			files := make([]string, 0, len(section.Files))
+			for _, file := range section.Files {
+				files = append(files, relativeConfigPath(configDir, file))
+			}
+			comment := strings.Join(files, " → ")
+			if section.Note != "" {
+				comment += "\n" + section.Note
+			}
+			key := &yaml.Node{Kind: yaml.ScalarNode, Value: section.Kind, HeadComment: comment}
+			value, err := resolvedYAMLNode(section, section.Data, "", configDir)
+			if err != nil {
+				return err
+			}
+			root.Content = append(root.Content, key, value)
+		}
+
+		encoder := yaml.NewEncoder(os.Stdout)
+		encoder.SetIndent(2)
+		fmt.Printf("# 平台: %s（路径值使用 %s 键）\n", resolved.Platform, resolved.PathPlatform)
+		if err := encoder.Encode(root); err != nil {
+			return err
+		}
+		return encoder.Close()
+	default:
+		return fmt.Errorf("❌ 不支持的输出格式: %s（可用: annotated, json, yaml）", configShowFormat)
+	}
+	return nil
+}
+
+// appendResolvedObject 以 JSON 形式输出解析后的对象，每个叶子值标注来源
+func appendResolvedObject(lines *[]annotatedLine, section *config.ResolvedSection, object map[string]interface{}, path string, depth int, configDir string) {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	indent := strings.Repeat("  ", depth+1)
+	if depth == 0 {
+		*lines = append(*lines, annotatedLine{text: "{"})
+	}
+	for idx, key := range keys {
+		childPath := key
+		if path != "" {
+			childPath = path + "." + key
+		}
+		comma := ","
+		if idx == len(keys)-1 {
+			comma = ""
+		}
+
+		keyJSON, _ := json.Marshal(key)
+		if child, ok := object[key].(map[string]interface{}); ok && len(child) > 0 {
+			*lines = append(*lines, annotatedLine{text: fmt.Sprintf("%s%s: {", indent, keyJSON)})
+			appendResolvedObject(lines, section, child, childPath, depth+1, configDir)
+			*lines = append(*lines, annotatedLine{text: indent + "}" + comma})
+			continue
+		}
+
+		value, _ := json.Marshal(object[key])
+		source := formatValueSource(configDir, section.Sources[childPath])
+		*lines = append(*lines, annotatedLine{text: fmt.Sprintf("%s%s: %s%s", indent, keyJSON, value, comma), source: source})
+	}
+	if depth == 0 {
+		*lines = append(*lines, annotatedLine{text: "}"})
+	}
+}
+
+// resolvedYAMLNode 将解析后的对象转换为 YAML 节点，来源写在叶子值的行尾注释中
+func resolvedYAMLNode(section *config.ResolvedSection, object map[string]interface{}, path, configDir string) (*yaml.Node, error) {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for _, key := range keys {
+		childPath := key
+		if path != "" {
+			childPath = path + "." + key
+		}
+		keyNode := &yaml.Node{Kind: yaml.ScalarNode, Value: key}
+
+		if child, ok := object[key].(map[string]interface{}); ok && len(child) > 0 {
+			value, err := resolvedYAMLNode(section, child, childPath, configDir)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, keyNode, value)
+			continue
+		}
+
+		value := &yaml.Node{}
+		if err := value.Encode(yamlValue(object[key])); err != nil {
+			return nil, fmt.Errorf("%s: %w", childPath, err)
+		}
+		if value.Kind == yaml.ScalarNode {
+			value.LineComment = formatValueSource(configDir, section.Sources[childPath])
+		} else {
+			keyNode.LineComment = formatValueSource(configDir, section.Sources[childPath])
+		}
+		node.Content = append(node.Content, keyNode, value)
+	}
+	return node, nil
+}
+
+// yamlValue 将 JSON 解码得到的数字转换为整数（如果是整数），避免 YAML 输出为 1.0e+00 之类的形式
+func yamlValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case float64:
+		if v == float64(int64(v)) {
+			return int64(v)
+		}
+	case []interface{}:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = yamlValue(item)
+		}
+		return items
+	}
+	return value
+}
+
+// formatValueSource 来源的简短说明，如 shared.json:12 · 平台键 windows · $HOME
+func formatValueSource(configDir string, source config.ValueSource) string {
+	var parts []string
+	if source.File != "" {
+		location := relativeConfigPath(configDir, source.File)
+		if source.Line > 0 {
+			location += ":" + strconv.Itoa(source.Line)
+		}
+		parts = append(parts, location)
+	}
+	if source.Default != "" {
+		parts = append(parts, source.Default)
+	}
+	if source.Platform != "" {
+		parts = append(parts, "平台键 "+source.Platform)
+	}
+	if len(source.Env) > 0 {
+		parts = append(parts, "$"+strings.Join(source.Env, " $"))
+	}
+	if source.Secret {
+		parts = append(parts, "密钥引用（未解密）")
+	}
+	if source.Error != "" {
+		parts = append(parts, "⚠️ "+source.Error)
+	}
+	return strings.Join(parts, " · ")
+}
@@ -54,7 +54,7 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 	logger.Info("🎯 开始配置文件生成流程")
 	
 	// 加载配置
-	configLoader := config.NewConfigLoader("configs", logger)
+	configLoader := config.NewConfigLoader(getConfigDir(), logger)
 	cfg, err := configLoader.LoadConfig()
 	if err != nil {
 		logger.Errorf("加载配置失败: %v", err)
@@ -0,0 +1,124 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/bbq191/dotfiles-go/internal/config"
+	"github.com/bbq191/dotfiles-go/internal/installer"
+	"github.com/spf13/cobra"
+)
+
+var (
+	gitCheck      bool
+	gitConfigFile string
+	gitBaseConfig string
+)
+
+// gitCmd git 工具配置命令
+var gitCmd = &cobra.Command{
+	Use:   "git",
+	Short: "管理 git 配置和 gh 扩展",
+}
+
+// gitApplyCmd 应用 git 配置命令
+var gitApplyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "将 git_tools 配置写入 git 配置并安装 gh 扩展",
+	Long: `将 zsh_integration.json 中启用的 git_tools 配置应用到真实的 git 配置。
+
+执行内容:
+  • 合并 conf/git/config 中的配置项
+  • 写入各工具的 git_config（如 delta 的 pager 设置，优先于 conf/git/config）
+  • 安装 extensions 中列出的 gh 扩展（未指定所有者时使用 github/）
+
+配置通过 git config --file 写入 $XDG_CONFIG_HOME/git/config。
+
+示例:
+  dotfiles git apply           # 应用配置并安装扩展
+  dotfiles git apply --check   # 只报告差异，不写入`,
+	RunE: runGitApply,
+}
+
+func init() {
+	rootCmd.AddCommand(gitCmd)
+	gitCmd.AddCommand(gitApplyCmd)
+
+	gitApplyCmd.Flags().BoolVar(&gitCheck, "check", false, "只报告差异，不写入")
+	gitApplyCmd.Flags().StringVar(&gitConfigFile, "file", "", "目标 git 配置文件（默认 $XDG_CONFIG_HOME/git/config）")
+	gitApplyCmd.Flags().StringVar(&gitBaseConfig, "base", "", "合并的基础配置文件（默认 conf/git/config）")
+}
+
+func runGitApply(cmd *cobra.Command, args []string) error {
+	logger := GetLogger()
+
+	exportXDGEnvironment(logger)
+	dotfilesConfig, err := config.NewConfigLoader(getConfigDir(), logger).LoadConfig()
+	if err != nil {
+		return fmt.Errorf("加载配置失败: %w", err)
+	}
+	if dotfilesConfig.ZshConfig == nil {
+		return fmt.Errorf("❌ 未加载 zsh_integration.json，无法读取 git_tools 配置")
+	}
+
+	configFile := gitConfigFile
+	if configFile == "" {
+		if configFile, err = installer.DefaultGitConfigFile(logger); err != nil {
+			return fmt.Errorf("获取 git 配置路径失败: %w", err)
+		}
+	}
+	baseConfig := gitBaseConfig
+	if baseConfig == "" {
+		// conf/ 与 configs/ 位于仓库根目录下
+		baseConfig = filepath.Join(filepath.Dir(getConfigDir()), "conf", "git", "config")
+	}
+
+	applier := installer.NewGitToolsApplier(dotfilesConfig.ZshConfig.GitTools, logger)
+	report, err := applier.Apply(context.Background(), installer.GitApplyOptions{
+		ConfigFile: configFile,
+		BaseConfig: baseConfig,
+		Check:      gitCheck,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\n🔧 git 配置: %s\n", report.ConfigFile)
+	if len(report.Changes) == 0 {
+		fmt.Println("✅ git 配置已是最新")
+	}
+	for _, change := range report.Changes {
+		current := "（未设置）"
+		if len(change.Current) > 0 {
+			current = strings.Join(change.Current, ", ")
+		}
+		fmt.Printf("  ~ %s: %s → %s  [%s]\n", change.Key, current, strings.Join(change.Desired, ", "), change.Source)
+	}
+
+	if len(report.MissingExtensions) > 0 {
+		fmt.Printf("\n🧩 gh 扩展:\n")
+		for _, extension := range report.MissingExtensions {
+			fmt.Printf("  + %s\n", extension)
+		}
+	}
+
+	if len(report.Errors) > 0 {
+		fmt.Printf("\n⚠️  错误:\n")
+		for _, err := range report.Errors {
+			fmt.Printf("  • %v\n", err)
+		}
+		return fmt.Errorf("❌ %d 项 git 配置应用失败", len(report.Errors))
+	}
+
+	if gitCheck {
+		if len(report.Changes) > 0 || len(report.MissingExtensions) > 0 {
+			return fmt.Errorf("❌ 发现 %d 项配置差异、%d 个缺失的 gh 扩展", len(report.Changes), len(report.MissingExtensions))
+		}
+		return nil
+	}
+
+	fmt.Printf("\n✅ 已写入 %d 项配置，安装 %d 个 gh 扩展\n", len(report.Changes), len(report.Installed))
+	return nil
+}
@@ -3,6 +3,8 @@ package commands
 import (
 	"context"
 	"fmt"
+	"os"
+	"runtime"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -11,6 +13,7 @@ import (
 	"github.com/bbq191/dotfiles-go/internal/installer"
 	"github.com/bbq191/dotfiles-go/internal/interactive"
 	"github.com/bbq191/dotfiles-go/internal/platform"
+	"github.com/bbq191/dotfiles-go/internal/xdg"
 )
 
 var (
@@ -20,6 +23,14 @@ var (
 	dryRun        bool
 	quiet         bool
 	interactiveMode bool
+	
+	installTimeout     time.Duration
+	postInstallTimeout time.Duration
+	batchTimeout       time.Duration
+	runHooks           bool
+	
+	resumeInstall  bool
+	installProfile string
 )
 
 // installCmd 安装软件包命令
@@ -33,7 +44,22 @@ var installCmd = &cobra.Command{
   dotfiles install neovim git fzf     # 安装指定包
   dotfiles install --interactive       # 交互式包选择和安装 ✨
   dotfiles install --force --dry-run  # 预览安装操作
-  dotfiles install --parallel          # 并行安装（开发中）`,
+  dotfiles install --parallel          # 并行安装（开发中）
+  dotfiles install --force --timeout 45m --batch-timeout 2h  # 单包超时后继续安装其余的包
+  dotfiles install --resume            # 继续上次中断的安装
+  dotfiles install --profile work      # 安装包清单中 work profile 选中的包
+  dotfiles install zsh --run-hooks     # 安装后执行包清单中的 post_install 命令
+
+安装过程中按 Ctrl-C 不再开始新的包，等待正在安装的包完成后输出汇总，
+并将未完成的包写入续装文件；再次按 Ctrl-C 立即终止。
+
+未指定包名时安装 --profile（或 shared.json 中 profile）选中的包。
+
+超时默认值可在包清单的 timeouts 中配置（install、post_install、batch），
+单个包可用 timeout、post_install_timeout 覆盖；命令行参数优先级最高。
+
+包清单中的 post_install 命令默认不执行；使用 --run-hooks 时，在整批安装完成后
+依次执行新装包的命令，命令连接终端，可以交互（如 chsh 询问密码）。`,
 	RunE: runInstall,
 }
 
@@ -46,6 +72,12 @@ func init() {
 	installCmd.Flags().BoolVarP(&force, "force", "f", false, "强制重新安装")
 	installCmd.Flags().BoolVar(&dryRun, "dry-run", false, "仅显示将要执行的操作")
 	installCmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "静默模式，不显示进度条")
+	installCmd.Flags().DurationVar(&installTimeout, "timeout", 0, "单个包的安装超时，如 45m（默认使用包清单配置或 30m）")
+	installCmd.Flags().DurationVar(&postInstallTimeout, "post-install-timeout", 0, "单个 post_install 命令的超时（默认使用包清单配置或 5m）")
+	installCmd.Flags().StringVar(&installProfile, "profile", "", "使用包清单中的 profile（默认 shared.json 中的 profile）")
+	installCmd.Flags().BoolVar(&resumeInstall, "resume", false, "只安装上次中断时未完成的包")
+	installCmd.Flags().DurationVar(&batchTimeout, "batch-timeout", 0, "整批安装的超时（默认使用包清单配置，未配置时不限制）")
+	installCmd.Flags().BoolVar(&runHooks, "run-hooks", false, "安装完成后执行新装包的 post_install 命令")
 }
 
 func runInstall(cmd *cobra.Command, args []string) error {
@@ -73,6 +105,20 @@ func runInstall(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("❌ 未找到可用的包管理器，请确保系统已安装 pacman 或 winget")
 	}
 	
+	// 加载包配置（用于AUR审查白名单），失败时使用空配置
+	exportXDGEnvironment(logger)
+	var packagesConfig *config.PackagesConfig
+	dotfilesConfig, configErr := config.NewConfigLoader(getConfigDir(), logger).LoadConfig()
+	if configErr == nil {
+		packagesConfig = dotfilesConfig.Packages
+		configureToolEnvironment(inst, dotfilesConfig, logger)
+	} else {
+		logger.Debugf("加载配置失败，AUR审查不使用信任列表: %v", configErr)
+	}
+	inst.SetPackagesConfig(packagesConfig)
+	inst.SetPlatformInfo(detectPlatformInfo(logger))
+	configureAURReview(inst, packagesConfig, logger)
+	
 	// 设置安装选项
 	opts := installer.InstallOptions{
 		Force:      force,
@@ -81,26 +127,68 @@ func runInstall(cmd *cobra.Command, args []string) error {
 		Quiet:      quiet,
 		Parallel:   parallel,
 		MaxWorkers: maxWorkers,
+		
+		InstallTimeout:     installTimeout,
+		PostInstallTimeout: postInstallTimeout,
+		BatchTimeout:       batchTimeout,
+		RunHooks:           runHooks,
+	}
+	
+	// 继续上次中断的安装
+	resumeFile, err := installer.DefaultResumeFile(logger)
+	if err != nil {
+		return fmt.Errorf("获取续装文件路径失败: %w", err)
+	}
+	var completed []string
+	if resumeInstall {
+		if len(args) > 0 {
+			return fmt.Errorf("❌ --resume 不能与包名同时使用")
+		}
+		state, err := installer.LoadResumeState(resumeFile)
+		if err != nil {
+			return err
+		}
+		args, completed = state.Packages, state.Completed
+		fmt.Printf("⏯️  继续上次中断的安装（%s）: 已完成 %d 个，剩余 %d 个包\n",
+			state.Interrupted.Format("2006-01-02 15:04:05"), len(state.Completed), len(state.Packages))
+	}
+	
+	// 未指定包名时使用 profile 选中的包
+	if installProfile != "" && len(args) > 0 {
+		return fmt.Errorf("❌ --profile 不能与包名同时使用")
+	}
+	if profile := activeProfile(installProfile, dotfilesConfig); profile != "" && len(args) == 0 && !resumeInstall {
+		if configErr != nil {
+			return fmt.Errorf("加载配置失败，无法使用 profile %s: %w", profile, configErr)
+		}
+		if args, err = inst.ProfilePackageNames(profile); err != nil {
+			return fmt.Errorf("❌ %w", err)
+		}
+		fmt.Printf("🧩 使用 profile %s: %d 个包\n", profile, len(args))
 	}
 	
-	// 创建上下文（支持取消）
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
-	defer cancel()
+	// 超时由安装器按包和按批次控制；第一次中断信号停止调度，第二次取消上下文
+	ctx, interrupt := watchInterrupt(logger)
+	defer interrupt.Close()
+	opts.Stop = interrupt.Stop()
 	
 	// 安装包
 	if len(args) == 0 {
-		return fmt.Errorf("❌ 请指定要安装的包名，例如: dotfiles install neovim git")
+		return fmt.Errorf("❌ 请指定要安装的包名（例如: dotfiles install neovim git）或使用 --profile")
 	}
 	
 	logger.Infof("📦 准备安装 %d 个包: %v", len(args), args)
 	
 	if dryRun {
 		fmt.Printf("🔍 预览模式 - 将执行以下操作:\n")
+	} else if inst.RequiresElevation(args) {
+		// 开始时验证一次 sudo，安装期间在后台保持凭据有效
+		elevator := startElevation(ctx, logger)
+		defer elevator.Stop()
 	}
 	
 	// 检查并行安装能力
 	var results []*installer.InstallResult
-	var err error
 	if opts.Parallel {
 		// 创建并行安装器
 		parallelInst := installer.NewParallelInstaller(inst, opts.MaxWorkers)
@@ -124,6 +212,16 @@ func runInstall(cmd *cobra.Command, args []string) error {
 		results, err = inst.InstallPackages(ctx, args, opts)
 	}
 	
+	// 中断后记录未完成的包
+	if !dryRun && (interrupt.Interrupted() || resumeInstall) {
+		if saveErr := saveResumeState(resumeFile, args, completed, results, logger); saveErr != nil {
+			logger.Warnf("保存续装状态失败: %v", saveErr)
+		}
+	}
+	if interrupt.Interrupted() {
+		return fmt.Errorf("❌ 安装已中断")
+	}
+	
 	if err != nil {
 		logger.Errorf("安装过程中出现错误: %v", err)
 		return err
@@ -131,12 +229,19 @@ func runInstall(cmd *cobra.Command, args []string) error {
 	
 	// 检查是否有失败的安装
 	failed := 0
+	timedOut := 0
 	for _, result := range results {
 		if !result.Success {
 			failed++
 		}
+		if result.TimedOut {
+			timedOut++
+		}
 	}
 	
+	if timedOut > 0 {
+		return fmt.Errorf("❌ %d 个包安装失败（其中 %d 个超时）", failed, timedOut)
+	}
 	if failed > 0 {
 		return fmt.Errorf("❌ %d 个包安装失败", failed)
 	}
@@ -145,6 +250,42 @@ func runInstall(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// activeProfile 返回 --profile 指定的 profile，未指定时使用 shared.json 中的 profile
+func activeProfile(flagValue string, dotfilesConfig *config.DotfilesConfig) string {
+	if flagValue != "" || dotfilesConfig == nil {
+		return flagValue
+	}
+	return dotfilesConfig.Profile
+}
+
+// startElevation 验证 sudo 凭据并启动后台刷新，失败时只记录警告（由包管理器报告具体错误）
+func startElevation(ctx context.Context, logger *logrus.Logger) *installer.Elevator {
+	elevator := installer.NewElevator(logger)
+	if err := elevator.Start(ctx, isTerminal()); err != nil {
+		logger.Warnf("⚠️  %v，需要 root 权限的包可能安装失败", err)
+	}
+	return elevator
+}
+
+// saveResumeState 写入未完成的包（completed 为之前已完成的包），全部完成时删除续装文件
+func saveResumeState(resumeFile string, packages, completed []string, results []*installer.InstallResult, logger *logrus.Logger) error {
+	state := installer.NewResumeState(packages, results)
+	state.Completed = append(completed, state.Completed...)
+	if len(state.Packages) == 0 {
+		if err := os.Remove(resumeFile); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		logger.Debugf("所有包已完成，删除续装文件 %s", resumeFile)
+		return nil
+	}
+	
+	if err := state.Save(resumeFile); err != nil {
+		return err
+	}
+	fmt.Printf("💾 剩余 %d 个包已写入 %s，使用 dotfiles install --resume 继续\n", len(state.Packages), resumeFile)
+	return nil
+}
+
 // runInteractiveInstall 执行交互式安装
 func runInteractiveInstall(cmd *cobra.Command, args []string, logger *logrus.Logger) error {
 	logger.Info("🎯 启动交互式包选择模式")
@@ -154,9 +295,9 @@ func runInteractiveInstall(cmd *cobra.Command, args []string, logger *logrus.Log
 		logger.Warn("⚠️  交互模式将忽略命令行中指定的包名，请通过界面选择")
 	}
 	
-	// 创建上下文
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
-	defer cancel()
+	// 超时由安装器按包和按批次控制；第一次中断信号停止调度，第二次取消上下文
+	ctx, interrupt := watchInterrupt(logger)
+	defer interrupt.Close()
 	
 	// 检测平台信息
 	detector := platform.NewDetector()
@@ -166,7 +307,8 @@ func runInteractiveInstall(cmd *cobra.Command, args []string, logger *logrus.Log
 	}
 	
 	// 加载配置
-	configLoader := config.NewConfigLoader("configs", logger)
+	exportXDGEnvironment(logger)
+	configLoader := config.NewConfigLoader(getConfigDir(), logger)
 	dotfilesConfig, err := configLoader.LoadConfig()
 	if err != nil {
 		return fmt.Errorf("加载配置失败: %w", err)
@@ -178,6 +320,16 @@ func runInteractiveInstall(cmd *cobra.Command, args []string, logger *logrus.Log
 		return fmt.Errorf("包配置未正确加载")
 	}
 	
+	// 选择界面只显示 profile 选中且 when 条件满足当前平台的包，安装器仍使用完整清单
+	selectableConfig := packagesConfig
+	if profile := activeProfile(installProfile, dotfilesConfig); profile != "" {
+		if selectableConfig, err = packagesConfig.ForProfile(profile); err != nil {
+			return fmt.Errorf("❌ %w", err)
+		}
+		logger.Infof("🧩 使用 profile %s", profile)
+	}
+	selectableConfig = selectableConfig.ForPlatform(platformInfo)
+	
 	// 创建安装器实例
 	inst := installer.NewInstaller(logger)
 	inst.InitializeManagers()
@@ -191,6 +343,11 @@ func runInteractiveInstall(cmd *cobra.Command, args []string, logger *logrus.Log
 	logger.Infof("✅ 检测到 %d 个可用包管理器: %v", 
 		len(availableManagers), getManagerNames(availableManagers))
 	
+	inst.SetPackagesConfig(packagesConfig)
+	inst.SetPlatformInfo(platformInfo)
+	configureToolEnvironment(inst, dotfilesConfig, logger)
+	configureAURReview(inst, packagesConfig, logger)
+	
 	// 创建交互式管理器
 	interactiveManager := interactive.NewInteractiveManager(
 		inst,              // installer
@@ -209,7 +366,7 @@ func runInteractiveInstall(cmd *cobra.Command, args []string, logger *logrus.Log
 	// 创建包选择场景
 	packageSelectionScenario := interactive.NewPackageSelectionScenario(
 		inst,
-		packagesConfig,
+		selectableConfig,
 		logger,
 		interactiveManager.GetTheme(),
 	)
@@ -226,16 +383,90 @@ func runInteractiveInstall(cmd *cobra.Command, args []string, logger *logrus.Log
 		"quiet":       quiet,
 		"parallel":    parallel,
 		"max_workers": maxWorkers,
+		
+		"install_timeout":      installTimeout,
+		"post_install_timeout": postInstallTimeout,
+		"batch_timeout":        batchTimeout,
+		"run_hooks":            runHooks,
+		"stop":                 interrupt.Stop(),
+	}
+	
+	// 交互式安装的包要到选择后才确定，只要有需要 sudo 的包管理器就提前验证
+	if !dryRun && inst.RequiresElevation(inst.ManifestPackageNames()) {
+		elevator := startElevation(ctx, logger)
+		defer elevator.Stop()
 	}
 	
 	// 执行交互式包选择场景
 	if err := interactiveManager.ExecuteScenario(ctx, "package_selection", scenarioOptions); err != nil {
 		return fmt.Errorf("交互式包选择失败: %w", err)
 	}
+	if interrupt.Interrupted() {
+		return fmt.Errorf("❌ 安装已中断")
+	}
 	
 	return nil
 }
 
+// exportXDGEnvironment 在加载配置前导出默认XDG变量，使 $XDG_* 路径和子进程环境一致
+func exportXDGEnvironment(logger *logrus.Logger) {
+	if err := xdg.NewManager(logger, runtime.GOOS).ExportEnvironment(); err != nil {
+		logger.Warnf("导出XDG环境变量失败: %v", err)
+	}
+}
+
+// detectPlatformInfo 检测当前平台，用于计算包清单中的 when 条件；检测失败时返回 nil（条件均视为满足）
+func detectPlatformInfo(logger *logrus.Logger) *platform.PlatformInfo {
+	info, err := platform.NewDetector().DetectPlatform()
+	if err != nil {
+		logger.Warnf("平台检测失败，忽略包清单中的 when 条件: %v", err)
+		return nil
+	}
+	return info
+}
+
+// configureToolEnvironment 将 development_environments 中的变量（NPM_CONFIG_CACHE、PIPX_HOME 等）传给语言包管理器
+func configureToolEnvironment(inst *installer.Installer, dotfilesConfig *config.DotfilesConfig, logger *logrus.Logger) {
+	if dotfilesConfig == nil || dotfilesConfig.ZshConfig == nil {
+		return
+	}
+	
+	shell := "zsh"
+	if runtime.GOOS == "windows" {
+		shell = "powershell"
+	}
+	env := installer.DevelopmentEnvironmentVars(dotfilesConfig.ZshConfig.DevelopmentEnvironments, shell)
+	logger.Debugf("语言包管理器使用 %d 个开发环境变量", len(env))
+	inst.SetToolEnvironment(env)
+}
+
+// configureAURReview 启用AUR PKGBUILD审查，非终端环境下仅信任列表中的包可自动安装
+func configureAURReview(inst *installer.Installer, packagesConfig *config.PackagesConfig, logger *logrus.Logger) {
+	var reviewConfig *config.AURReviewConfig
+	if packagesConfig != nil {
+		reviewConfig = packagesConfig.AURReview
+	}
+	
+	var approver installer.ReviewApprover
+	if isTerminal() {
+		approver = installer.TerminalApprover
+	}
+	
+	if err := inst.ConfigureAURReview(reviewConfig, approver); err != nil {
+		logger.Warnf("启用AUR审查失败: %v", err)
+	}
+}
+
+// isTerminal 检查标准输入输出是否为终端
+func isTerminal() bool {
+	for _, f := range []*os.File{os.Stdin, os.Stdout} {
+		if fi, err := f.Stat(); err != nil || (fi.Mode()&os.ModeCharDevice) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
 // getManagerNames 获取包管理器名称列表
 func getManagerNames(managers []installer.PackageManager) []string {
 	var names []string
@@ -0,0 +1,84 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/sirupsen/logrus"
+)
+
+// interruptHandler 处理安装过程中的 SIGINT/SIGTERM
+//
+// 安装子进程在自己的进程组中运行（见 installer 的 setProcessGroup），终端的 Ctrl-C 不会直接终止它们。
+// 第一次信号关闭 stop 通道：不再开始新的包，正在安装的包继续完成或超时；
+// 第二次信号取消上下文，安装器向正在运行的包管理器所在的整个进程组发送 SIGTERM（宽限后 SIGKILL）。
+type interruptHandler struct {
+	signals     chan os.Signal
+	stop        chan struct{}
+	cancel      context.CancelFunc
+	mu          sync.Mutex
+	interrupted bool
+	done        chan struct{}
+}
+
+// watchInterrupt 开始监听中断信号，返回可被第二次信号取消的上下文
+func watchInterrupt(logger *logrus.Logger) (context.Context, *interruptHandler) {
+	ctx, cancel := context.WithCancel(context.Background())
+	h := &interruptHandler{
+		signals: make(chan os.Signal, 2),
+		stop:    make(chan struct{}),
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}
+	signal.Notify(h.signals, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		count := 0
+		for {
+			select {
+			case sig := <-h.signals:
+				count++
+				if count == 1 {
+					h.mu.Lock()
+					h.interrupted = true
+					h.mu.Unlock()
+					close(h.stop)
+					logger.Debugf("收到信号 %v，停止调度新的包", sig)
+					fmt.Fprintf(os.Stderr, "\n⚠️  收到中断信号，等待正在安装的包完成（再次按 Ctrl-C 立即终止）\n")
+					continue
+				}
+				logger.Debugf("再次收到信号 %v，终止正在运行的包管理器进程组", sig)
+				fmt.Fprintf(os.Stderr, "\n🛑 立即终止安装\n")
+				cancel()
+				return
+			case <-h.done:
+				return
+			}
+		}
+	}()
+
+	return ctx, h
+}
+
+// Stop 第一次收到信号后关闭的通道
+func (h *interruptHandler) Stop() <-chan struct{} {
+	return h.stop
+}
+
+// Interrupted 是否收到过中断信号
+func (h *interruptHandler) Interrupted() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.interrupted
+}
+
+// Close 停止监听信号并释放上下文
+func (h *interruptHandler) Close() {
+	signal.Stop(h.signals)
+	close(h.done)
+	h.cancel()
+}
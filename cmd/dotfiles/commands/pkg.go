@@ -0,0 +1,122 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/bbq191/dotfiles-go/internal/config"
+	"github.com/bbq191/dotfiles-go/internal/installer"
+	"github.com/spf13/cobra"
+)
+
+var (
+	importCategory string
+	importManagers []string
+	importOutput   string
+	importDryRun   bool
+)
+
+// pkgCmd 包清单管理命令
+var pkgCmd = &cobra.Command{
+	Use:   "pkg",
+	Short: "管理软件包清单",
+	Long:  `管理平台包配置文件（configs/packages/<平台>.json）中的软件包清单。`,
+}
+
+// pkgImportCmd 导入本机已安装包命令
+var pkgImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "将本机已安装的软件包导入包清单",
+	Long: `读取本机显式安装的软件包并写入或合并到平台包配置文件。
+
+数据来源:
+  • pacman -Qqen  官方仓库中显式安装的包
+  • yay -Qm       从 AUR 安装的外部包
+  • winget export Windows 上已安装的包
+
+已在清单中的包只补充缺失的管理器映射和描述；新包的分类根据已有标签推断，
+也可以通过 --category 指定。描述来自包管理器的元数据。
+
+示例:
+  dotfiles pkg import                     # 导入并合并到当前平台的包配置
+  dotfiles pkg import --dry-run           # 只显示将要做的修改
+  dotfiles pkg import --category work     # 将新包放入 work 分类
+  dotfiles pkg import --manager yay       # 只导入 AUR 包`,
+	RunE: runPkgImport,
+}
+
+func init() {
+	rootCmd.AddCommand(pkgCmd)
+	pkgCmd.AddCommand(pkgImportCmd)
+
+	pkgImportCmd.Flags().StringVar(&importCategory, "category", "", "新包的分类（默认根据标签推断）")
+	pkgImportCmd.Flags().StringSliceVar(&importManagers, "manager", nil, "只从指定的包管理器导入")
+	pkgImportCmd.Flags().StringVarP(&importOutput, "output", "o", "", "写入的包配置文件（默认当前平台的包配置）")
+	pkgImportCmd.Flags().BoolVar(&importDryRun, "dry-run", false, "只显示修改，不写入文件")
+}
+
+func runPkgImport(cmd *cobra.Command, args []string) error {
+	logger := GetLogger()
+
+	outputPath := importOutput
+	if outputPath == "" {
+		outputPath = config.NewConfigLoader(getConfigDir(), logger).PlatformPackagesPath()
+	}
+
+	// 读取已有清单（不存在时从空清单开始）；只写入该文件，extends/include 中已有的包不重复导入
+	var manifest, inherited *config.PackagesConfig
+	if _, err := os.Stat(outputPath); err == nil {
+		manifest, err = config.LoadPackagesFile(outputPath)
+		if err != nil {
+			return fmt.Errorf("读取包配置失败: %w", err)
+		}
+		if manifest.Extends != "" || len(manifest.Include) > 0 {
+			if inherited, _, err = config.LoadComposedPackagesFile(outputPath); err != nil {
+				return fmt.Errorf("合并包配置失败: %w", err)
+			}
+		}
+	} else {
+		logger.Infof("包配置 %s 不存在，将创建新文件", outputPath)
+	}
+
+	inst := installer.NewInstaller(logger)
+	inst.InitializeManagers()
+
+	packages, err := inst.ListInstalledPackages(context.Background(), importManagers)
+	if err != nil {
+		return err
+	}
+	if len(packages) == 0 {
+		return fmt.Errorf("❌ 没有可导入的软件包（没有可用的包管理器支持列出已安装包）")
+	}
+
+	importer := installer.NewManifestImporter(manifest, logger)
+	report := importer.Merge(packages, installer.ImportOptions{Category: importCategory, Inherited: inherited})
+
+	fmt.Printf("\n📦 导入结果:\n")
+	for _, change := range report.Changes {
+		if change.Added {
+			fmt.Printf("  + %-24s → %s (%s)\n", change.Key, change.Category, change.Manager)
+		} else {
+			fmt.Printf("  ~ %-24s   %s (补充 %s 映射或描述)\n", change.Key, change.Category, change.Manager)
+		}
+	}
+	fmt.Printf("总计: 新增 %d, 更新 %d, 未变化 %d\n",
+		report.Added(), len(report.Changes)-report.Added(), report.Unchanged)
+
+	if importDryRun {
+		fmt.Println("🔍 [DRY RUN] 未写入文件")
+		return nil
+	}
+	if len(report.Changes) == 0 {
+		fmt.Println("✅ 包清单已是最新")
+		return nil
+	}
+
+	if err := config.SavePackagesFile(outputPath, importer.Manifest(), report.PackageRefs()); err != nil {
+		return err
+	}
+	fmt.Printf("✅ 已写入 %s\n", outputPath)
+	return nil
+}
@@ -0,0 +1,147 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/bbq191/dotfiles-go/internal/config"
+	"github.com/bbq191/dotfiles-go/internal/installer"
+	"github.com/spf13/cobra"
+)
+
+var (
+	runtimesProjectDir      string
+	runtimesSkipPostInstall bool
+	runtimesDryRun          bool
+)
+
+// runtimesCmd 运行时版本管理命令
+var runtimesCmd = &cobra.Command{
+	Use:   "runtimes",
+	Short: "管理语言运行时",
+	Long: `通过 zsh_integration.json 中配置的版本管理器（fnm、pyenv、sdkman、g）管理语言运行时。
+
+示例:
+  dotfiles runtimes sync             # 执行 post_install 并安装项目请求的版本
+  dotfiles runtimes sync --dry-run   # 只显示将要执行的命令
+  dotfiles runtimes status           # 显示每个运行时已安装的版本`,
+}
+
+// runtimesSyncCmd 同步运行时命令
+var runtimesSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "安装版本管理器的默认运行时和项目请求的版本",
+	Long: `为启用的版本管理器执行 post_install 命令（应用其 env_vars 和 path_additions），
+然后读取项目中的版本文件并安装请求的运行时版本。
+
+支持的版本文件（从项目目录向上查找，每种运行时使用最近的文件）:
+  • .tool-versions    asdf 格式，支持 nodejs/python/java/golang
+  • .nvmrc / .node-version
+  • .python-version
+  • .sdkmanrc         java=<版本>
+  • .go-version`,
+	RunE: runRuntimesSync,
+}
+
+// runtimesStatusCmd 运行时状态命令
+var runtimesStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "显示每个运行时已安装的版本",
+	RunE:  runRuntimesStatus,
+}
+
+func init() {
+	rootCmd.AddCommand(runtimesCmd)
+	runtimesCmd.AddCommand(runtimesSyncCmd)
+	runtimesCmd.AddCommand(runtimesStatusCmd)
+
+	runtimesSyncCmd.Flags().StringVarP(&runtimesProjectDir, "dir", "d", "", "查找版本文件的项目目录（默认当前目录）")
+	runtimesSyncCmd.Flags().BoolVar(&runtimesSkipPostInstall, "skip-post-install", false, "不执行版本管理器的 post_install 命令")
+	runtimesSyncCmd.Flags().BoolVar(&runtimesDryRun, "dry-run", false, "只显示将要执行的命令")
+}
+
+// newRuntimeSyncer 加载配置并创建版本管理器同步器
+func newRuntimeSyncer() (*installer.RuntimeSyncer, error) {
+	logger := GetLogger()
+
+	exportXDGEnvironment(logger)
+	dotfilesConfig, err := config.NewConfigLoader(getConfigDir(), logger).LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("加载配置失败: %w", err)
+	}
+	if dotfilesConfig.ZshConfig == nil || len(dotfilesConfig.ZshConfig.VersionManagers) == 0 {
+		return nil, fmt.Errorf("❌ 未配置任何版本管理器（zsh_integration.json 中的 version_managers）")
+	}
+
+	return installer.NewRuntimeSyncer(dotfilesConfig.ZshConfig.VersionManagers, logger), nil
+}
+
+func runRuntimesSync(cmd *cobra.Command, args []string) error {
+	syncer, err := newRuntimeSyncer()
+	if err != nil {
+		return err
+	}
+
+	projectDir := runtimesProjectDir
+	if projectDir == "" {
+		if projectDir, err = os.Getwd(); err != nil {
+			return fmt.Errorf("获取当前目录失败: %w", err)
+		}
+	}
+
+	reports, err := syncer.Sync(context.Background(), installer.RuntimeSyncOptions{
+		ProjectDir:      projectDir,
+		SkipPostInstall: runtimesSkipPostInstall,
+		DryRun:          runtimesDryRun,
+	})
+	if err != nil {
+		return err
+	}
+
+	failed := printRuntimeReports(reports)
+	if failed > 0 {
+		return fmt.Errorf("❌ %d 个运行时同步失败", failed)
+	}
+	return nil
+}
+
+func runRuntimesStatus(cmd *cobra.Command, args []string) error {
+	syncer, err := newRuntimeSyncer()
+	if err != nil {
+		return err
+	}
+
+	printRuntimeReports(syncer.Status(context.Background()))
+	return nil
+}
+
+// printRuntimeReports 输出运行时报告，返回有错误的运行时数量
+func printRuntimeReports(reports []*installer.RuntimeReport) int {
+	failed := 0
+
+	fmt.Printf("\n🧰 运行时状态:\n")
+	for _, report := range reports {
+		icon := "✅"
+		if len(report.Errors) > 0 {
+			icon = "❌"
+			failed++
+		}
+
+		installed := "（未检测到）"
+		if len(report.Installed) > 0 {
+			installed = strings.Join(report.Installed, ", ")
+		}
+		fmt.Printf("%s %-7s (%s): %s\n", icon, report.Runtime, report.Manager, installed)
+
+		for _, request := range report.Requested {
+			fmt.Printf("    ↳ %s 请求 %s\n", request.Source, request.Version)
+		}
+		for _, err := range report.Errors {
+			fmt.Printf("    ⚠️  %v\n", err)
+		}
+	}
+
+	return failed
+}
@@ -0,0 +1,101 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/bbq191/dotfiles-go/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	schemaOutput string
+	schemaLink   bool
+)
+
+// schemaCmd JSON Schema 生成命令
+var schemaCmd = &cobra.Command{
+	Use:   "schema [配置...]",
+	Short: "生成配置文件的 JSON Schema，供编辑器补全和校验",
+	Long: `根据配置结构生成 JSON Schema（draft-07），默认生成全部配置到配置目录的 schemas/ 下:
+  shared、zsh_integration、packages、advanced_functions
+
+--link 在配置目录的配置文件中写入指向生成文件的相对引用，VS Code 等编辑器据此补全和校验:
+  JSON   顶层 "$schema" 键（加载配置时忽略）
+  YAML   # yaml-language-server: $schema=... 注释
+  TOML   #:schema ... 注释（Taplo）
+
+示例:
+  dotfiles schema --link                # 生成到 configs/schemas/ 并写入引用
+  dotfiles schema packages -o -         # 输出包配置的 schema 到标准输出
+  dotfiles schema -o ~/.cache/schemas   # 生成到指定目录`,
+	ValidArgs: config.SchemaNames(),
+	Args:      cobra.OnlyValidArgs,
+	RunE:      runSchema,
+}
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+
+	schemaCmd.Flags().StringVarP(&schemaOutput, "output", "o", "", "输出目录（默认 <配置目录>/schemas，- 表示标准输出）")
+	schemaCmd.Flags().BoolVar(&schemaLink, "link", false, "在配置文件中写入 $schema 引用")
+}
+
+func runSchema(cmd *cobra.Command, args []string) error {
+	names := args
+	if len(names) == 0 {
+		names = config.SchemaNames()
+	}
+	configDir := getConfigDir()
+
+	if schemaOutput == "-" {
+		if len(names) != 1 || schemaLink {
+			return fmt.Errorf("❌ 输出到标准输出时只能指定一个配置，且不能与 --link 同时使用")
+		}
+		data, err := config.GenerateSchema(names[0])
+		if err != nil {
+			return fmt.Errorf("❌ %w", err)
+		}
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+
+	outputDir := schemaOutput
+	if outputDir == "" {
+		outputDir = filepath.Join(configDir, "schemas")
+	}
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("❌ 创建目录失败: %w", err)
+	}
+
+	for _, name := range names {
+		data, err := config.GenerateSchema(name)
+		if err != nil {
+			return fmt.Errorf("❌ %w", err)
+		}
+		path := filepath.Join(outputDir, config.SchemaFileName(name))
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return fmt.Errorf("❌ 写入 %s 失败: %w", path, err)
+		}
+		fmt.Printf("✅ 已生成 %s\n", path)
+	}
+
+	if !schemaLink {
+		return nil
+	}
+	files, err := config.ConfigFiles(configDir, names)
+	if err != nil {
+		return fmt.Errorf("❌ %w", err)
+	}
+	for _, file := range files {
+		changed, err := config.LinkSchema(file.Path, filepath.Join(outputDir, config.SchemaFileName(file.Kind)))
+		if err != nil {
+			return fmt.Errorf("❌ 写入 %s 的 schema 引用失败: %w", file.Path, err)
+		}
+		if changed {
+			fmt.Printf("🔗 %s → %s\n", relativeConfigPath(configDir, file.Path), config.SchemaFileName(file.Kind))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,236 @@
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/bbq191/dotfiles-go/internal/secrets"
+	"github.com/spf13/cobra"
+)
+
+var (
+	secretAddRecipients    []string
+	secretRemoveRecipients []string
+)
+
+// secretCmd 密钥管理命令
+var secretCmd = &cobra.Command{
+	Use:   "secret",
+	Short: "管理 age 加密的密钥",
+	Long: `管理配置目录中 age 加密的密钥文件 secrets.age。
+
+加密文件和接收者列表 secrets.recipients 可以提交到仓库；解密使用本机的 age 身份文件
+（默认 $XDG_CONFIG_HOME/dotfiles/age/keys.txt，可通过 DOTFILES_AGE_IDENTITY 指定），
+首次 set 时如果身份文件不存在会自动生成。
+
+密钥不分层: /etc/dotfiles、$XDG_CONFIG_HOME/dotfiles 和 hosts/<主机名>/ 中的配置
+引用的密钥也都存放在配置目录的 secrets.age 中。
+
+在配置和模板中引用密钥:
+  shared.json 的 environment 和代理配置:  "VALKEY_PASSWORD": "{{ secret \"valkey.password\" }}"
+  模板:                                   {{ secret "valkey.password" }}
+
+解密后的密钥值不会写入日志（日志中替换为 ******）。`,
+}
+
+// secretSetCmd 设置密钥命令
+var secretSetCmd = &cobra.Command{
+	Use:   "set <名称> [值]",
+	Short: "设置密钥（省略值时从终端输入或标准输入读取）",
+	Long: `设置密钥并重新加密。名称用点分隔分组，如 valkey.password。
+
+省略值时在终端中隐藏输入，或从标准输入读取（去掉末尾换行）；
+直接在命令行传入值会留在 shell 历史中，不推荐。
+
+示例:
+  dotfiles secret set valkey.password             # 隐藏输入
+  pass show work/proxy | dotfiles secret set proxy.work`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runSecretSet,
+}
+
+// secretGetCmd 读取密钥命令
+var secretGetCmd = &cobra.Command{
+	Use:   "get [名称]",
+	Short: "输出密钥值（省略名称时列出所有密钥名）",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runSecretGet,
+}
+
+// secretEditCmd 编辑密钥命令
+var secretEditCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "在编辑器中编辑解密后的 JSON",
+	Long: `将密钥解密到权限为 0600 的临时文件，使用 $VISUAL 或 $EDITOR 打开，
+保存后校验 JSON 并重新加密，临时文件随后被覆盖并删除。`,
+	Args: cobra.NoArgs,
+	RunE: runSecretEdit,
+}
+
+// secretRekeyCmd 重新加密命令
+var secretRekeyCmd = &cobra.Command{
+	Use:   "rekey",
+	Short: "更新接收者并重新加密",
+	Long: `按 secrets.recipients 中的公钥重新加密密钥文件，用于添加新机器或撤销旧机器。
+
+示例:
+  dotfiles secret rekey                                  # 手动修改 secrets.recipients 后重新加密
+  dotfiles secret rekey --add age1qyqszqgpqyqszqgp...     # 添加新机器的公钥
+  dotfiles secret rekey --remove age1zvkyg2lqzraa2lnj...  # 撤销旧机器的公钥
+
+撤销公钥后，旧机器仍能解密仓库历史中的旧版本，需要同时轮换密钥值。`,
+	Args: cobra.NoArgs,
+	RunE: runSecretRekey,
+}
+
+func init() {
+	rootCmd.AddCommand(secretCmd)
+	secretCmd.AddCommand(secretSetCmd)
+	secretCmd.AddCommand(secretGetCmd)
+	secretCmd.AddCommand(secretEditCmd)
+	secretCmd.AddCommand(secretRekeyCmd)
+
+	secretRekeyCmd.Flags().StringSliceVar(&secretAddRecipients, "add", nil, "添加的 age 公钥")
+	secretRekeyCmd.Flags().StringSliceVar(&secretRemoveRecipients, "remove", nil, "移除的 age 公钥")
+}
+
+func runSecretSet(cmd *cobra.Command, args []string) error {
+	store := secrets.NewStore(getConfigDir(), GetLogger())
+	name := args[0]
+
+	var value string
+	if len(args) == 2 {
+		value = args[1]
+	} else if isTerminal() {
+		prompt := &survey.Password{Message: fmt.Sprintf("%s 的值:", name)}
+		if err := survey.AskOne(prompt, &value); err != nil {
+			return err
+		}
+	} else {
+		data, err := io.ReadAll(bufio.NewReader(os.Stdin))
+		if err != nil {
+			return fmt.Errorf("读取标准输入失败: %w", err)
+		}
+		value = strings.TrimRight(string(data), "\r\n")
+	}
+	if value == "" {
+		return fmt.Errorf("❌ 密钥值不能为空")
+	}
+
+	if err := store.Set(name, value); err != nil {
+		return fmt.Errorf("❌ 设置密钥失败: %w", err)
+	}
+	fmt.Printf("🔐 已设置 %s（%s）\n", name, store.Path())
+	return nil
+}
+
+func runSecretGet(cmd *cobra.Command, args []string) error {
+	store := secrets.NewStore(getConfigDir(), GetLogger())
+
+	if len(args) == 0 {
+		names, err := store.Names()
+		if err != nil {
+			return fmt.Errorf("❌ %w", err)
+		}
+		for _, name := range names {
+			fmt.Println(name)
+		}
+		return nil
+	}
+
+	value, err := store.Get(args[0])
+	if err != nil {
+		return fmt.Errorf("❌ %w", err)
+	}
+	fmt.Println(value)
+	return nil
+}
+
+func runSecretEdit(cmd *cobra.Command, args []string) error {
+	store := secrets.NewStore(getConfigDir(), GetLogger())
+	plaintext, err := store.Plaintext()
+	if err != nil {
+		return fmt.Errorf("❌ %w", err)
+	}
+
+	tmpDir, err := os.MkdirTemp("", "dotfiles-secret-*")
+	if err != nil {
+		return err
+	}
+	tmpFile := filepath.Join(tmpDir, "secrets.json")
+	defer func() {
+		// 删除前覆盖明文
+		if info, err := os.Stat(tmpFile); err == nil {
+			_ = os.WriteFile(tmpFile, make([]byte, info.Size()), 0600)
+		}
+		os.RemoveAll(tmpDir)
+	}()
+	if err := os.WriteFile(tmpFile, plaintext, 0600); err != nil {
+		return err
+	}
+
+	for {
+		command := strings.Fields(secretEditor()) // 支持 "code --wait" 这样带参数的编辑器
+		editor := exec.Command(command[0], append(command[1:], tmpFile)...)
+		editor.Stdin, editor.Stdout, editor.Stderr = os.Stdin, os.Stdout, os.Stderr
+		if err := editor.Run(); err != nil {
+			return fmt.Errorf("❌ 编辑器退出异常: %w", err)
+		}
+
+		edited, err := os.ReadFile(tmpFile)
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(edited, plaintext) {
+			fmt.Println("ℹ️  内容未修改")
+			return nil
+		}
+
+		err = store.SetPlaintext(edited)
+		if err == nil {
+			fmt.Printf("🔐 已重新加密 %s\n", store.Path())
+			return nil
+		}
+		fmt.Printf("❌ %v\n", err)
+
+		retry := false
+		prompt := &survey.Confirm{Message: "是否重新编辑?", Default: true}
+		if !isTerminal() || survey.AskOne(prompt, &retry) != nil || !retry {
+			return fmt.Errorf("❌ 未保存修改")
+		}
+	}
+}
+
+func runSecretRekey(cmd *cobra.Command, args []string) error {
+	store := secrets.NewStore(getConfigDir(), GetLogger())
+	count, err := store.Rekey(secretAddRecipients, secretRemoveRecipients)
+	if err != nil {
+		return fmt.Errorf("❌ 重新加密失败: %w", err)
+	}
+	fmt.Printf("🔐 已使用 %d 个接收者重新加密 %s\n", count, store.Path())
+	if len(secretRemoveRecipients) > 0 {
+		fmt.Println("⚠️  已撤销的公钥仍能解密仓库历史中的旧版本，请轮换相关密钥值")
+	}
+	return nil
+}
+
+// secretEditor 返回编辑器命令: $VISUAL、$EDITOR，默认 vi（Windows 上为 notepad）
+func secretEditor() string {
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if editor := os.Getenv(env); editor != "" {
+			return editor
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return "notepad"
+	}
+	return "vi"
+}
@@ -0,0 +1,140 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/bbq191/dotfiles-go/internal/config"
+	"github.com/bbq191/dotfiles-go/internal/installer"
+	"github.com/spf13/cobra"
+)
+
+// statusCmd 显示包清单状态命令
+var statusCmd = &cobra.Command{
+	Use:   "status [packages...]",
+	Short: "显示软件包安装状态",
+	Long: `检查包清单中软件包的安装状态和版本约束。
+
+显示内容:
+  • 包所在分类和使用的包管理器
+  • 是否已安装及已安装版本（未安装时为可用版本）
+  • 版本约束及违规情况: 已安装版本或（未安装时）可用版本不满足约束
+
+未安装本身不算违规，只有存在违规时命令才返回非零退出码。
+
+示例:
+  dotfiles status               # 检查清单中的所有包（设置了 profile 时只检查其选中的包）
+  dotfiles status neovim git    # 只检查指定包
+  dotfiles status --profile work`,
+	RunE: runStatus,
+}
+
+var statusProfile string
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+
+	statusCmd.Flags().StringVar(&statusProfile, "profile", "", "只检查 profile 选中的包（默认 shared.json 中的 profile）")
+}
+
+func runStatus(cmd *cobra.Command, args []string) error {
+	logger := GetLogger()
+
+	// 加载配置
+	exportXDGEnvironment(logger)
+	dotfilesConfig, err := config.NewConfigLoader(getConfigDir(), logger).LoadConfig()
+	if err != nil {
+		return fmt.Errorf("加载配置失败: %w", err)
+	}
+	if dotfilesConfig.Packages == nil {
+		return fmt.Errorf("包配置未正确加载")
+	}
+
+	// 创建安装器实例
+	inst := installer.NewInstaller(logger)
+	inst.InitializeManagers()
+	inst.SetPackagesConfig(dotfilesConfig.Packages)
+	inst.SetPlatformInfo(detectPlatformInfo(logger))
+	configureToolEnvironment(inst, dotfilesConfig, logger)
+
+	packages := args
+	if len(packages) == 0 {
+		packages = inst.ManifestPackageNames()
+		if profile := activeProfile(statusProfile, dotfilesConfig); profile != "" {
+			if packages, err = inst.ProfilePackageNames(profile); err != nil {
+				return fmt.Errorf("❌ %w", err)
+			}
+			fmt.Printf("\n🧩 profile: %s\n", profile)
+		}
+	}
+
+	fmt.Printf("\n📋 软件包状态:\n")
+	fmt.Printf("┌─────────────────────┬──────────────┬──────────┬──────────────────┬──────────────┐\n")
+	fmt.Printf("│ 包名                │ 分类         │ 管理器   │ 版本             │ 约束         │\n")
+	fmt.Printf("├─────────────────────┼──────────────┼──────────┼──────────────────┼──────────────┤\n")
+
+	installed, missing, notApplicable := 0, 0, 0
+	var violations []*installer.PackageStatus
+	for _, name := range packages {
+		status := inst.CheckPackageStatus(name)
+
+		version := "❌ 未安装"
+		switch {
+		case status.NotApplicable:
+			notApplicable++
+			version = "⏭️ 不适用"
+		case status.Installed:
+			installed++
+			version = status.Version
+			if version == "" {
+				version = "✅ 已安装"
+			}
+		default:
+			missing++
+			if status.Available != "" {
+				version = "❌ 可用 " + status.Available
+			}
+		}
+
+		if !status.NotApplicable && (status.Error != nil || status.VersionViolation != "") {
+			violations = append(violations, status)
+		}
+
+		fmt.Printf("│ %-19s │ %-12s │ %-8s │ %-16s │ %-12s │\n",
+			truncateCell(status.Name, 19),
+			truncateCell(status.Category, 12),
+			truncateCell(status.Manager, 8),
+			truncateCell(version, 16),
+			truncateCell(status.Constraint, 12),
+		)
+	}
+
+	fmt.Printf("└─────────────────────┴──────────────┴──────────┴──────────────────┴──────────────┘\n")
+	fmt.Printf("总计: 已安装 %d, 未安装 %d", installed, missing)
+	if notApplicable > 0 {
+		fmt.Printf(", 当前平台不适用 %d", notApplicable)
+	}
+	fmt.Println()
+
+	if len(violations) > 0 {
+		fmt.Printf("\n⚠️  版本约束违规:\n")
+		for _, status := range violations {
+			if status.Error != nil {
+				fmt.Printf("  • %s: %v\n", status.Name, status.Error)
+			} else {
+				fmt.Printf("  • %s: %s\n", status.Name, status.VersionViolation)
+			}
+		}
+		return fmt.Errorf("❌ %d 个包违反版本约束", len(violations))
+	}
+
+	return nil
+}
+
+// truncateCell 截断表格单元格内容
+func truncateCell(s string, maxLen int) string {
+	runes := []rune(s)
+	if len(runes) <= maxLen {
+		return s
+	}
+	return string(runes[:maxLen-3]) + "..."
+}
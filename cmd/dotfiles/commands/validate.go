@@ -1,7 +1,9 @@
 package commands
 
 import (
+	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/bbq191/dotfiles-go/internal/config"
 	"github.com/sirupsen/logrus"
@@ -9,82 +11,161 @@ import (
 )
 
 var (
-	strictMode bool
+	strictMode     bool
+	validateFormat string
 )
 
 // validateCmd 验证配置命令
 var validateCmd = &cobra.Command{
 	Use:   "validate",
 	Short: "验证配置文件",
-	Long: `验证配置文件的格式和内容是否正确。
+	Long: `验证配置文件的格式和内容是否正确，收集所有问题后统一报告。
 
 验证项目:
-  • JSON 语法检查
-  • 必填字段验证
-  • 路径有效性检查
-  • 包名正确性验证
-  • 平台兼容性检查
+  • 语法和必填字段
+  • 路径、环境变量名和代理地址格式
+  • 包清单（包管理器映射、版本约束、超时、profile、when 条件）
+
+严格模式（--strict）额外检查:
+  • 所有配置层文件中的未知字段（包括 extends/include 引用的包配置）
+  • user.editor、EDITOR/VISUAL 以及 modern_tools、external_tools 引用的命令是否在 PATH 中
+  • 当前系统没有可用包管理器能安装的包
+  • 不是 active_profile 的代理方案
+  • 出现在多个分类中的包
+
+结果分为 error、warning、info 三个级别；存在 error 时验证失败，严格模式下 warning 也视为失败。
 
 示例:
-  dotfiles validate                 # 验证默认配置
-  dotfiles validate --strict       # 严格模式验证
-  dotfiles validate --config=my.json  # 验证指定配置`,
-	RunE: runValidate,
+  dotfiles validate                   # 验证默认配置
+  dotfiles validate --strict          # 严格模式验证
+  dotfiles validate --format json     # 输出 JSON 报告（供脚本和 CI 使用）`,
+	SilenceUsage: true, // 验证失败时不打印用法
+	RunE:         runValidate,
 }
 
 func init() {
 	rootCmd.AddCommand(validateCmd)
 
 	validateCmd.Flags().BoolVarP(&strictMode, "strict", "s", false, "严格模式验证")
+	validateCmd.Flags().StringVar(&validateFormat, "format", "text", "输出格式: text 或 json")
+}
+
+// validateResult validate --format json 的输出
+type validateResult struct {
+	Valid    bool                    `json:"valid"`
+	Strict   bool                    `json:"strict"`
+	Summary  map[config.Severity]int `json:"summary"`
+	Findings []config.Finding        `json:"findings"`
 }
 
 func runValidate(cmd *cobra.Command, args []string) error {
+	if validateFormat != "text" && validateFormat != "json" {
+		return fmt.Errorf("❌ 不支持的输出格式: %s（可用: text, json）", validateFormat)
+	}
+
 	logger := GetLogger()
-	
 	logger.Info("开始配置验证流程")
-	
 	if strictMode {
 		logger.Info("使用严格模式验证")
 	}
-	
+
 	// 加载配置
 	configDir := getConfigDir()
 	loader := loadConfig(configDir, logger)
-	
-	config, err := loader.LoadConfig()
+
+	report := config.NewReport()
+	cfg, err := loader.LoadConfig()
 	if err != nil {
-		return fmt.Errorf("配置加载失败: %w", err)
+		report.AddError(config.SeverityError, "load", err)
+	} else {
+		// 验证配置
+		report = createValidator(logger).Diagnose(cfg, strictMode)
 	}
-	
-	// 验证配置
-	validator := createValidator(logger)
-	if err := validator.ValidateConfig(config); err != nil {
-		fmt.Printf("❌ 配置验证失败:\n%v\n", err)
-		return err
+	if strictMode {
+		report.AddError(config.SeverityError, "unknown-field", loader.StrictDecode())
+		report.Sort()
+	}
+
+	valid := report.Count(config.SeverityError) == 0 && (!strictMode || report.Count(config.SeverityWarning) == 0)
+
+	if validateFormat == "json" {
+		result := validateResult{
+			Valid:  valid,
+			Strict: strictMode,
+			Summary: map[config.Severity]int{
+				config.SeverityError:   report.Count(config.SeverityError),
+				config.SeverityWarning: report.Count(config.SeverityWarning),
+				config.SeverityInfo:    report.Count(config.SeverityInfo),
+			},
+			Findings: report.Findings,
+		}
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		if !valid {
+			return fmt.Errorf("配置验证失败: %s", report)
+		}
+		return nil
 	}
-	
+
+	printFindings(report, configDir)
+	if !valid {
+		return fmt.Errorf("❌ 配置验证失败: %s", report)
+	}
+
 	// 显示验证结果
-	fmt.Println("✅ 配置验证通过")
-	fmt.Printf("用户: %s (%s)\n", config.User.Name, config.User.Email)
-	fmt.Printf("版本: %s\n", config.Version)
-	
-	if config.ZshConfig != nil {
+	if len(report.Findings) > 0 {
+		fmt.Printf("✅ 配置验证通过（%s）\n", report)
+	} else {
+		fmt.Println("✅ 配置验证通过")
+	}
+	fmt.Printf("用户: %s (%s)\n", cfg.User.Name, cfg.User.Email)
+	fmt.Printf("版本: %s\n", cfg.Version)
+	var layers []string
+	for _, layer := range loader.ActiveLayers() {
+		if layer.Dir == "" {
+			layers = append(layers, layer.Name)
+		} else {
+			layers = append(layers, fmt.Sprintf("%s(%s)", layer.Name, layer.Dir))
+		}
+	}
+	fmt.Printf("配置层: %s\n", strings.Join(layers, " → "))
+
+	if cfg.ZshConfig != nil {
 		fmt.Printf("Zsh 集成: 已启用\n")
-		if config.ZshConfig.XDGDirectories.Enabled {
+		if cfg.ZshConfig.XDGDirectories.Enabled {
 			fmt.Printf("XDG 目录: 已启用\n")
 		}
 	}
-	
-	if config.Packages != nil {
-		categoryCount := len(config.Packages.Categories)
-		managerCount := len(config.Packages.Managers)
+
+	if cfg.Packages != nil {
+		categoryCount := len(cfg.Packages.Categories)
+		managerCount := len(cfg.Packages.Managers)
 		fmt.Printf("包配置: %d 个分类, %d 个包管理器\n", categoryCount, managerCount)
 	}
-	
+
 	logger.Info("配置验证完成")
 	return nil
 }
 
+// printFindings 按级别输出诊断结果，文件路径显示为相对配置目录的路径
+func printFindings(report *config.Report, configDir string) {
+	icons := map[config.Severity]string{
+		config.SeverityError:   "❌",
+		config.SeverityWarning: "⚠️ ",
+		config.SeverityInfo:    "ℹ️ ",
+	}
+	for _, finding := range report.Findings {
+		finding.File = relativeConfigPath(configDir, finding.File)
+		fmt.Printf("%s %s [%s]\n", icons[finding.Severity], finding, finding.Check)
+	}
+	if len(report.Findings) > 0 {
+		fmt.Println()
+	}
+}
+
 // getConfigDir 获取配置目录
 func getConfigDir() string {
 	return config.GetConfigDir()
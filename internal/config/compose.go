@@ -0,0 +1,225 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// DeleteMarker 删除标记：值为 "$delete" 的键，或包含 "$delete": true 的对象，会从合并结果中删除
+const DeleteMarker = "$delete"
+
+// Provenance 合并结果中每个路径（如 categories.essential.packages.git）的来源文件
+type Provenance map[string]string
+
+// ComposedConfig 按 extends/include 合并后的配置文件
+type ComposedConfig struct {
+	Data     map[string]interface{} // 合并后的原始数据（已移除 extends/include、$schema 和删除标记）
+	Sources  Provenance
+	Files    []string          // 参与合并的文件（按合并顺序）
+	Versions map[string]string // 文件 -> 文件声明的配置格式版本（没有 version 键的文件不记录）
+}
+
+// ComposeJSONFile 读取配置文件（JSON、YAML 或 TOML）并按其 extends/include 指令深度合并
+//
+// 合并顺序为 extends 指定的文件、include 中的文件（按列出顺序）、文件自身，后者覆盖前者。
+// 对象逐键合并，数组和标量整体替换；路径相对于当前文件所在目录。
+func ComposeJSONFile(path string) (*ComposedConfig, error) {
+	composed := newComposedConfig()
+	if err := composed.compose(path, nil); err != nil {
+		return nil, err
+	}
+	return composed, nil
+}
+
+func newComposedConfig() *ComposedConfig {
+	return &ComposedConfig{
+		Data:     make(map[string]interface{}),
+		Sources:  make(Provenance),
+		Versions: make(map[string]string),
+	}
+}
+
+// Decode 将合并结果解码到目标结构
+func (cc *ComposedConfig) Decode(target interface{}) error {
+	data, err := json.Marshal(cc.Data)
+	if err != nil {
+		return fmt.Errorf("序列化合并结果失败: %w", err)
+	}
+	if err := json.Unmarshal(data, target); err != nil {
+		return fmt.Errorf("解析合并结果失败（%s）: %w", strings.Join(cc.Files, " + "), err)
+	}
+	return nil
+}
+
+// Source 返回路径的来源文件，路径未单独记录时返回最近的上级路径的来源
+func (cc *ComposedConfig) Source(path string) string {
+	for {
+		if source, ok := cc.Sources[path]; ok {
+			return source
+		}
+		idx := strings.LastIndex(path, ".")
+		if idx < 0 {
+			return ""
+		}
+		path = path[:idx]
+	}
+}
+
+// compose 递归合并文件，chain 记录正在合并的文件用于检测循环引用
+func (cc *ComposedConfig) compose(path string, chain []string) error {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	if slices.Contains(chain, absPath) {
+		return fmt.Errorf("配置文件循环引用: %s", strings.Join(append(chain, absPath), " → "))
+	}
+	chain = append(chain, absPath)
+
+	data, _, err := readConfigJSON(path)
+	if err != nil {
+		return err
+	}
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("解析配置文件 %s 失败: %w", path, err)
+	}
+
+	parents, err := composeDirectives(raw, path)
+	if err != nil {
+		return err
+	}
+	for _, parent := range parents {
+		if err := cc.compose(filepath.Join(filepath.Dir(path), parent), chain); err != nil {
+			return fmt.Errorf("%s 引用的 %s: %w", path, parent, err)
+		}
+	}
+
+	if version, ok := raw[VersionKey].(string); ok {
+		cc.Versions[path] = version
+	}
+	mergeJSON(cc.Data, raw, path, cc.Sources, "")
+	cc.Files = append(cc.Files, path)
+	return nil
+}
+
+// composeDirectives 取出并移除文件中的 extends 和 include 指令，返回按合并顺序排列的文件
+//
+// 只对当前文件有意义的 $schema 引用也在这里移除，不参与合并。
+func composeDirectives(raw map[string]interface{}, path string) ([]string, error) {
+	var files []string
+	delete(raw, SchemaKey)
+
+	if extends, ok := raw["extends"]; ok {
+		name, isString := extends.(string)
+		if !isString || name == "" {
+			return nil, fmt.Errorf("%s: extends 必须是文件路径字符串", path)
+		}
+		files = append(files, name)
+		delete(raw, "extends")
+	}
+
+	if include, ok := raw["include"]; ok {
+		switch value := include.(type) {
+		case string:
+			files = append(files, value)
+		case []interface{}:
+			for _, item := range value {
+				name, isString := item.(string)
+				if !isString || name == "" {
+					return nil, fmt.Errorf("%s: include 只能包含文件路径字符串", path)
+				}
+				files = append(files, name)
+			}
+		default:
+			return nil, fmt.Errorf("%s: include 必须是文件路径或路径数组", path)
+		}
+		delete(raw, "include")
+	}
+
+	return files, nil
+}
+
+// mergeJSON 将 src 深度合并到 dst，并记录每个写入路径的来源
+func mergeJSON(dst, src map[string]interface{}, source string, sources Provenance, prefix string) {
+	keys := make([]string, 0, len(src))
+	for key := range src {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := src[key]
+		path := joinPath(prefix, key)
+
+		if isDeleteMarker(value) {
+			delete(dst, key)
+			sources.deleteTree(path)
+			continue
+		}
+
+		object, isObject := value.(map[string]interface{})
+		if !isObject {
+			dst[key] = value
+			sources.deleteTree(path)
+			sources[path] = source
+			continue
+		}
+
+		existing, merge := dst[key].(map[string]interface{})
+		if !merge {
+			existing = make(map[string]interface{})
+			dst[key] = existing
+			sources.deleteTree(path)
+			sources[path] = source
+		}
+		mergeJSON(existing, object, source, sources, path)
+	}
+}
+
+// isDeleteMarker 判断值是否为删除标记
+func isDeleteMarker(value interface{}) bool {
+	switch v := value.(type) {
+	case string:
+		return v == DeleteMarker
+	case map[string]interface{}:
+		marker, ok := v[DeleteMarker].(bool)
+		return ok && marker
+	}
+	return false
+}
+
+// deleteTree 删除路径及其所有子路径的来源记录
+func (p Provenance) deleteTree(path string) {
+	delete(p, path)
+	for key := range p {
+		if strings.HasPrefix(key, path+".") {
+			delete(p, key)
+		}
+	}
+}
+
+func joinPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// LoadComposedPackagesFile 加载包配置文件并合并其 extends/include 引用的文件
+func LoadComposedPackagesFile(path string) (*PackagesConfig, *ComposedConfig, error) {
+	composed, err := ComposeJSONFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var packages PackagesConfig
+	if err := composed.Decode(&packages); err != nil {
+		return nil, nil, err
+	}
+	return &packages, composed, nil
+}
@@ -0,0 +1,190 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/bbq191/dotfiles-go/internal/platform"
+)
+
+// conditionKeys when 块支持的键
+var conditionKeys = []string{"os", "wsl", "distro", "arch", "powershell"}
+
+// ConditionValues 条件取值，JSON 中可以写成字符串或字符串数组；以 ! 开头的值表示排除
+type ConditionValues []string
+
+// UnmarshalJSON 同时接受 "arch" 和 ["arch", "manjaro"] 两种写法
+func (v *ConditionValues) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*v = ConditionValues{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("条件取值必须是字符串或字符串数组: %s", string(data))
+	}
+	*v = list
+	return nil
+}
+
+// match 检查实际值是否满足条件：无肯定值或命中任一肯定值，且未命中任何排除值（不区分大小写）
+func (v ConditionValues) match(actual string) bool {
+	actual = strings.ToLower(actual)
+	positive, matched := false, false
+	for _, value := range v {
+		value = strings.ToLower(value)
+		if excluded, ok := strings.CutPrefix(value, "!"); ok {
+			if excluded == actual {
+				return false
+			}
+			continue
+		}
+		positive = true
+		if value == actual {
+			matched = true
+		}
+	}
+	return !positive || matched
+}
+
+// Condition when 条件块，所有已设置的键同时满足时条件成立
+type Condition struct {
+	OS         ConditionValues `json:"os,omitempty"`         // 操作系统（runtime.GOOS）：linux、windows、darwin
+	WSL        *bool           `json:"wsl,omitempty"`        // true 仅在 WSL2 中，false 仅在非 WSL2 环境（裸机或虚拟机）
+	Distro     ConditionValues `json:"distro,omitempty"`     // Linux 发行版 ID：arch、ubuntu、fedora 等
+	Arch       ConditionValues `json:"arch,omitempty"`       // 系统架构（runtime.GOARCH）：amd64、arm64
+	PowerShell ConditionValues `json:"powershell,omitempty"` // PowerShell 版本类型：core、desktop
+
+	unknown []string // 解析时遇到的未知键，由 Validate 报告
+}
+
+// UnmarshalJSON 解析条件并记录未知键
+func (c *Condition) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("when 必须是对象: %w", err)
+	}
+
+	type plain Condition
+	var parsed plain
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return err
+	}
+	*c = Condition(parsed)
+
+	for key := range raw {
+		if !slices.Contains(conditionKeys, key) {
+			c.unknown = append(c.unknown, key)
+		}
+	}
+	sort.Strings(c.unknown)
+	return nil
+}
+
+// Validate 检查条件中的键和取值
+func (c *Condition) Validate() error {
+	if c == nil {
+		return nil
+	}
+	if len(c.unknown) > 0 {
+		return fmt.Errorf("when 包含未知的条件键 %s（支持: %s）",
+			strings.Join(c.unknown, ", "), strings.Join(conditionKeys, ", "))
+	}
+	for _, edition := range c.PowerShell {
+		switch strings.ToLower(strings.TrimPrefix(edition, "!")) {
+		case "core", "desktop":
+		default:
+			return fmt.Errorf("when.powershell 的取值 %s 无效（支持: core, desktop）", edition)
+		}
+	}
+	return nil
+}
+
+// Matches 检查当前平台是否满足条件；没有条件或平台信息未知时视为满足
+func (c *Condition) Matches(info *platform.PlatformInfo) bool {
+	if c == nil || info == nil {
+		return true
+	}
+	if len(c.OS) > 0 && !c.OS.match(info.OS) {
+		return false
+	}
+	if c.WSL != nil && *c.WSL != info.IsWSL2Environment() {
+		return false
+	}
+	if len(c.Distro) > 0 {
+		distro := ""
+		if info.Linux != nil {
+			distro = info.Linux.Distribution
+		}
+		if !c.Distro.match(distro) {
+			return false
+		}
+	}
+	if len(c.Arch) > 0 && !c.Arch.match(info.Architecture) {
+		return false
+	}
+	if len(c.PowerShell) > 0 {
+		edition := ""
+		if info.PowerShell != nil {
+			edition = info.PowerShell.Edition
+		}
+		if !c.PowerShell.match(edition) {
+			return false
+		}
+	}
+	return true
+}
+
+// PackageApplies 检查包（及其所在分类）的 when 条件在当前平台是否成立；清单中不存在的包视为成立
+func (pc *PackagesConfig) PackageApplies(name string, info *platform.PlatformInfo) bool {
+	found := false
+	for _, category := range pc.Categories {
+		pkg, ok := category.Packages[name]
+		if !ok {
+			continue
+		}
+		if category.When.Matches(info) && pkg.When.Matches(info) {
+			return true
+		}
+		found = true
+	}
+	return !found
+}
+
+// ForPlatform 返回只包含当前平台 when 条件成立的分类和包的清单副本（包全部被过滤的分类被移除）
+func (pc *PackagesConfig) ForPlatform(info *platform.PlatformInfo) *PackagesConfig {
+	filtered := *pc
+	filtered.Categories = make(map[string]Category)
+	for categoryName, category := range pc.Categories {
+		if !category.When.Matches(info) {
+			continue
+		}
+		packages := make(map[string]PackageInfo)
+		for pkgName, pkg := range category.Packages {
+			if pkg.When.Matches(info) {
+				packages[pkgName] = pkg
+			}
+		}
+		if len(packages) == 0 && len(category.Packages) > 0 {
+			continue
+		}
+		category.Packages = packages
+		filtered.Categories[categoryName] = category
+	}
+	return &filtered
+}
+
+// ForPlatform 返回只包含当前平台 when 条件成立的函数的副本
+func (fc *FunctionsConfig) ForPlatform(info *platform.PlatformInfo) *FunctionsConfig {
+	filtered := &FunctionsConfig{Functions: make(map[string]FunctionInfo)}
+	for name, fn := range fc.Functions {
+		if fn.When.Matches(info) {
+			filtered.Functions[name] = fn
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,293 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+)
+
+// DecodeError 配置文件解码或验证错误，定位到文件、JSON 路径和（可能时）行列
+type DecodeError struct {
+	File    string
+	Path    string // JSON 路径，如 features.completion_cache、paths.projects.linux
+	Line    int    // 从 1 开始，0 表示未知
+	Column  int    // 从 1 开始，0 表示未知
+	Message string
+}
+
+// Error 格式: 文件:行:列: 路径: 说明（未知的行列省略）
+func (e *DecodeError) Error() string {
+	var b strings.Builder
+	b.WriteString(e.File)
+	if e.Line > 0 {
+		fmt.Fprintf(&b, ":%d", e.Line)
+		if e.Column > 0 {
+			fmt.Fprintf(&b, ":%d", e.Column)
+		}
+	}
+	if e.Path != "" {
+		b.WriteString(": " + e.Path)
+	}
+	b.WriteString(": " + e.Message)
+	return b.String()
+}
+
+// SourceMap 记录配置文件中每个键的位置，用于把验证错误定位到行列
+type SourceMap struct {
+	File      string
+	data      []byte
+	positions map[string]int      // JSON 路径 -> 键的字节偏移
+	locations map[string]location // 非 JSON 文件: JSON 路径 -> 键在原文件中的行列
+}
+
+// location 键在原文件中的行列
+type location struct {
+	line, column int
+}
+
+// Locate 返回路径所在的行列；路径不存在时（如缺少的必需字段）使用最近的父路径，根路径返回 0, 0
+func (sm *SourceMap) Locate(path string) (line, column int) {
+	if sm == nil {
+		return 0, 0
+	}
+	for path != "" {
+		if sm.locations != nil {
+			if loc, ok := sm.locations[path]; ok {
+				return loc.line, loc.column
+			}
+		} else if offset, ok := sm.positions[path]; ok {
+			return lineColumn(sm.data, offset)
+		}
+		path = parentPath(path)
+	}
+	return 0, 0
+}
+
+// Error 构造定位到该文件和路径的错误
+func (sm *SourceMap) Error(path, message string) *DecodeError {
+	line, column := sm.Locate(path)
+	return &DecodeError{File: sm.File, Path: path, Line: line, Column: column, Message: message}
+}
+
+// DecodeStrict 按扩展名读取 JSON、YAML 或 TOML 配置文件并严格解码到 target
+//
+// YAML 的错误定位到原文件的行列；TOML 解码后不保留位置，只报告路径。
+func DecodeStrict(file string, target any) (*SourceMap, error) {
+	data, locations, err := readConfigJSON(file)
+	if err != nil {
+		return nil, err
+	}
+	if locations == nil {
+		return DecodeStrictJSON(file, data, target)
+	}
+	return decodeStrict(file, data, locations, target)
+}
+
+// DecodeStrictJSON 将 JSON 严格解码到 target：未知字段和类型错误都以 DecodeError 报告（多个错误用 errors.Join 合并）
+//
+// 实现了 json.Unmarshaler 的类型（如 PathValue）和 interface{} 字段内部不检查未知字段。
+func DecodeStrictJSON(file string, data []byte, target any) (*SourceMap, error) {
+	return decodeStrict(file, data, nil, target)
+}
+
+// decodeStrict 严格解码转换后的 JSON；locations 不为 nil 时用它代替 JSON 中的位置
+func decodeStrict(file string, data []byte, locations map[string]location, target any) (*SourceMap, error) {
+	sm := &SourceMap{File: file, data: data, positions: make(map[string]int), locations: locations}
+
+	walker := &jsonWalker{dec: json.NewDecoder(bytes.NewReader(data)), source: sm}
+	if err := walker.walkValue(reflect.TypeOf(target), ""); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			// Offset 包括出错的字符，减一后定位到该字符
+			line, column := lineColumn(data, max(int(syntaxErr.Offset)-1, 0))
+			return sm, &DecodeError{File: file, Line: line, Column: column, Message: "JSON 语法错误: " + syntaxErr.Error()}
+		}
+		return sm, &DecodeError{File: file, Message: "JSON 解析失败: " + err.Error()}
+	}
+	if len(walker.errs) > 0 {
+		return sm, errors.Join(walker.errs...)
+	}
+
+	if err := json.Unmarshal(withoutReservedKeys(data, target), target); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			decodeErr := sm.Error(typeErr.Field, fmt.Sprintf("类型错误: 期望 %s，实际为 %s", typeErr.Type, typeErr.Value))
+			if _, ok := sm.positions[typeErr.Field]; !ok && locations == nil {
+				decodeErr.Line, decodeErr.Column = lineColumn(data, int(typeErr.Offset))
+			}
+			return sm, decodeErr
+		}
+		return sm, &DecodeError{File: file, Message: err.Error()}
+	}
+	return sm, nil
+}
+
+// jsonWalker 按目标类型遍历 JSON 记录键位置并检查未知字段
+type jsonWalker struct {
+	dec    *json.Decoder
+	source *SourceMap
+	errs   []error
+}
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// walkValue 读取一个 JSON 值；t 为 nil 时只记录位置，不检查字段
+func (w *jsonWalker) walkValue(t reflect.Type, path string) error {
+	tok, err := w.dec.Token()
+	if err != nil {
+		return err
+	}
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		return nil
+	}
+
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t != nil && (t.Kind() == reflect.Interface || reflect.PointerTo(t).Implements(jsonUnmarshalerType)) {
+		t = nil
+	}
+
+	switch delim {
+	case '{':
+		for w.dec.More() {
+			keyTok, err := w.dec.Token()
+			if err != nil {
+				return err
+			}
+			key, _ := keyTok.(string)
+			childPath := joinPath(path, key)
+			w.source.positions[childPath] = keyStart(w.source.data, int(w.dec.InputOffset()))
+
+			var child reflect.Type
+			if t != nil {
+				switch t.Kind() {
+				case reflect.Struct:
+					var known bool
+					if child, known = structFieldType(t, key); !known {
+						w.errs = append(w.errs, w.source.Error(childPath, "未知字段"))
+					}
+				case reflect.Map:
+					if path != "" || !isReservedKey(key) {
+						child = t.Elem()
+					}
+				}
+			}
+			if err := w.walkValue(child, childPath); err != nil {
+				return err
+			}
+		}
+	case '[':
+		var elem reflect.Type
+		if t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+			elem = t.Elem()
+		}
+		for index := 0; w.dec.More(); index++ {
+			if err := w.walkValue(elem, fmt.Sprintf("%s[%d]", path, index)); err != nil {
+				return err
+			}
+		}
+	}
+
+	// 读取结束符 } 或 ]
+	_, err = w.dec.Token()
+	return err
+}
+
+// isReservedKey 判断顶层键是否为 $schema 引用或 version 格式版本
+func isReservedKey(key string) bool {
+	return key == SchemaKey || key == VersionKey
+}
+
+// withoutReservedKeys 解码到 map（如 advanced_functions）时删除顶层的 $schema 引用和字符串类型的 version，
+// 否则它们会被当作普通的项
+func withoutReservedKeys(data []byte, target any) []byte {
+	t := reflect.TypeOf(target)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Map {
+		return data
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return data
+	}
+	found := false
+	for key, value := range raw {
+		if key == SchemaKey || (key == VersionKey && bytes.HasPrefix(value, []byte(`"`))) {
+			delete(raw, key)
+			found = true
+		}
+	}
+	if !found {
+		return data
+	}
+	stripped, err := json.Marshal(raw)
+	if err != nil {
+		return data
+	}
+	return stripped
+}
+
+// structFieldType 按 encoding/json 的规则（json 标签、不区分大小写、嵌入结构体）查找键对应的字段类型
+func structFieldType(t reflect.Type, key string) (reflect.Type, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" && field.Anonymous && field.Type.Kind() == reflect.Struct {
+			if child, ok := structFieldType(field.Type, key); ok {
+				return child, true
+			}
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if strings.EqualFold(name, key) {
+			return field.Type, true
+		}
+	}
+	return nil, false
+}
+
+// keyStart 根据键结束位置（右引号之后）找到键的左引号位置
+func keyStart(data []byte, end int) int {
+	for i := end - 2; i >= 0; i-- {
+		if data[i] == '"' && (i == 0 || data[i-1] != '\\') {
+			return i
+		}
+	}
+	return end
+}
+
+// lineColumn 将字节偏移转换为行列（列按字符计数）
+func lineColumn(data []byte, offset int) (line, column int) {
+	if offset > len(data) {
+		offset = len(data)
+	}
+	before := data[:offset]
+	line = bytes.Count(before, []byte("\n")) + 1
+	lineStart := bytes.LastIndexByte(before, '\n') + 1
+	return line, utf8.RuneCount(before[lineStart:]) + 1
+}
+
+// parentPath 返回 JSON 路径的父路径（a.b[0] -> a.b，a.b -> a）
+func parentPath(path string) string {
+	if idx := strings.LastIndexAny(path, ".["); idx >= 0 {
+		return path[:idx]
+	}
+	return ""
+}
@@ -0,0 +1,112 @@
+package config
+
+import (
+	"errors"
+	"testing"
+)
+
+// decodeTestConfig 严格解码测试使用的配置结构
+type decodeTestConfig struct {
+	Name  string                 `json:"name"`
+	Count int                    `json:"count"`
+	Paths map[string]PathValue   `json:"paths"`
+	Extra map[string]interface{} `json:"extra"`
+	Items []decodeTestItem       `json:"items"`
+}
+
+type decodeTestItem struct {
+	Tool string `json:"tool"`
+}
+
+// TestDecodeStrictJSON 测试未知字段、类型错误和语法错误定位到路径和行列，自定义解码和任意值内部不检查
+func TestDecodeStrictJSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		errs   []DecodeError // 期望的错误（只比较路径、行列和说明），为空表示解码成功
+		prefix bool          // 只比较说明的前缀
+	}{
+		{
+			name:  "正常",
+			input: "{\n  \"name\": \"test\",\n  \"count\": 1\n}",
+		},
+		{
+			name:  "拼错的键",
+			input: "{\n  \"name\": \"test\",\n  \"cuont\": 1\n}",
+			errs:  []DecodeError{{Path: "cuont", Line: 3, Column: 3, Message: "未知字段"}},
+		},
+		{
+			name:  "嵌套的拼错的键",
+			input: "{\n  \"items\": [\n    {\"tool\": \"eza\"},\n    {\"tol\": \"bat\"}\n  ]\n}",
+			errs:  []DecodeError{{Path: "items[1].tol", Line: 4, Column: 6, Message: "未知字段"}},
+		},
+		{
+			name:  "多个未知字段",
+			input: "{\"nmae\": \"a\", \"items\": [{\"tol\": \"b\"}]}",
+			errs: []DecodeError{
+				{Path: "nmae", Line: 1, Column: 2, Message: "未知字段"},
+				{Path: "items[0].tol", Line: 1, Column: 26, Message: "未知字段"},
+			},
+		},
+		{
+			name:   "类型错误",
+			input:  "{\n  \"name\": \"test\",\n  \"count\": \"1\"\n}",
+			errs:   []DecodeError{{Path: "count", Line: 3, Column: 3, Message: "类型错误"}},
+			prefix: true,
+		},
+		{
+			name:   "语法错误",
+			input:  "{\n  \"name\": \"test\",\n  \"count\": 1,,\n}",
+			errs:   []DecodeError{{Line: 3, Column: 14, Message: "JSON 语法错误"}},
+			prefix: true,
+		},
+		{
+			name:  "路径值和任意值内部不检查",
+			input: `{"paths": {"projects": {"linux": "~/p", "plan9": "/n/p"}}, "extra": {"anything": {"goes": true}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var target decodeTestConfig
+			_, err := DecodeStrictJSON("test.json", []byte(tt.input), &target)
+			if len(tt.errs) == 0 {
+				if err != nil {
+					t.Fatalf("不应返回错误: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("应返回错误")
+			}
+
+			var got []*DecodeError
+			if joined, ok := err.(interface{ Unwrap() []error }); ok {
+				for _, e := range joined.Unwrap() {
+					var decodeErr *DecodeError
+					if errors.As(e, &decodeErr) {
+						got = append(got, decodeErr)
+					}
+				}
+			} else {
+				var decodeErr *DecodeError
+				if errors.As(err, &decodeErr) {
+					got = append(got, decodeErr)
+				}
+			}
+			if len(got) != len(tt.errs) {
+				t.Fatalf("期望 %d 个错误，实际: %v", len(tt.errs), err)
+			}
+			for i, want := range tt.errs {
+				e := got[i]
+				message := e.Message
+				if tt.prefix && len(message) > len(want.Message) {
+					message = message[:len(want.Message)]
+				}
+				if e.File != "test.json" || e.Path != want.Path || e.Line != want.Line || e.Column != want.Column || message != want.Message {
+					t.Errorf("错误 %d 不符: 期望 %s:%d:%d %s %s，实际 %v", i, "test.json", want.Line, want.Column, want.Path, want.Message, e)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Severity 诊断结果的严重级别
+type Severity string
+
+const (
+	SeverityError   Severity = "error"   // 配置错误，加载或生成会失败
+	SeverityWarning Severity = "warning" // 可能的问题，严格模式下视为失败
+	SeverityInfo    Severity = "info"    // 提示信息
+)
+
+// severityRank 排序用：错误在前
+var severityRank = map[Severity]int{SeverityError: 0, SeverityWarning: 1, SeverityInfo: 2}
+
+// Finding 一条诊断结果
+type Finding struct {
+	Severity Severity `json:"severity"`
+	Check    string   `json:"check"`          // 检查项，如 unknown-field、missing-tool
+	File     string   `json:"file,omitempty"` // 所在文件（能定位时）
+	Line     int      `json:"line,omitempty"`
+	Column   int      `json:"column,omitempty"`
+	Path     string   `json:"path,omitempty"` // JSON 路径，如 modern_tools.replacements.ls.tool
+	Message  string   `json:"message"`
+}
+
+// String 格式: 文件:行:列: 路径: 说明
+func (f Finding) String() string {
+	if f.File != "" {
+		return (&DecodeError{File: f.File, Path: f.Path, Line: f.Line, Column: f.Column, Message: f.Message}).Error()
+	}
+	if f.Path != "" {
+		return f.Path + ": " + f.Message
+	}
+	return f.Message
+}
+
+// Report 诊断报告，收集所有检查结果而不是在第一个错误处停止
+type Report struct {
+	Findings []Finding `json:"findings"`
+}
+
+// NewReport 创建空的诊断报告
+func NewReport() *Report {
+	return &Report{Findings: []Finding{}}
+}
+
+// Add 添加一条诊断结果
+func (r *Report) Add(severity Severity, check, path, format string, args ...any) {
+	r.Findings = append(r.Findings, Finding{
+		Severity: severity,
+		Check:    check,
+		Path:     path,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// AddError 将错误添加为诊断结果：errors.Join 合并的错误（包括被包装的）逐个展开，DecodeError 保留文件和行列
+func (r *Report) AddError(severity Severity, check string, err error) {
+	if err == nil {
+		return
+	}
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if joined, ok := e.(interface{ Unwrap() []error }); ok {
+			for _, inner := range joined.Unwrap() {
+				r.AddError(severity, check, inner)
+			}
+			return
+		}
+	}
+
+	var decodeErr *DecodeError
+	if errors.As(err, &decodeErr) {
+		r.Findings = append(r.Findings, Finding{
+			Severity: severity,
+			Check:    check,
+			File:     decodeErr.File,
+			Line:     decodeErr.Line,
+			Column:   decodeErr.Column,
+			Path:     decodeErr.Path,
+			Message:  decodeErr.Message,
+		})
+		return
+	}
+	r.Findings = append(r.Findings, Finding{Severity: severity, Check: check, Message: err.Error()})
+}
+
+// Introduced 返回不在 before 中的结果，用于只报告一次修改引入的问题
+func (r *Report) Introduced(before *Report) []Finding {
+	existing := make(map[string]bool)
+	for _, finding := range before.Findings {
+		existing[finding.String()] = true
+	}
+	var introduced []Finding
+	for _, finding := range r.Findings {
+		if !existing[finding.String()] {
+			introduced = append(introduced, finding)
+		}
+	}
+	return introduced
+}
+
+// Count 返回指定级别的结果数量
+func (r *Report) Count(severity Severity) int {
+	count := 0
+	for _, finding := range r.Findings {
+		if finding.Severity == severity {
+			count++
+		}
+	}
+	return count
+}
+
+// String 报告摘要
+func (r *Report) String() string {
+	return fmt.Sprintf("%d 个错误, %d 个警告, %d 个提示", r.Count(SeverityError), r.Count(SeverityWarning), r.Count(SeverityInfo))
+}
+
+// Sort 按级别、文件、行和路径排序
+func (r *Report) Sort() {
+	sort.SliceStable(r.Findings, func(i, j int) bool {
+		a, b := r.Findings[i], r.Findings[j]
+		if severityRank[a.Severity] != severityRank[b.Severity] {
+			return severityRank[a.Severity] < severityRank[b.Severity]
+		}
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Path < b.Path
+	})
+}
+
+// Err 将所有错误级别的结果合并为一个错误，没有错误时返回 nil
+func (r *Report) Err() error {
+	var messages []string
+	for _, finding := range r.Findings {
+		if finding.Severity == SeverityError {
+			messages = append(messages, finding.String())
+		}
+	}
+	if len(messages) == 0 {
+		return nil
+	}
+	return fmt.Errorf("配置验证失败:\n  - %s", strings.Join(messages, "\n  - "))
+}
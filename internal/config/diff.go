@@ -0,0 +1,112 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext 差异中每处修改前后保留的上下文行数
+const diffContext = 3
+
+// UnifiedDiff 生成统一格式（diff -u）的逐行差异，内容相同时返回空字符串
+func UnifiedDiff(name string, oldData, newData []byte) string {
+	oldLines := splitLines(string(oldData))
+	newLines := splitLines(string(newData))
+	lines := DiffLines(oldLines, newLines)
+
+	var out strings.Builder
+	for start := 0; start < len(lines); {
+		if lines[start].Op == ' ' {
+			start++
+			continue
+		}
+
+		// 合并间隔不超过 2*diffContext 行的修改为一个片段
+		from := max(start-diffContext, 0)
+		end := start
+		for k := start; k < len(lines) && k-end <= 2*diffContext; k++ {
+			if lines[k].Op != ' ' {
+				end = k
+			}
+		}
+		to := min(end+diffContext+1, len(lines))
+
+		if out.Len() == 0 {
+			fmt.Fprintf(&out, "--- %s\n+++ %s\n", name, name)
+		}
+		oldCount, newCount := 0, 0
+		for _, line := range lines[from:to] {
+			if line.Op != '+' {
+				oldCount++
+			}
+			if line.Op != '-' {
+				newCount++
+			}
+		}
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n", hunkRange(lines[from].Old, oldCount), hunkRange(lines[from].New, newCount))
+		for _, line := range lines[from:to] {
+			out.WriteString(string(line.Op) + line.Text + "\n")
+		}
+		start = to
+	}
+	return out.String()
+}
+
+// DiffLine 逐行差异中的一行
+type DiffLine struct {
+	Op       byte // ' ' 相同，'-' 删除，'+' 新增
+	Text     string
+	Old, New int // 该行之前已经过的旧、新行数
+}
+
+// DiffLines 基于最长公共子序列逐行比较，同一处修改中删除的行排在新增的行之前
+func DiffLines(oldLines, newLines []string) []DiffLine {
+	// lcs[i][j] 表示 oldLines[i:] 与 newLines[j:] 的最长公共子序列长度
+	lcs := make([][]int, len(oldLines)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(newLines)+1)
+	}
+	for i := len(oldLines) - 1; i >= 0; i-- {
+		for j := len(newLines) - 1; j >= 0; j-- {
+			if oldLines[i] == newLines[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var lines []DiffLine
+	i, j := 0, 0
+	for i < len(oldLines) || j < len(newLines) {
+		switch {
+		case i < len(oldLines) && j < len(newLines) && oldLines[i] == newLines[j]:
+			lines = append(lines, DiffLine{' ', oldLines[i], i, j})
+			i++
+			j++
+		case i < len(oldLines) && (j == len(newLines) || lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, DiffLine{'-', oldLines[i], i, j})
+			i++
+		default:
+			lines = append(lines, DiffLine{'+', newLines[j], i, j})
+			j++
+		}
+	}
+	return lines
+}
+
+// hunkRange 输出片段的起始行和行数（行数为 0 时起始行为前一行）
+func hunkRange(before, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", before)
+	}
+	return fmt.Sprintf("%d,%d", before+1, count)
+}
+
+// splitLines 按行拆分，忽略末尾换行产生的空行
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
@@ -0,0 +1,422 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigDocument 可原地修改的配置文件，只改动涉及的键:
+//   - JSON 直接修改原文，保留缩进、空行和其余键的顺序
+//   - YAML 修改节点树，保留注释
+//   - TOML 按中间表示重新生成，除 schema 引用外的注释不保留
+type ConfigDocument struct {
+	Path   string
+	Format ConfigFormat
+
+	data []byte         // 当前内容（JSON 为修改后的原文）
+	root *orderedObject // 当前内容的中间表示
+	yaml *yaml.Node     // YAML 文档节点
+}
+
+// LoadConfigDocument 读取配置文件用于修改
+func LoadConfigDocument(path string) (*ConfigDocument, error) {
+	format, err := FormatFromPath(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := ParseConfigDocument(data, format)
+	if err != nil {
+		return nil, &DecodeError{File: path, Message: err.Error()}
+	}
+	doc.Path = path
+	return doc, nil
+}
+
+// ParseConfigDocument 解析配置内容用于修改，顶层必须是对象
+func ParseConfigDocument(data []byte, format ConfigFormat) (*ConfigDocument, error) {
+	tree, err := parseConfigTree(data, format, nil)
+	if err != nil {
+		return nil, err
+	}
+	root, ok := tree.(*orderedObject)
+	if !ok {
+		return nil, fmt.Errorf("配置文件的顶层必须是对象")
+	}
+
+	doc := &ConfigDocument{Format: format, data: data, root: root}
+	switch format {
+	case FormatJSON:
+		if len(bytes.TrimSpace(data)) == 0 {
+			doc.data = []byte("{}\n")
+		}
+	case FormatYAML:
+		doc.yaml = &yaml.Node{}
+		if err := yaml.Unmarshal(data, doc.yaml); err != nil {
+			return nil, fmt.Errorf("YAML 解析失败: %w", err)
+		}
+		if doc.yaml.Kind == 0 {
+			doc.yaml = &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+		}
+	}
+	return doc, nil
+}
+
+// Get 返回路径对应的值（中间表示）
+func (d *ConfigDocument) Get(path []string) (any, bool) {
+	var value any = d.root
+	for _, key := range path {
+		object, ok := value.(*orderedObject)
+		if !ok {
+			return nil, false
+		}
+		if value, ok = object.values[key]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// Set 设置路径对应的值，缺少的上级对象会自动创建，新键追加到所在对象的末尾
+func (d *ConfigDocument) Set(path []string, value any) error {
+	return d.setAt(path, value, -1)
+}
+
+// setAt 设置路径对应的值；键不存在时插入到所在对象的第 index 个位置（index < 0 表示末尾）
+func (d *ConfigDocument) setAt(path []string, value any, index int) error {
+	if len(path) == 0 {
+		return fmt.Errorf("路径不能为空")
+	}
+
+	// 先在中间表示上检查路径，确保三种格式的修改一致
+	object := d.root
+	for i, key := range path[:len(path)-1] {
+		child, exists := object.values[key]
+		if !exists {
+			break
+		}
+		next, ok := child.(*orderedObject)
+		if !ok {
+			return fmt.Errorf("%s 不是对象", strings.Join(path[:i+1], "."))
+		}
+		object = next
+	}
+
+	switch d.Format {
+	case FormatJSON:
+		data, err := setJSONText(d.data, path, value, index)
+		if err != nil {
+			return err
+		}
+		d.data = data
+	case FormatYAML:
+		setYAMLNode(d.yaml.Content[0], path, value, index)
+	}
+	setTree(d.root, path, value, index)
+	return nil
+}
+
+// Delete 删除路径对应的键，键不存在时返回 false
+func (d *ConfigDocument) Delete(path []string) (bool, error) {
+	if _, exists := d.Get(path); !exists || len(path) == 0 {
+		return false, nil
+	}
+
+	switch d.Format {
+	case FormatJSON:
+		data, err := deleteJSONText(d.data, path)
+		if err != nil {
+			return false, err
+		}
+		d.data = data
+	case FormatYAML:
+		deleteYAMLNode(d.yaml.Content[0], path)
+	}
+
+	parent, _ := d.Get(path[:len(path)-1])
+	parent.(*orderedObject).delete(path[len(path)-1])
+	return true, nil
+}
+
+// Bytes 返回修改后的文件内容
+func (d *ConfigDocument) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	switch d.Format {
+	case FormatJSON:
+		return d.data, nil
+	case FormatYAML:
+		encoder := yaml.NewEncoder(&buf)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(d.yaml); err != nil {
+			return nil, fmt.Errorf("生成 YAML 失败: %w", err)
+		}
+		if err := encoder.Close(); err != nil {
+			return nil, err
+		}
+	case FormatTOML:
+		if ref := commentSchemaRef(d.data, FormatTOML); ref != "" {
+			buf.WriteString(schemaComments[FormatTOML] + ref + "\n")
+		}
+		if err := writeTOMLTable(&buf, d.root, nil); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("不支持的格式: %s", d.Format)
+	}
+	return buf.Bytes(), nil
+}
+
+// delete 删除键
+func (o *orderedObject) delete(key string) {
+	delete(o.values, key)
+	o.keys = slices.DeleteFunc(o.keys, func(k string) bool { return k == key })
+}
+
+// setTree 在中间表示上设置值，缺少的上级对象自动创建
+func setTree(object *orderedObject, path []string, value any, index int) {
+	for _, key := range path[:len(path)-1] {
+		child, ok := object.values[key].(*orderedObject)
+		if !ok {
+			child = newOrderedObject()
+			object.set(key, child)
+		}
+		object = child
+	}
+
+	key := path[len(path)-1]
+	if _, exists := object.values[key]; exists || index < 0 || index >= len(object.keys) {
+		object.set(key, value)
+		return
+	}
+	object.keys = slices.Insert(object.keys, index, key)
+	object.values[key] = value
+}
+
+// nestedValue 将 path 包装为嵌套对象，如 [a b] 和 v 得到 {"a": {"b": v}}
+func nestedValue(path []string, value any) any {
+	for i := len(path) - 1; i >= 0; i-- {
+		object := newOrderedObject()
+		object.set(path[i], value)
+		value = object
+	}
+	return value
+}
+
+// jsonMember JSON 对象成员在原文中的位置
+type jsonMember struct {
+	key        string
+	keyStart   int // 键的左引号
+	valueStart int
+	valueEnd   int // 值之后的第一个字节
+}
+
+// jsonObject 解析 open 处（左花括号）的对象，返回各成员的位置和右花括号的位置
+func jsonObject(data []byte, open int) ([]jsonMember, int, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data[open:]))
+	if tok, err := decoder.Token(); err != nil || tok != json.Delim('{') {
+		return nil, 0, fmt.Errorf("第 %d 字节处不是 JSON 对象", open)
+	}
+
+	var members []jsonMember
+	for decoder.More() {
+		tok, err := decoder.Token()
+		if err != nil {
+			return nil, 0, err
+		}
+		keyEnd := open + int(decoder.InputOffset())
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			return nil, 0, err
+		}
+		valueEnd := open + int(decoder.InputOffset())
+		members = append(members, jsonMember{
+			key:        tok.(string),
+			keyStart:   keyStart(data, keyEnd),
+			valueStart: valueEnd - len(bytes.TrimSpace(raw)),
+			valueEnd:   valueEnd,
+		})
+	}
+	if _, err := decoder.Token(); err != nil {
+		return nil, 0, err
+	}
+	return members, open + int(decoder.InputOffset()) - 1, nil
+}
+
+// jsonRoot 返回顶层对象左花括号的位置
+func jsonRoot(data []byte) (int, error) {
+	open := len(data) - len(bytes.TrimLeft(data, " \t\r\n"))
+	if open >= len(data) || data[open] != '{' {
+		return 0, fmt.Errorf("JSON 的顶层必须是对象")
+	}
+	return open, nil
+}
+
+// lineIndent 返回 offset 所在行的行首空白
+func lineIndent(data []byte, offset int) string {
+	start := bytes.LastIndexByte(data[:offset], '\n') + 1
+	end := start
+	for end < offset && (data[end] == ' ' || data[end] == '\t') {
+		end++
+	}
+	return string(data[start:end])
+}
+
+// renderJSON 按所在行的缩进输出值
+func renderJSON(value any, indent string) string {
+	var buf bytes.Buffer
+	writeJSONTree(&buf, value, indent)
+	return buf.String()
+}
+
+// setJSONText 在 JSON 原文中设置值：已有的键只替换值，新键按相邻成员的缩进插入
+func setJSONText(data []byte, path []string, value any, index int) ([]byte, error) {
+	open, err := jsonRoot(data)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, key := range path {
+		members, close, err := jsonObject(data, open)
+		if err != nil {
+			return nil, err
+		}
+		position := slices.IndexFunc(members, func(m jsonMember) bool { return m.key == key })
+		if position < 0 {
+			return insertJSONMember(data, members, open, close, key, nestedValue(path[i+1:], value), index), nil
+		}
+
+		member := members[position]
+		if i == len(path)-1 {
+			rendered := renderJSON(value, lineIndent(data, member.keyStart))
+			return slices.Concat(data[:member.valueStart], []byte(rendered), data[member.valueEnd:]), nil
+		}
+		open = member.valueStart
+	}
+	return data, nil
+}
+
+// insertJSONMember 在对象中插入新成员
+func insertJSONMember(data []byte, members []jsonMember, open, close int, key string, value any, index int) []byte {
+	indent := lineIndent(data, open) + "  "
+	if len(members) > 0 {
+		indent = lineIndent(data, members[0].keyStart)
+	}
+	member := renderJSON(key, "") + ": " + renderJSON(value, indent)
+	// 写在一行内的对象（如 {"pacman": "git"}）在同一行插入
+	separator := ",\n" + indent
+	if len(members) > 0 && !bytes.Contains(data[open:close], []byte("\n")) {
+		separator = ", "
+	}
+
+	switch {
+	case len(members) == 0:
+		text := "{\n" + indent + member + "\n" + lineIndent(data, open) + "}"
+		return slices.Concat(data[:open], []byte(text), data[close+1:])
+	case index < 0 || index >= len(members):
+		last := members[len(members)-1].valueEnd
+		return slices.Concat(data[:last], []byte(separator+member), data[last:])
+	default:
+		at := members[index].keyStart
+		return slices.Concat(data[:at], []byte(member+separator), data[at:])
+	}
+}
+
+// deleteJSONText 在 JSON 原文中删除键及其前后的逗号和空白
+func deleteJSONText(data []byte, path []string) ([]byte, error) {
+	open, err := jsonRoot(data)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, key := range path {
+		members, close, err := jsonObject(data, open)
+		if err != nil {
+			return nil, err
+		}
+		position := slices.IndexFunc(members, func(m jsonMember) bool { return m.key == key })
+		if position < 0 {
+			return data, nil
+		}
+		if i < len(path)-1 {
+			open = members[position].valueStart
+			continue
+		}
+
+		switch {
+		case len(members) == 1:
+			return slices.Concat(data[:open+1], data[close:]), nil
+		case position > 0:
+			return slices.Concat(data[:members[position-1].valueEnd], data[members[position].valueEnd:]), nil
+		default:
+			return slices.Concat(data[:members[0].keyStart], data[members[1].keyStart:]), nil
+		}
+	}
+	return data, nil
+}
+
+// setYAMLNode 在 YAML 映射节点中设置值，替换已有的值时保留其注释
+func setYAMLNode(mapping *yaml.Node, path []string, value any, index int) {
+	for i, key := range path {
+		position := yamlKeyIndex(mapping, key)
+		if position < 0 {
+			keyNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}
+			valueNode := yamlNodeFromTree(nestedValue(path[i+1:], value))
+			if index < 0 || index*2 >= len(mapping.Content) {
+				mapping.Content = append(mapping.Content, keyNode, valueNode)
+				return
+			}
+			// 插入到开头时，原第一个键上方的注释（如 schema 引用）仍留在最上方
+			if index == 0 {
+				keyNode.HeadComment, mapping.Content[0].HeadComment = mapping.Content[0].HeadComment, ""
+			}
+			mapping.Content = slices.Insert(mapping.Content, index*2, keyNode, valueNode)
+			return
+		}
+
+		if i == len(path)-1 {
+			old := mapping.Content[position+1]
+			node := yamlNodeFromTree(value)
+			node.HeadComment, node.LineComment, node.FootComment = old.HeadComment, old.LineComment, old.FootComment
+			mapping.Content[position+1] = node
+			return
+		}
+		mapping = mapping.Content[position+1]
+	}
+}
+
+// deleteYAMLNode 删除 YAML 映射节点中的键
+func deleteYAMLNode(mapping *yaml.Node, path []string) {
+	for i, key := range path {
+		position := yamlKeyIndex(mapping, key)
+		if position < 0 {
+			return
+		}
+		if i < len(path)-1 {
+			mapping = mapping.Content[position+1]
+			continue
+		}
+		mapping.Content = slices.Delete(mapping.Content, position, position+2)
+	}
+}
+
+// yamlKeyIndex 返回映射节点中键所在的下标，不存在时返回 -1
+func yamlKeyIndex(mapping *yaml.Node, key string) int {
+	if mapping.Kind != yaml.MappingNode {
+		return -1
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
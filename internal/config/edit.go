@@ -0,0 +1,290 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// configPathPrefixes 点路径的第一段可以选择配置文件，没有这些前缀时为 shared
+var configPathPrefixes = map[string]string{
+	"shared":             "shared",
+	"zsh":                "zsh_integration",
+	"zsh_integration":    "zsh_integration",
+	"packages":           "packages",
+	"functions":          "advanced_functions",
+	"advanced_functions": "advanced_functions",
+}
+
+// ConfigPath 配置项的点路径，如 user.email、zsh.proxy.active_profile
+type ConfigPath struct {
+	Kind string   // 配置种类（schema 名称）
+	Keys []string // 文件内的键路径
+}
+
+// ParseConfigPath 解析点路径：第一段为 zsh、packages、functions 等时选择对应的配置文件，
+// 键名中的点写作 \.
+func ParseConfigPath(path string) (ConfigPath, error) {
+	var keys []string
+	var key strings.Builder
+	for i := 0; i < len(path); i++ {
+		switch {
+		case path[i] == '\\' && i+1 < len(path) && path[i+1] == '.':
+			key.WriteByte('.')
+			i++
+		case path[i] == '.':
+			keys = append(keys, key.String())
+			key.Reset()
+		default:
+			key.WriteByte(path[i])
+		}
+	}
+	keys = append(keys, key.String())
+	if slices.Contains(keys, "") {
+		return ConfigPath{}, fmt.Errorf("无效的路径: %q", path)
+	}
+
+	if kind, ok := configPathPrefixes[keys[0]]; ok && len(keys) > 1 {
+		return ConfigPath{Kind: kind, Keys: keys[1:]}, nil
+	}
+	return ConfigPath{Kind: "shared", Keys: keys}, nil
+}
+
+// String 返回文件内的点路径
+func (p ConfigPath) String() string {
+	escaped := make([]string, len(p.Keys))
+	for i, key := range p.Keys {
+		escaped[i] = strings.ReplaceAll(key, ".", `\.`)
+	}
+	return strings.Join(escaped, ".")
+}
+
+// valueType 返回路径对应的 Go 类型，任意值（interface{}）返回 nil；PathValue 之后的一段为平台键
+func (p ConfigPath) valueType() (reflect.Type, error) {
+	spec, err := findConfigSchema(p.Kind)
+	if err != nil {
+		return nil, err
+	}
+
+	t := spec.root
+	for i, key := range p.Keys {
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		current := strings.Join(p.Keys[:i], ".")
+		switch {
+		case t == pathValueType:
+			if !slices.Contains(pathValueKeys, key) {
+				return nil, fmt.Errorf("%s: 无效的平台键 %s（可用: %s）", current, key, strings.Join(pathValueKeys, ", "))
+			}
+			t = reflect.TypeOf("")
+		case t.Kind() == reflect.Struct:
+			field, ok := structFieldType(t, key)
+			if !ok {
+				return nil, fmt.Errorf("%s: 未知字段", joinPath(current, key))
+			}
+			t = field
+		case t.Kind() == reflect.Map:
+			if i == 0 && isReservedKey(key) {
+				t = reflect.TypeOf("")
+			} else {
+				t = t.Elem()
+			}
+		case t.Kind() == reflect.Interface:
+			return nil, nil
+		default:
+			return nil, fmt.Errorf("%s 不是对象", current)
+		}
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Interface {
+		return nil, nil
+	}
+	return t, nil
+}
+
+// IsPathValue 路径是否指向 PathValue（字符串或平台 -> 路径的对象）
+func (p ConfigPath) IsPathValue() bool {
+	t, err := p.valueType()
+	return err == nil && t == pathValueType
+}
+
+// ParseConfigValue 按路径对应的类型解析命令行给出的值：字符串原样使用，布尔值和数字按字面解析，
+// 对象和数组需要写成 JSON；任意值先尝试 JSON，失败时作为字符串
+func (p ConfigPath) ParseConfigValue(raw string) (any, error) {
+	t, err := p.valueType()
+	if err != nil {
+		return nil, err
+	}
+	if raw == DeleteMarker {
+		return raw, nil
+	}
+
+	if t == nil {
+		if value, err := parseJSONValue(raw); err == nil {
+			return value, nil
+		}
+		return raw, nil
+	}
+	if t == pathValueType {
+		if strings.HasPrefix(strings.TrimSpace(raw), "{") {
+			return parseJSONValue(raw)
+		}
+		return raw, nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return raw, nil
+	case reflect.Bool:
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s 应为布尔值（true 或 false），实际为 %q", p, raw)
+		}
+		return value, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if _, err := strconv.ParseInt(raw, 10, 64); err != nil {
+			return nil, fmt.Errorf("%s 应为整数，实际为 %q", p, raw)
+		}
+		return json.Number(raw), nil
+	case reflect.Float32, reflect.Float64:
+		if _, err := strconv.ParseFloat(raw, 64); err != nil {
+			return nil, fmt.Errorf("%s 应为数字，实际为 %q", p, raw)
+		}
+		return json.Number(raw), nil
+	}
+
+	value, err := parseJSONValue(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%s 的值需要写成 JSON: %w", p, err)
+	}
+	return value, nil
+}
+
+// parseJSONValue 将 JSON 文本解析为中间表示
+func parseJSONValue(raw string) (any, error) {
+	decoder := json.NewDecoder(strings.NewReader(raw))
+	decoder.UseNumber()
+	value, err := jsonTree(decoder)
+	if err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("JSON 之后有多余的内容")
+	}
+	return value, nil
+}
+
+// SetConfigValue 设置路径对应的值；设置 PathValue 的平台键而原值是字符串时，原值保留为 default
+func SetConfigValue(doc *ConfigDocument, path ConfigPath, value any) error {
+	if len(path.Keys) > 1 {
+		parent := ConfigPath{Kind: path.Kind, Keys: path.Keys[:len(path.Keys)-1]}
+		if current, ok := doc.Get(parent.Keys); ok && parent.IsPathValue() {
+			if s, isString := current.(string); isString {
+				object := newOrderedObject()
+				object.set("default", s)
+				if err := doc.Set(parent.Keys, object); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return doc.Set(path.Keys, value)
+}
+
+// NewConfigDocument 创建尚不存在的配置文件（只包含当前格式版本）
+func NewConfigDocument(path string) (*ConfigDocument, error) {
+	format, err := FormatFromPath(path)
+	if err != nil {
+		return nil, err
+	}
+	var data []byte
+	if format == FormatJSON {
+		data = []byte("{}\n")
+	}
+	doc, err := ParseConfigDocument(data, format)
+	if err != nil {
+		return nil, err
+	}
+	doc.Path = path
+	if err := doc.Set([]string{VersionKey}, CurrentConfigVersion); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// Save 将修改写回文件，保留原有的文件权限
+func (d *ConfigDocument) Save() error {
+	data, err := d.Bytes()
+	if err != nil {
+		return err
+	}
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(d.Path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.MkdirAll(filepath.Dir(d.Path), 0755); err != nil {
+		return fmt.Errorf("创建目录失败: %w", err)
+	}
+	if err := os.WriteFile(d.Path, data, mode); err != nil {
+		return fmt.Errorf("写入 %s 失败: %w", d.Path, err)
+	}
+	return nil
+}
+
+// FormatConfigValue 输出配置值：字符串原样输出，其他值输出为 JSON（两空格缩进）
+func FormatConfigValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case *orderedObject, []any, json.Number, bool, nil:
+		var buf bytes.Buffer
+		writeJSONTree(&buf, v, "")
+		return buf.String()
+	}
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(value); err != nil {
+		return fmt.Sprint(value)
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// LookupValue 在合并后的原始数据中按键路径取值
+func LookupValue(data map[string]interface{}, keys []string) (any, bool) {
+	var value any = data
+	for _, key := range keys {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if value, ok = object[key]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// ResolvePathValue 返回 PathValue 形式的值在指定平台（或 shell）上的取值
+func ResolvePathValue(value any, platform string) (string, error) {
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	data := FormatConfigValue(value)
+	var pv PathValue
+	if err := json.Unmarshal([]byte(data), &pv); err != nil {
+		return "", fmt.Errorf("不是有效的路径值: %s", data)
+	}
+	return pv.Get(platform), nil
+}
@@ -0,0 +1,54 @@
+package config
+
+import "testing"
+
+// TestSetConfigValue 测试点路径解析、按类型解析值、PathValue 平台键和 schema 检查
+func TestSetConfigValue(t *testing.T) {
+	path, err := ParseConfigPath(`zsh.proxy.active_profile`)
+	if err != nil || path.Kind != "zsh_integration" || path.String() != "proxy.active_profile" {
+		t.Fatalf("解析结果不符: %+v %v", path, err)
+	}
+	if path, _ = ParseConfigPath(`packages.categories.dev.packages.python3\.12`); path.Keys[len(path.Keys)-1] != "python3.12" {
+		t.Errorf("转义的点应保留在键名中: %v", path.Keys)
+	}
+
+	enabled, _ := ParseConfigPath("zsh.proxy.enabled")
+	if _, err := enabled.ParseConfigValue("yes"); err == nil {
+		t.Errorf("布尔字段应拒绝 yes")
+	}
+
+	doc, err := ParseConfigDocument([]byte("{\n  \"paths\": {\n    \"projects\": \"~/p\"\n  }\n}\n"), FormatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	windows, _ := ParseConfigPath("paths.projects.windows")
+	if err := SetConfigValue(doc, windows, `D:\p`); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := doc.Bytes()
+	want := "{\n  \"paths\": {\n    \"projects\": {\n      \"default\": \"~/p\",\n      \"windows\": \"D:\\\\p\"\n    }\n  }\n}\n"
+	if string(data) != want {
+		t.Errorf("结果不符:\n%s\n期望:\n%s", data, want)
+	}
+	if value, _ := doc.Get([]string{"paths", "projects"}); mustResolve(t, value, "linux") != "~/p" {
+		t.Errorf("linux 应回退到 default")
+	}
+
+	email, _ := ParseConfigPath("user.email")
+	if err := SetConfigValue(doc, email, "not-an-email"); err != nil {
+		t.Fatal(err)
+	}
+	report, err := doc.CheckSchema("shared")
+	if err != nil || len(report.Findings) != 1 {
+		t.Errorf("无效的邮箱应有一项 schema 问题: %v %v", report, err)
+	}
+}
+
+func mustResolve(t *testing.T, value any, platform string) string {
+	t.Helper()
+	resolved, err := ResolvePathValue(value, platform)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resolved
+}
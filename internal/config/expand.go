@@ -0,0 +1,162 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ExpandError ${VAR:?信息} 或 ${VAR?信息} 中的变量未设置（或为空）
+type ExpandError struct {
+	Name    string
+	Message string
+}
+
+func (e *ExpandError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Name, e.Message)
+}
+
+// ExpandEnv 使用当前进程的环境变量展开 s，见 Expand
+func ExpandEnv(s string) (string, error) {
+	return Expand(s, os.LookupEnv)
+}
+
+// Expand 按 POSIX 参数展开的规则展开 s 中的变量
+//
+//	$VAR、${VAR}       变量的值，未设置时为空
+//	${VAR:-默认值}     VAR 未设置或为空时使用默认值；${VAR-默认值} 只在未设置时使用
+//	${VAR:+替代值}     VAR 非空时使用替代值，否则为空；${VAR+替代值} 在已设置时使用
+//	${VAR:?错误信息}   VAR 未设置或为空时返回 *ExpandError；${VAR?错误信息} 只在未设置时报错
+//
+// 默认值、替代值和错误信息中可以嵌套变量，只在用到时展开。\$ 表示字面量 $；
+// ${...} 内部 \} 和 \\ 分别表示 } 和 \。其他反斜杠原样保留（如 Windows 路径）。
+// 变量名不合法的 ${...}（如 zsh 的 ${(s.:.)LS_COLORS}）、不支持的运算符和 $ 后不是变量名的内容原样保留。
+func Expand(s string, lookup func(string) (string, bool)) (string, error) {
+	return expandWord(s, lookup, false)
+}
+
+// expandWord 展开字符串；inBraces 表示位于 ${...} 的默认值、替代值或错误信息中
+func expandWord(s string, lookup func(string) (string, bool), inBraces bool) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && (s[i+1] == '$' || inBraces && (s[i+1] == '}' || s[i+1] == '\\')):
+			b.WriteByte(s[i+1])
+			i += 2
+		case c == '$':
+			n, value, err := expandParameter(s[i:], lookup)
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(value)
+			i += n
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return b.String(), nil
+}
+
+// expandParameter 展开以 $ 开头的一个参数，返回消耗的字节数和结果
+func expandParameter(s string, lookup func(string) (string, bool)) (int, string, error) {
+	if len(s) < 2 {
+		return 1, "$", nil
+	}
+	if s[1] != '{' {
+		name := variableName(s[1:])
+		if name == "" {
+			return 1, "$", nil
+		}
+		value, _ := lookup(name)
+		return 1 + len(name), value, nil
+	}
+
+	end := closingBrace(s)
+	if end < 0 {
+		return 1, "$", nil
+	}
+	literal := s[:end+1]
+	body := s[2:end]
+	name := variableName(body)
+	if name == "" {
+		return len(literal), literal, nil
+	}
+
+	value, set := lookup(name)
+	rest := body[len(name):]
+	if rest == "" {
+		return len(literal), value, nil
+	}
+
+	colon := strings.HasPrefix(rest, ":")
+	if colon {
+		rest = rest[1:]
+	}
+	if rest == "" || !strings.ContainsRune("-+?", rune(rest[0])) {
+		return len(literal), literal, nil
+	}
+	op, word := rest[0], rest[1:]
+	unset := !set || colon && value == ""
+
+	switch op {
+	case '-':
+		if unset {
+			expanded, err := expandWord(word, lookup, true)
+			return len(literal), expanded, err
+		}
+	case '+':
+		if unset {
+			return len(literal), "", nil
+		}
+		expanded, err := expandWord(word, lookup, true)
+		return len(literal), expanded, err
+	case '?':
+		if unset {
+			message, err := expandWord(word, lookup, true)
+			if err != nil {
+				return 0, "", err
+			}
+			if message == "" {
+				message = "参数为空或未设置"
+			}
+			return 0, "", &ExpandError{Name: name, Message: message}
+		}
+	}
+	return len(literal), value, nil
+}
+
+// variableName 返回 s 开头的变量名（字母或下划线开头，由字母、数字和下划线组成）
+func variableName(s string) string {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || i > 0 && '0' <= c && c <= '9' {
+			continue
+		}
+		return s[:i]
+	}
+	return s
+}
+
+// closingBrace 返回与 s 开头的 ${ 匹配的 } 的位置（跳过转义字符，计算嵌套的 ${），不存在时返回 -1
+func closingBrace(s string) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '$':
+			if i+1 < len(s) && s[i+1] == '{' {
+				depth++
+				i++
+			}
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
@@ -0,0 +1,101 @@
+package config
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestExpand 测试 POSIX 参数展开、嵌套和转义
+func TestExpand(t *testing.T) {
+	env := map[string]string{
+		"HOME":    "/home/afu",
+		"PROFILE": "work",
+		"EMPTY":   "",
+		"HOST":    "proxy.local",
+	}
+	lookup := func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"$HOME/Projects", "/home/afu/Projects"},
+		{"${HOME}/Projects", "/home/afu/Projects"},
+		{"$UNSET/x", "/x"},
+		{"${PROXY_PROFILE:-default}", "default"},
+		{"${PROFILE:-default}", "work"},
+		{"${EMPTY:-default}", "default"},
+		{"${EMPTY-default}", ""},
+		{"${UNSET-default}", "default"},
+		{"${PROFILE:+--profile=$PROFILE}", "--profile=work"},
+		{"${EMPTY:+set}", ""},
+		{"${EMPTY+set}", "set"},
+		{"${UNSET+set}", ""},
+		// 嵌套
+		{"${WORK_PROXY_HTTP:-http://${HOST}:8080}", "http://proxy.local:8080"},
+		{"${A:-${B:-${PROFILE}}}", "work"},
+		{"${A:-{braces}}", "{braces}"},
+		// 转义
+		{`\$HOME`, "$HOME"},
+		{`${A:-a\}b}`, "a}b"},
+		{`${A:-\${HOME\}}`, "${HOME}"},
+		{`${A:-c:\\temp}`, `c:\temp`},
+		{`D:\Projects\$HOME`, `D:\Projects$HOME`},
+		// 原样保留
+		{"${(s.:.)LS_COLORS}", "${(s.:.)LS_COLORS}"},
+		{"${HOME:=x}", "${HOME:=x}"},
+		{"cost: 5$", "cost: 5$"},
+		{"$1 ${", "$1 ${"},
+	}
+
+	for _, tt := range tests {
+		got, err := Expand(tt.input, lookup)
+		if err != nil {
+			t.Errorf("Expand(%q) 返回错误: %v", tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Expand(%q) = %q，期望 %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+// TestExpandRequired 测试 ${VAR:?信息} 的错误和延迟展开
+func TestExpandRequired(t *testing.T) {
+	lookup := func(name string) (string, bool) {
+		if name == "TOKEN" {
+			return "secret", true
+		}
+		if name == "EMPTY" {
+			return "", true
+		}
+		return "", false
+	}
+
+	if got, err := Expand("${TOKEN:?必须设置 TOKEN}", lookup); err != nil || got != "secret" {
+		t.Errorf("已设置的变量应该正常展开，实际 %q, %v", got, err)
+	}
+	// 未使用的默认值不展开，其中的必需变量不报错
+	if got, err := Expand("${TOKEN:-${MISSING:?不应报错}}", lookup); err != nil || got != "secret" {
+		t.Errorf("未使用的默认值不应展开，实际 %q, %v", got, err)
+	}
+	if _, err := Expand("${EMPTY?x}", lookup); err != nil {
+		t.Errorf("${EMPTY?x} 只在未设置时报错，实际 %v", err)
+	}
+
+	_, err := Expand("token=${WORK_TOKEN:?请设置 ${TOKEN_NAME:-WORK_TOKEN}}", lookup)
+	var expandErr *ExpandError
+	if !errors.As(err, &expandErr) {
+		t.Fatalf("期望 ExpandError，实际 %v", err)
+	}
+	if expandErr.Name != "WORK_TOKEN" || expandErr.Message != "请设置 WORK_TOKEN" {
+		t.Errorf("错误内容不正确: %+v", expandErr)
+	}
+
+	if _, err := Expand("${EMPTY:?}", lookup); err == nil || err.Error() != "EMPTY: 参数为空或未设置" {
+		t.Errorf("空信息应该使用默认提示，实际 %v", err)
+	}
+}
@@ -0,0 +1,651 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFormat 配置文件格式
+type ConfigFormat string
+
+const (
+	FormatJSON ConfigFormat = "json"
+	FormatYAML ConfigFormat = "yaml"
+	FormatTOML ConfigFormat = "toml"
+)
+
+// configExtensions 查找配置文件时依次尝试的扩展名
+var configExtensions = []string{".json", ".yaml", ".yml", ".toml"}
+
+// FormatFromPath 根据扩展名判断配置文件格式
+func FormatFromPath(path string) (ConfigFormat, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return FormatJSON, nil
+	case ".yaml", ".yml":
+		return FormatYAML, nil
+	case ".toml":
+		return FormatTOML, nil
+	}
+	return "", fmt.Errorf("不支持的配置文件格式: %s（支持 .json、.yaml、.yml、.toml）", path)
+}
+
+// ParseFormat 解析格式名称（json、yaml、yml、toml）
+func ParseFormat(name string) (ConfigFormat, error) {
+	return FormatFromPath("config." + name)
+}
+
+// FindConfigFile 按 .json、.yaml、.yml、.toml 的顺序查找 dir 下名为 base 的配置文件
+func FindConfigFile(dir, base string) (string, error) {
+	var found []string
+	for _, ext := range configExtensions {
+		path := filepath.Join(dir, base+ext)
+		if _, err := os.Stat(path); err == nil {
+			found = append(found, path)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return "", fmt.Errorf("未找到配置文件 %s（支持 %s）: %w", filepath.Join(dir, base), strings.Join(configExtensions, "、"), os.ErrNotExist)
+	case 1:
+		return found[0], nil
+	}
+	return "", fmt.Errorf("配置文件 %s 存在多种格式: %s，请只保留一个", base, strings.Join(found, ", "))
+}
+
+// readConfigJSON 读取任意格式的配置文件并转换为 JSON；非 JSON 文件同时返回每个键在原文件中的位置
+func readConfigJSON(path string) ([]byte, map[string]location, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	format, err := FormatFromPath(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	if format == FormatJSON {
+		return data, nil, nil
+	}
+
+	locations := make(map[string]location)
+	tree, err := parseConfigTree(data, format, locations)
+	if err != nil {
+		var syntaxErr *configSyntaxError
+		if errors.As(err, &syntaxErr) {
+			return nil, nil, &DecodeError{File: path, Line: syntaxErr.line, Column: syntaxErr.column, Message: syntaxErr.message}
+		}
+		return nil, nil, &DecodeError{File: path, Message: err.Error()}
+	}
+	// schema 注释等同于 JSON 中的 $schema 键，保存时（如 SavePackagesFile）可以写回
+	if object, ok := tree.(*orderedObject); ok {
+		if ref := commentSchemaRef(data, format); ref != "" {
+			if _, exists := object.values[SchemaKey]; !exists {
+				object.set(SchemaKey, ref)
+			}
+		}
+	}
+	var buf bytes.Buffer
+	writeJSONTree(&buf, tree, "")
+	return buf.Bytes(), locations, nil
+}
+
+// loadConfigFile 读取任意格式的配置文件并解码到 T（不检查未知字段）
+func loadConfigFile[T any](path string) (*T, error) {
+	data, _, err := readConfigJSON(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var config T
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("解析配置文件 %s 失败: %w", path, err)
+	}
+	return &config, nil
+}
+
+// ConvertConfig 在 JSON、YAML 和 TOML 之间转换配置文件内容，保持键的顺序（TOML 输入按键名排序）
+//
+// 注释无法保留，但 schema 引用会在 JSON 的 "$schema" 键和 YAML/TOML 的 schema 注释之间转换；
+// TOML 没有 null，转换为 TOML 时 null 值被省略。
+func ConvertConfig(data []byte, from, to ConfigFormat) ([]byte, error) {
+	tree, err := parseConfigTree(data, from, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	schemaRef := commentSchemaRef(data, from)
+	root, isObject := tree.(*orderedObject)
+	if isObject {
+		if ref := takeSchemaRef(root); ref != "" {
+			schemaRef = ref
+		}
+	}
+
+	var buf bytes.Buffer
+	if prefix, ok := schemaComments[to]; ok && schemaRef != "" {
+		buf.WriteString(prefix + schemaRef + "\n")
+	}
+	switch to {
+	case FormatJSON:
+		if isObject && schemaRef != "" {
+			root.keys = append([]string{SchemaKey}, root.keys...)
+			root.values[SchemaKey] = schemaRef
+		}
+		writeJSONTree(&buf, tree, "")
+		buf.WriteByte('\n')
+	case FormatYAML:
+		encoder := yaml.NewEncoder(&buf)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(yamlNodeFromTree(tree)); err != nil {
+			return nil, fmt.Errorf("生成 YAML 失败: %w", err)
+		}
+		if err := encoder.Close(); err != nil {
+			return nil, err
+		}
+	case FormatTOML:
+		if !isObject {
+			return nil, fmt.Errorf("TOML 的顶层必须是对象")
+		}
+		if err := writeTOMLTable(&buf, root, nil); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("不支持的目标格式: %s", to)
+	}
+	return buf.Bytes(), nil
+}
+
+// orderedObject 保持键顺序的对象，作为各格式之间转换的中间表示
+//
+// 中间表示的取值: *orderedObject、[]any、string、json.Number、bool 和 nil。
+type orderedObject struct {
+	keys   []string
+	values map[string]any
+}
+
+// set 设置键值，新键追加到末尾
+func (o *orderedObject) set(key string, value any) {
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+}
+
+func newOrderedObject() *orderedObject {
+	return &orderedObject{values: make(map[string]any)}
+}
+
+// parseConfigTree 将配置文件内容解析为中间表示；locations 不为 nil 时记录 YAML 键的行列
+func parseConfigTree(data []byte, format ConfigFormat, locations map[string]location) (any, error) {
+	switch format {
+	case FormatJSON:
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		tree, err := jsonTree(decoder)
+		if err != nil {
+			return nil, fmt.Errorf("JSON 解析失败: %w", err)
+		}
+		return tree, nil
+	case FormatYAML:
+		var doc yaml.Node
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			if match := yamlErrorLine.FindStringSubmatch(err.Error()); match != nil {
+				line, _ := strconv.Atoi(match[1])
+				return nil, &configSyntaxError{format: format, line: line, message: "YAML 语法错误: " + match[2]}
+			}
+			return nil, fmt.Errorf("YAML 解析失败: %w", err)
+		}
+		if doc.Kind == 0 {
+			return newOrderedObject(), nil
+		}
+		return yamlTree(&doc, "", locations)
+	case FormatTOML:
+		var raw map[string]any
+		if err := toml.Unmarshal(data, &raw); err != nil {
+			var decodeErr *toml.DecodeError
+			if errors.As(err, &decodeErr) {
+				row, column := decodeErr.Position()
+				return nil, &configSyntaxError{format: format, line: row, column: column, message: "TOML 语法错误: " + decodeErr.Error()}
+			}
+			return nil, fmt.Errorf("TOML 解析失败: %w", err)
+		}
+		return tomlTree(raw), nil
+	}
+	return nil, fmt.Errorf("不支持的格式: %s", format)
+}
+
+// yamlErrorLine 匹配 yaml.v3 语法错误中的行号，如 "yaml: line 3: mapping values are not allowed in this context"
+var yamlErrorLine = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+// configSyntaxError YAML 或 TOML 语法错误，readConfigJSON 将其转换为定位到行列的 DecodeError
+type configSyntaxError struct {
+	format       ConfigFormat
+	line, column int // column 为 0 表示未知（yaml.v3 只报告行号）
+	message      string
+}
+
+// Error 格式: 第 3 行第 5 列: TOML 语法错误: ...
+func (e *configSyntaxError) Error() string {
+	if e.column > 0 {
+		return fmt.Sprintf("第 %d 行第 %d 列: %s", e.line, e.column, e.message)
+	}
+	return fmt.Sprintf("第 %d 行: %s", e.line, e.message)
+}
+
+// jsonTree 按顺序读取 JSON 值
+func jsonTree(decoder *json.Decoder) (any, error) {
+	tok, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch value := tok.(type) {
+	case json.Delim:
+		if value == '{' {
+			object := newOrderedObject()
+			for decoder.More() {
+				keyTok, err := decoder.Token()
+				if err != nil {
+					return nil, err
+				}
+				child, err := jsonTree(decoder)
+				if err != nil {
+					return nil, err
+				}
+				object.set(keyTok.(string), child)
+			}
+			_, err := decoder.Token()
+			return object, err
+		}
+		list := make([]any, 0)
+		for decoder.More() {
+			child, err := jsonTree(decoder)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, child)
+		}
+		_, err := decoder.Token()
+		return list, err
+	default:
+		return value, nil
+	}
+}
+
+// yamlTree 将 YAML 节点转换为中间表示，并记录每个键的行列
+func yamlTree(node *yaml.Node, path string, locations map[string]location) (any, error) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return newOrderedObject(), nil
+		}
+		return yamlTree(node.Content[0], path, locations)
+	case yaml.AliasNode:
+		return yamlTree(node.Alias, path, locations)
+	case yaml.MappingNode:
+		object := newOrderedObject()
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			keyNode, valueNode := node.Content[i], node.Content[i+1]
+			childPath := joinPath(path, keyNode.Value)
+			if locations != nil {
+				locations[childPath] = location{line: keyNode.Line, column: keyNode.Column}
+			}
+			child, err := yamlTree(valueNode, childPath, locations)
+			if err != nil {
+				return nil, err
+			}
+			object.set(keyNode.Value, child)
+		}
+		return object, nil
+	case yaml.SequenceNode:
+		list := make([]any, 0, len(node.Content))
+		for index, item := range node.Content {
+			child, err := yamlTree(item, fmt.Sprintf("%s[%d]", path, index), locations)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, child)
+		}
+		return list, nil
+	}
+
+	var value any
+	if err := node.Decode(&value); err != nil {
+		return nil, fmt.Errorf("第 %d 行: %w", node.Line, err)
+	}
+	return scalarTree(value), nil
+}
+
+// tomlTree 将 go-toml 解码的值转换为中间表示（TOML 表没有顺序，键按名称排序）
+func tomlTree(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		object := newOrderedObject()
+		for _, key := range sortedKeys(v) {
+			object.set(key, tomlTree(v[key]))
+		}
+		return object
+	case []any:
+		list := make([]any, 0, len(v))
+		for _, item := range v {
+			list = append(list, tomlTree(item))
+		}
+		return list
+	case toml.LocalDate, toml.LocalTime, toml.LocalDateTime:
+		return fmt.Sprint(v)
+	}
+	return scalarTree(value)
+}
+
+// scalarTree 将解码得到的标量统一为中间表示
+func scalarTree(value any) any {
+	switch v := value.(type) {
+	case int:
+		return json.Number(strconv.Itoa(v))
+	case int64:
+		return json.Number(strconv.FormatInt(v, 10))
+	case uint64:
+		return json.Number(strconv.FormatUint(v, 10))
+	case float64:
+		// JSON 无法表示 nan 和 inf，保留为字符串
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return strconv.FormatFloat(v, 'g', -1, 64)
+		}
+		return json.Number(strconv.FormatFloat(v, 'g', -1, 64))
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case string, bool, nil:
+		return v
+	}
+	return fmt.Sprint(value)
+}
+
+// writeJSONTree 以两空格缩进输出中间表示（不转义 HTML 字符）
+func writeJSONTree(buf *bytes.Buffer, value any, indent string) {
+	switch v := value.(type) {
+	case *orderedObject:
+		if len(v.keys) == 0 {
+			buf.WriteString("{}")
+			return
+		}
+		buf.WriteString("{\n")
+		for i, key := range v.keys {
+			buf.WriteString(indent + "  ")
+			writeJSONScalar(buf, key)
+			buf.WriteString(": ")
+			writeJSONTree(buf, v.values[key], indent+"  ")
+			if i < len(v.keys)-1 {
+				buf.WriteByte(',')
+			}
+			buf.WriteByte('\n')
+		}
+		buf.WriteString(indent + "}")
+	case []any:
+		if len(v) == 0 {
+			buf.WriteString("[]")
+			return
+		}
+		buf.WriteString("[\n")
+		for i, item := range v {
+			buf.WriteString(indent + "  ")
+			writeJSONTree(buf, item, indent+"  ")
+			if i < len(v)-1 {
+				buf.WriteByte(',')
+			}
+			buf.WriteByte('\n')
+		}
+		buf.WriteString(indent + "]")
+	default:
+		writeJSONScalar(buf, v)
+	}
+}
+
+// writeJSONScalar 输出 JSON 标量
+func writeJSONScalar(buf *bytes.Buffer, value any) {
+	var scalar bytes.Buffer
+	encoder := json.NewEncoder(&scalar)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(value)
+	buf.Write(bytes.TrimRight(scalar.Bytes(), "\n"))
+}
+
+// yamlNodeFromTree 将中间表示转换为 YAML 节点（多行字符串使用 | 块格式）
+func yamlNodeFromTree(value any) *yaml.Node {
+	switch v := value.(type) {
+	case *orderedObject:
+		node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for _, key := range v.keys {
+			node.Content = append(node.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+				yamlNodeFromTree(v.values[key]))
+		}
+		return node
+	case []any:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, item := range v {
+			node.Content = append(node.Content, yamlNodeFromTree(item))
+		}
+		return node
+	case string:
+		node := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v}
+		if strings.Contains(v, "\n") {
+			node.Style = yaml.LiteralStyle
+		}
+		return node
+	case json.Number:
+		tag := "!!int"
+		if strings.ContainsAny(string(v), ".eE") {
+			tag = "!!float"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: string(v)}
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(v)}
+	}
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+}
+
+// writeTOMLTable 输出 TOML 表：先输出标量和数组，再输出子表（[a.b]）和表数组（[[a.b]]）
+func writeTOMLTable(buf *bytes.Buffer, object *orderedObject, path []string) error {
+	var tables []string
+	for _, key := range object.keys {
+		value := object.values[key]
+		if value == nil {
+			continue
+		}
+		if isTOMLTable(value) {
+			tables = append(tables, key)
+			continue
+		}
+		var line bytes.Buffer
+		if err := writeTOMLValue(&line, value, append(path, key)); err != nil {
+			return err
+		}
+		fmt.Fprintf(buf, "%s = %s\n", tomlKey(key), line.String())
+	}
+
+	for _, key := range tables {
+		childPath := append(slices.Clone(path), key)
+		header := tomlKeyPath(childPath)
+		switch value := object.values[key].(type) {
+		case *orderedObject:
+			// 只包含子表的表不需要单独的表头
+			if !hasTOMLValues(value) && len(value.keys) > 0 {
+				if err := writeTOMLTable(buf, value, childPath); err != nil {
+					return err
+				}
+				continue
+			}
+			if buf.Len() > 0 {
+				buf.WriteByte('\n')
+			}
+			fmt.Fprintf(buf, "[%s]\n", header)
+			if err := writeTOMLTable(buf, value, childPath); err != nil {
+				return err
+			}
+		case []any:
+			for _, item := range value {
+				if buf.Len() > 0 {
+					buf.WriteByte('\n')
+				}
+				fmt.Fprintf(buf, "[[%s]]\n", header)
+				if err := writeTOMLTable(buf, item.(*orderedObject), childPath); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// hasTOMLValues 对象中是否有输出为 key = value 的项
+func hasTOMLValues(object *orderedObject) bool {
+	for _, key := range object.keys {
+		if value := object.values[key]; value != nil && !isTOMLTable(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// isTOMLTable 对象和非空的对象数组输出为表，其余输出为 key = value
+func isTOMLTable(value any) bool {
+	switch v := value.(type) {
+	case *orderedObject:
+		return true
+	case []any:
+		if len(v) == 0 {
+			return false
+		}
+		for _, item := range v {
+			if _, ok := item.(*orderedObject); !ok {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// writeTOMLValue 输出 TOML 值（数组中的对象输出为内联表）
+func writeTOMLValue(buf *bytes.Buffer, value any, path []string) error {
+	switch v := value.(type) {
+	case *orderedObject:
+		buf.WriteString("{")
+		first := true
+		for _, key := range v.keys {
+			if v.values[key] == nil {
+				continue
+			}
+			if !first {
+				buf.WriteString(",")
+			}
+			first = false
+			fmt.Fprintf(buf, " %s = ", tomlKey(key))
+			if err := writeTOMLValue(buf, v.values[key], append(path, key)); err != nil {
+				return err
+			}
+		}
+		if !first {
+			buf.WriteString(" ")
+		}
+		buf.WriteString("}")
+	case []any:
+		buf.WriteString("[")
+		for i, item := range v {
+			if item == nil {
+				return fmt.Errorf("%s: TOML 数组不能包含 null", tomlKeyPath(path))
+			}
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			if err := writeTOMLValue(buf, item, path); err != nil {
+				return err
+			}
+		}
+		buf.WriteString("]")
+	case string:
+		writeTOMLString(buf, v)
+	case json.Number:
+		buf.WriteString(string(v))
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+	}
+	return nil
+}
+
+// writeTOMLString 输出 TOML 基本字符串，多行内容使用 """ 格式（只转义会结束字符串的引号）
+func writeTOMLString(buf *bytes.Buffer, s string) {
+	multiline := strings.Contains(s, "\n")
+	if multiline {
+		buf.WriteString("\"\"\"\n")
+	} else {
+		buf.WriteByte('"')
+	}
+	for i, r := range s {
+		switch {
+		case r == '\\':
+			buf.WriteString(`\\`)
+		case r == '"' && (!multiline || strings.HasPrefix(s[i+1:], `""`) || i == len(s)-1):
+			buf.WriteString(`\"`)
+		case (r == '\n' || r == '\t') && multiline:
+			buf.WriteRune(r)
+		case r == '\n':
+			buf.WriteString(`\n`)
+		case r == '\t':
+			buf.WriteString(`\t`)
+		case r == '\r':
+			buf.WriteString(`\r`)
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(buf, `\u%04X`, r)
+		default:
+			buf.WriteRune(r)
+		}
+	}
+	if multiline {
+		buf.WriteString("\"\"\"")
+	} else {
+		buf.WriteByte('"')
+	}
+}
+
+// tomlKey 裸键只能包含字母、数字、- 和 _，其余键加引号
+func tomlKey(key string) string {
+	if key != "" && strings.Trim(key, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_-") == "" {
+		return key
+	}
+	var buf bytes.Buffer
+	writeTOMLString(&buf, strings.ReplaceAll(key, "\n", `\n`))
+	return buf.String()
+}
+
+// tomlKeyPath 输出点分隔的表名
+func tomlKeyPath(path []string) string {
+	keys := make([]string, len(path))
+	for i, key := range path {
+		keys[i] = tomlKey(key)
+	}
+	return strings.Join(keys, ".")
+}
+
+// sortedKeys 返回按名称排序的键
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,166 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// formatTestJSON 转换测试使用的 JSON（键不按字母顺序，包含 null、数组、多行字符串和 $schema）
+const formatTestJSON = `{
+  "$schema": "../schemas/shared.schema.json",
+  "version": "1.1.0",
+  "user": {
+    "name": "test",
+    "email": "test@example.com",
+    "editor": null
+  },
+  "paths": {
+    "projects": "$HOME/Projects",
+    "dotfiles": {
+      "windows": "D:\\dotfiles",
+      "default": "$HOME/dotfiles"
+    }
+  },
+  "features": {
+    "zsh_plugins": true,
+    "completion_cache": 3600
+  },
+  "profiles": [
+    {
+      "name": "work",
+      "tags": [
+        "a",
+        "b"
+      ]
+    }
+  ],
+  "motd": "line 1\nline 2 \"quoted\""
+}
+`
+
+// TestConvertConfig_YAMLRoundTrip 测试 JSON → YAML → JSON 保持键顺序、null 和 schema 引用
+func TestConvertConfig_YAMLRoundTrip(t *testing.T) {
+	yamlData, err := ConvertConfig([]byte(formatTestJSON), FormatJSON, FormatYAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	yamlText := string(yamlData)
+	if !strings.HasPrefix(yamlText, schemaComments[FormatYAML]+"../schemas/shared.schema.json\n") {
+		t.Errorf("$schema 应转换为 YAML 第一行的 schema 注释:\n%s", yamlText)
+	}
+	if strings.Count(yamlText, "shared.schema.json") != 1 {
+		t.Errorf("YAML 中不应保留 $schema 键:\n%s", yamlText)
+	}
+	if strings.Index(yamlText, "user:") > strings.Index(yamlText, "paths:") {
+		t.Errorf("YAML 应保持键的顺序:\n%s", yamlText)
+	}
+
+	jsonData, err := ConvertConfig(yamlData, FormatYAML, FormatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(jsonData) != formatTestJSON {
+		t.Errorf("JSON → YAML → JSON 应与原文相同，实际:\n%s", jsonData)
+	}
+}
+
+// TestConvertConfig_TOMLRoundTrip 测试 JSON → TOML → JSON：键按名称排序，null 被省略，schema 引用保留
+func TestConvertConfig_TOMLRoundTrip(t *testing.T) {
+	tomlData, err := ConvertConfig([]byte(formatTestJSON), FormatJSON, FormatTOML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(tomlData), schemaComments[FormatTOML]+"../schemas/shared.schema.json\n") {
+		t.Errorf("$schema 应转换为 TOML 第一行的 schema 注释:\n%s", tomlData)
+	}
+	if strings.Contains(string(tomlData), "editor") {
+		t.Errorf("TOML 中应省略 null 值:\n%s", tomlData)
+	}
+
+	jsonData, err := ConvertConfig(tomlData, FormatTOML, FormatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+  "$schema": "../schemas/shared.schema.json",
+  "features": {
+    "completion_cache": 3600,
+    "zsh_plugins": true
+  },
+  "motd": "line 1\nline 2 \"quoted\"",
+  "paths": {
+    "dotfiles": {
+      "default": "$HOME/dotfiles",
+      "windows": "D:\\dotfiles"
+    },
+    "projects": "$HOME/Projects"
+  },
+  "profiles": [
+    {
+      "name": "work",
+      "tags": [
+        "a",
+        "b"
+      ]
+    }
+  ],
+  "user": {
+    "email": "test@example.com",
+    "name": "test"
+  },
+  "version": "1.1.0"
+}
+`
+	if string(jsonData) != want {
+		t.Errorf("JSON → TOML → JSON 不符，实际:\n%s", jsonData)
+	}
+}
+
+// TestDecodeStrict_YAMLLocations 测试 YAML 文件的未知字段和类型错误定位到原文件的行列，YAML/TOML 语法错误定位到行
+func TestDecodeStrict_YAMLLocations(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "shared.yaml")
+	content := "user:\n  name: test\n  emial: test@example.com\nfeatures:\n    completion_cache: soon\n"
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var config DotfilesConfig
+	_, err := DecodeStrict(file, &config)
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) || decodeErr.Path != "user.emial" || decodeErr.Line != 3 || decodeErr.Column != 3 {
+		t.Errorf("未知字段应定位到 shared.yaml:3:3 user.emial，实际 %v", err)
+	}
+
+	content = "user:\n  name: test\nfeatures:\n    completion_cache: soon\n"
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = DecodeStrict(file, &config)
+	if !errors.As(err, &decodeErr) || decodeErr.Path != "features.completion_cache" || decodeErr.Line != 4 || decodeErr.Column != 5 {
+		t.Errorf("类型错误应定位到 shared.yaml:4:5 features.completion_cache，实际 %v", err)
+	}
+
+	if err := os.WriteFile(file, []byte("user:\n  name: test\n  email: a: b\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = DecodeStrict(file, &config)
+	if !errors.As(err, &decodeErr) || decodeErr.Line != 3 || !strings.HasPrefix(decodeErr.Message, "YAML 语法错误") {
+		t.Errorf("YAML 语法错误应定位到第 3 行，实际 %v", err)
+	}
+	if want := file + ":3: YAML 语法错误: mapping values are not allowed in this context"; err.Error() != want {
+		t.Errorf("错误信息不符: 期望 %s，实际 %s", want, err)
+	}
+
+	toml := filepath.Join(dir, "zsh_integration.toml")
+	if err := os.WriteFile(toml, []byte("[proxy]\nenabled = true\nport = \n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = DecodeStrict(toml, &ZshIntegrationConfig{})
+	if !errors.As(err, &decodeErr) || decodeErr.Line != 3 || decodeErr.Column == 0 {
+		t.Errorf("TOML 语法错误应定位到第 3 行的某一列，实际 %v", err)
+	}
+}
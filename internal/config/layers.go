@@ -0,0 +1,198 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// BuiltinLayerSource 内置默认值在来源记录中的名称
+const BuiltinLayerSource = "<内置默认值>"
+
+// 配置层名称，按优先级从低到高排列
+const (
+	LayerDefaults = "defaults" // 内置默认值
+	LayerSystem   = "system"   // /etc/dotfiles
+	LayerUser     = "user"     // $XDG_CONFIG_HOME/dotfiles
+	LayerRepo     = "repo"     // 仓库 configs/ 目录
+	LayerHost     = "host"     // configs/hosts/<主机名>/
+)
+
+// ConfigLayer 配置层，后面的层深度合并覆盖前面的层
+type ConfigLayer struct {
+	Name string
+	Dir  string // 内置默认值层为空
+}
+
+// builtinSharedDefaults 主配置的内置默认值（每次返回新的对象）
+func builtinSharedDefaults() map[string]interface{} {
+	return map[string]interface{}{
+		"version": CurrentConfigVersion,
+		"paths": map[string]interface{}{
+			"projects": "$HOME/Projects",
+			"dotfiles": "$HOME/dotfiles",
+		},
+	}
+}
+
+// Layers 返回按优先级从低到高排列的配置层；同一目录出现在多个层时只保留优先级最高的一个
+func (cl *ConfigLoader) Layers() []ConfigLayer {
+	layers := []ConfigLayer{{Name: LayerDefaults}}
+	if dir := systemConfigDir(); dir != "" {
+		layers = append(layers, ConfigLayer{Name: LayerSystem, Dir: dir})
+	}
+	if dir := userConfigDir(); dir != "" {
+		layers = append(layers, ConfigLayer{Name: LayerUser, Dir: dir})
+	}
+	layers = append(layers, ConfigLayer{Name: LayerRepo, Dir: cl.configDir})
+	if host := hostName(); host != "" {
+		layers = append(layers, ConfigLayer{Name: LayerHost, Dir: filepath.Join(cl.configDir, "hosts", host)})
+	}
+
+	// 去重: 例如配置目录本身就是 ~/.config/dotfiles 时，user 层与 repo 层相同
+	seen := make(map[string]bool)
+	unique := make([]ConfigLayer, 0, len(layers))
+	for i := len(layers) - 1; i >= 0; i-- {
+		layer := layers[i]
+		if layer.Dir != "" {
+			key := layer.Dir
+			if abs, err := filepath.Abs(layer.Dir); err == nil {
+				key = abs
+			}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+		}
+		unique = append([]ConfigLayer{layer}, unique...)
+	}
+	return unique
+}
+
+// ActiveLayers 返回目录存在的配置层（包括内置默认值层）
+func (cl *ConfigLoader) ActiveLayers() []ConfigLayer {
+	var active []ConfigLayer
+	for _, layer := range cl.Layers() {
+		if layer.Dir != "" {
+			if info, err := os.Stat(layer.Dir); err != nil || !info.IsDir() {
+				continue
+			}
+		}
+		active = append(active, layer)
+	}
+	return active
+}
+
+// layerFiles 返回各配置层中名为 base（如 shared、packages/arch）的配置文件，按优先级从低到高排列
+func (cl *ConfigLoader) layerFiles(base string) ([]string, error) {
+	var files []string
+	for _, layer := range cl.Layers() {
+		if layer.Dir == "" {
+			continue
+		}
+		path, err := FindConfigFile(layer.Dir, base)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, path)
+	}
+	return files, nil
+}
+
+// composeLayers 按配置层深度合并名为 base 的配置文件；defaults 不为 nil 时作为最底层
+//
+// 每个文件先按自身的 extends/include 合并，再覆盖到下层之上。没有任何层包含该文件时返回 os.ErrNotExist。
+func (cl *ConfigLoader) composeLayers(base string, defaults map[string]interface{}) (*ComposedConfig, error) {
+	files, err := cl.layerFiles(base)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("未在任何配置层中找到 %s: %w", base, os.ErrNotExist)
+	}
+
+	composed := newComposedConfig()
+	if defaults != nil {
+		mergeJSON(composed.Data, defaults, BuiltinLayerSource, composed.Sources, "")
+	}
+	for _, file := range files {
+		if err := composed.compose(file, nil); err != nil {
+			return nil, err
+		}
+	}
+	for _, file := range composed.Files {
+		kind, _, _ := strings.Cut(base, "/")
+		cl.checkVersion(kind, file, composed.Versions[file])
+	}
+	if len(composed.Files) > 1 {
+		cl.logger.Debugf("%s 由 %d 个文件合并: %s", base, len(composed.Files), strings.Join(composed.Files, " + "))
+	}
+	return composed, nil
+}
+
+// checkVersion 配置文件的格式版本不是当前版本时提示（每个文件只提示一次）；旧版本的文件仍按原样加载
+func (cl *ConfigLoader) checkVersion(kind, file, version string) {
+	if cl.warnedFiles[file] {
+		return
+	}
+	cmp, err := compareConfigVersion(version)
+	switch {
+	case err != nil:
+		cl.logger.Warnf("%s: %v", file, err)
+	case cmp < 0:
+		notes := MigrationNotes(kind, version)
+		if version == "" {
+			version = legacyConfigVersion + "（未声明 version）"
+		}
+		cl.logger.Warnf("%s 的配置格式版本为 %s，当前为 %s，请运行 dotfiles config migrate 升级", file, version, CurrentConfigVersion)
+		for _, note := range notes {
+			cl.logger.Warnf("  %s", note)
+		}
+	case cmp > 0:
+		cl.logger.Warnf("%s 的配置格式版本 %s 高于本程序支持的 %s，部分配置可能无法识别，请升级 dotfiles", file, version, CurrentConfigVersion)
+	default:
+		return
+	}
+	if cl.warnedFiles == nil {
+		cl.warnedFiles = make(map[string]bool)
+	}
+	cl.warnedFiles[file] = true
+}
+
+// systemConfigDir 系统级配置目录
+func systemConfigDir() string {
+	if runtime.GOOS == "windows" {
+		if programData := os.Getenv("ProgramData"); programData != "" {
+			return filepath.Join(programData, "dotfiles")
+		}
+		return ""
+	}
+	return "/etc/dotfiles"
+}
+
+// userConfigDir 用户级配置目录: $XDG_CONFIG_HOME/dotfiles，未设置时为 ~/.config/dotfiles
+func userConfigDir() string {
+	if configHome := os.Getenv("XDG_CONFIG_HOME"); configHome != "" {
+		return filepath.Join(configHome, "dotfiles")
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(home, ".config", "dotfiles")
+	}
+	return ""
+}
+
+// hostName 返回短主机名（去掉域名部分），用于 configs/hosts/<主机名>/
+func hostName() string {
+	host, err := os.Hostname()
+	if err != nil {
+		return ""
+	}
+	short, _, _ := strings.Cut(host, ".")
+	return short
+}
@@ -0,0 +1,114 @@
+package config
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// writeLayerFile 在配置层目录中写入配置文件
+func writeLayerFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestLayers 测试配置层的顺序、主机目录的选择和同一目录的去重
+func TestLayers(t *testing.T) {
+	host := hostName()
+	if host == "" {
+		t.Skip("无法获取主机名")
+	}
+	repo := t.TempDir()
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	loader := NewConfigLoader(repo, logger)
+
+	want := map[string]string{
+		LayerDefaults: "",
+		LayerUser:     filepath.Join(configHome, "dotfiles"),
+		LayerRepo:     repo,
+		LayerHost:     filepath.Join(repo, "hosts", host),
+	}
+	var names []string
+	for _, layer := range loader.Layers() {
+		names = append(names, layer.Name)
+		if dir, ok := want[layer.Name]; ok && layer.Dir != dir {
+			t.Errorf("%s 层的目录应为 %q，实际 %q", layer.Name, dir, layer.Dir)
+		}
+	}
+	if len(names) < 4 || names[0] != LayerDefaults || names[len(names)-3] != LayerUser || names[len(names)-2] != LayerRepo || names[len(names)-1] != LayerHost {
+		t.Errorf("配置层顺序应为 defaults < system < user < repo < host，实际 %v", names)
+	}
+
+	// 配置目录就是 $XDG_CONFIG_HOME/dotfiles 时只保留优先级更高的 repo 层
+	loader = NewConfigLoader(filepath.Join(configHome, "dotfiles"), logger)
+	for _, layer := range loader.Layers() {
+		if layer.Name == LayerUser {
+			t.Errorf("与 repo 层相同的 user 层应被去掉: %+v", loader.Layers())
+		}
+	}
+}
+
+// TestComposeLayers 测试各配置层按 defaults < user < repo < host 的优先级深度合并
+func TestComposeLayers(t *testing.T) {
+	host := hostName()
+	if host == "" {
+		t.Skip("无法获取主机名")
+	}
+	repo := t.TempDir()
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+
+	writeLayerFile(t, filepath.Join(configHome, "dotfiles"), "shared.json",
+		`{"user": {"name": "user", "email": "user@example.com", "editor": "vim"}, "paths": {"projects": "/user/projects"}}`)
+	writeLayerFile(t, repo, "shared.json",
+		`{"user": {"name": "repo", "email": "repo@example.com"}}`)
+	writeLayerFile(t, filepath.Join(repo, "hosts", host), "shared.yaml",
+		"user:\n  name: host\n")
+	// 其他主机的目录不参与合并
+	writeLayerFile(t, filepath.Join(repo, "hosts", host+"-other"), "shared.json",
+		`{"user": {"name": "other"}}`)
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	composed, err := NewConfigLoader(repo, logger).Compose("shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path   string
+		want   string
+		source string
+	}{
+		{"user.name", "host", filepath.Join(repo, "hosts", host, "shared.yaml")},
+		{"user.email", "repo@example.com", filepath.Join(repo, "shared.json")},
+		{"user.editor", "vim", filepath.Join(configHome, "dotfiles", "shared.json")},
+		{"paths.projects", "/user/projects", filepath.Join(configHome, "dotfiles", "shared.json")},
+		{"paths.dotfiles", "$HOME/dotfiles", BuiltinLayerSource},
+	}
+	for _, tt := range tests {
+		value, _ := LookupValue(composed.Data, strings.Split(tt.path, "."))
+		if value != tt.want {
+			t.Errorf("%s 应为 %q，实际 %v", tt.path, tt.want, value)
+		}
+		if source := composed.Source(tt.path); source != tt.source {
+			t.Errorf("%s 应来自 %s，实际 %s", tt.path, tt.source, source)
+		}
+	}
+	if len(composed.Files) != 3 {
+		t.Errorf("应合并 user、repo 和 host 层的 3 个文件，实际 %v", composed.Files)
+	}
+}
@@ -1,34 +1,44 @@
 package config
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/bbq191/dotfiles-go/internal/platform"
+	"github.com/bbq191/dotfiles-go/internal/secrets"
 	"github.com/go-playground/validator/v10"
 	"github.com/sirupsen/logrus"
 )
 
 // ConfigLoader 配置加载器
 type ConfigLoader struct {
-	configDir   string
-	platform    string
-	detector    *platform.Detector
-	validator   *validator.Validate
-	logger      *logrus.Logger
+	configDir    string
+	platform     string
+	distro       string // WSL 中运行的 Linux 发行版（目前只识别 arch），用于选择包配置
+	detector     *platform.Detector
+	validator    *validator.Validate
+	logger       *logrus.Logger
+	mainComposed *ComposedConfig       // 各配置层合并后的主配置，记录每项的来源文件
+	mainSources  map[string]*SourceMap // 主配置文件 -> 键位置，用于定位验证错误
+	expandErrors []error               // 环境变量展开错误
+	trace        *loadTrace            // Resolve 时记录加载过程对配置项的处理，其他时候为 nil
+	warnedFiles  map[string]bool       // 已提示过格式版本的文件
 }
 
 // NewConfigLoader 创建新的配置加载器
 func NewConfigLoader(configDir string, logger *logrus.Logger) *ConfigLoader {
+	currentPlatform, distro := detectCurrentPlatform()
 	return &ConfigLoader{
 		configDir: configDir,
-		platform:  detectCurrentPlatform(),
+		platform:  currentPlatform,
+		distro:    distro,
 		detector:  platform.NewDetector(),
-		validator: validator.New(),
+		validator: newJSONValidator(),
 		logger:    logger,
 	}
 }
@@ -73,133 +83,210 @@ func (cl *ConfigLoader) LoadConfig() (*DotfilesConfig, error) {
 	}
 
 	// 处理配置后处理（环境变量展开等）
-	cl.postProcessConfig(config)
+	if err := cl.postProcessConfig(config); err != nil {
+		return nil, fmt.Errorf("配置后处理失败: %w", err)
+	}
 
 	cl.logger.Info("配置加载完成")
 	return config, nil
 }
 
-// loadMainConfig 加载主配置文件
+// loadMainConfig 按配置层加载并合并主配置文件 shared.json/.yaml/.yml/.toml
+//
+// 每个文件单独严格解码（未知字段和类型错误定位到文件、路径和行列），合并结果用于验证和解码。
 func (cl *ConfigLoader) loadMainConfig() (*DotfilesConfig, error) {
-	// 直接读取 JSON 文件
-	configPath := filepath.Join(cl.configDir, "shared.json")
-	data, err := os.ReadFile(configPath)
+	composed, err := cl.composeLayers("shared", builtinSharedDefaults())
 	if err != nil {
 		return nil, fmt.Errorf("读取配置文件失败: %w", err)
 	}
 
-	var rawConfig map[string]interface{}
-	if err := json.Unmarshal(data, &rawConfig); err != nil {
-		return nil, fmt.Errorf("解析配置文件失败: %w", err)
+	cl.mainComposed = composed
+	cl.mainSources = make(map[string]*SourceMap)
+	var errs []error
+	for _, file := range composed.Files {
+		source, err := DecodeStrict(file, &DotfilesConfig{})
+		cl.mainSources[file] = source
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
 	}
 
-	cl.logger.Debugf("原始配置键: %v", getMapKeys(rawConfig))
-
-	// 手动构建配置结构
 	config := &DotfilesConfig{}
+	if err := composed.Decode(config); err != nil {
+		return nil, err
+	}
 
-	// 解析用户配置
-	if userData, ok := rawConfig["user"]; ok {
-		if userDataMap, ok := userData.(map[string]interface{}); ok {
-			config.User = UserConfig{
-				Name:    getStringFromMap(userDataMap, "name"),
-				Email:   getStringFromMap(userDataMap, "email"),
-				Editor:  getStringFromMap(userDataMap, "editor"),
-				Browser: getStringFromMap(userDataMap, "browser"),
+	cl.logger.Debugf("已加载主配置文件: %s", strings.Join(composed.Files, " + "))
+	return config, nil
+}
+
+// StrictDecode 严格解码各配置层的 zsh_integration、advanced_functions 和包配置文件（包括 extends/include 引用的文件），
+// 返回所有未知字段和类型错误（用 errors.Join 合并），没有问题时返回 nil。shared 在 LoadConfig 时已经严格解码。
+func (cl *ConfigLoader) StrictDecode() error {
+	var errs []error
+	decodeFiles := func(files []string, target func() any) {
+		for _, file := range files {
+			if _, err := DecodeStrict(file, target()); err != nil {
+				errs = append(errs, err)
 			}
-			cl.logger.Debugf("解析用户配置: Name=%s, Email=%s", config.User.Name, config.User.Email)
-		} else {
-			cl.logger.Warnf("用户数据不是 map 类型: %T", userData)
 		}
-	} else {
-		cl.logger.Warn("配置中未找到 user 字段")
 	}
 
-	// 解析路径配置
-	if pathsData, ok := rawConfig["paths"]; ok {
-		if pathsData, ok := pathsData.(map[string]interface{}); ok {
-			config.Paths = PathsConfig{
-				Projects:  cl.parsePathValue(pathsData["projects"]),
-				Dotfiles:  cl.parsePathValue(pathsData["dotfiles"]),
-				Scripts:   cl.parsePathValue(pathsData["scripts"]),
-				Templates: cl.parsePathValue(pathsData["templates"]),
-			}
+	targets := []struct {
+		base   string
+		target func() any
+	}{
+		{"zsh_integration", func() any { return &ZshIntegrationConfig{} }},
+		{"advanced_functions", func() any { return &map[string]FunctionInfo{} }},
+	}
+	for _, t := range targets {
+		composed, err := cl.composeLayers(t.base, nil)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
 		}
+		decodeFiles(composed.Files, t.target)
 	}
 
-	// 解析环境变量
-	if envData, ok := rawConfig["environment"]; ok {
-		if envData, ok := envData.(map[string]interface{}); ok {
-			config.Environment = make(map[string]string)
-			for k, v := range envData {
-				if strVal, ok := v.(string); ok {
-					config.Environment[k] = strVal
-				}
-			}
-		}
+	if _, composed, err := cl.LoadPackagesComposition(); err == nil {
+		decodeFiles(composed.Files, func() any { return &PackagesConfig{} })
 	}
+	return errors.Join(errs...)
+}
 
-	// 解析功能配置
-	if featuresData, ok := rawConfig["features"]; ok {
-		if featuresData, ok := featuresData.(map[string]interface{}); ok {
-			config.Features = FeaturesConfig{
-				GitIntegration:   getBoolFromMap(featuresData, "git_integration"),
-				NodejsManagement: getBoolFromMap(featuresData, "nodejs_management"),
-				PythonManagement: getBoolFromMap(featuresData, "python_management"),
-			}
-		}
+// loadZshConfig 加载 Zsh 集成配置
+func (cl *ConfigLoader) loadZshConfig() (*ZshIntegrationConfig, error) {
+	composed, err := cl.composeLayers("zsh_integration", nil)
+	if err != nil {
+		return nil, err
 	}
 
-	// 设置版本
-	if version, ok := rawConfig["version"]; ok {
-		if versionStr, ok := version.(string); ok {
-			config.Version = versionStr
+	var zshConfig ZshIntegrationConfig
+	if err := composed.Decode(&zshConfig); err != nil {
+		return nil, err
+	}
+	return &zshConfig, nil
+}
+
+// loadPackagesConfig 加载包配置（合并 extends/include 引用的文件）
+func (cl *ConfigLoader) loadPackagesConfig() (*PackagesConfig, error) {
+	config, _, err := cl.LoadPackagesComposition()
+	return config, err
+}
+
+// LoadPackagesComposition 加载当前平台的包配置（合并各配置层和 extends/include），同时返回每项的来源文件
+func (cl *ConfigLoader) LoadPackagesComposition() (*PackagesConfig, *ComposedConfig, error) {
+	for _, name := range cl.packagesBases() {
+		base := "packages/" + name
+		cl.logger.Debugf("尝试加载包配置: %s", base)
+		composed, err := cl.composeLayers(base, nil)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			cl.logger.Warnf("加载包配置 %s 失败: %v", base, err)
+			continue
+		}
+
+		var config PackagesConfig
+		if err := composed.Decode(&config); err != nil {
+			cl.logger.Warnf("加载包配置 %s 失败: %v", base, err)
+			continue
 		}
+		return &config, composed, nil
 	}
 
-	cl.logger.Debugf("已加载主配置文件: %s", configPath)
-	return config, nil
+	return nil, nil, fmt.Errorf("未找到适合的包配置文件")
 }
 
-// loadZshConfig 加载 Zsh 集成配置
-func (cl *ConfigLoader) loadZshConfig() (*ZshIntegrationConfig, error) {
-	configPath := filepath.Join(cl.configDir, "zsh_integration.json")
-	return loadJSONConfig[ZshIntegrationConfig](configPath)
+// packagesBases 依次尝试的包配置文件名：当前平台，WSL 中的发行版，然后是备选的 linux 和 arch
+//
+// WSL 中的 Arch 使用 arch.json（其中 when: {wsl: true} 的包只在 WSL 中安装），而不是回退到 linux.json。
+func (cl *ConfigLoader) packagesBases() []string {
+	bases := []string{cl.platform}
+	for _, name := range []string{cl.distro, "linux", "arch"} {
+		if name != "" && !slices.Contains(bases, name) {
+			bases = append(bases, name)
+		}
+	}
+	return bases
 }
 
-// loadPackagesConfig 加载包配置
-func (cl *ConfigLoader) loadPackagesConfig() (*PackagesConfig, error) {
-	// 尝试加载平台特定的包配置
-	platformFiles := []string{
-		fmt.Sprintf("packages/%s.json", cl.platform),
-		"packages/linux.json", // 备选
-		"packages/arch.json",  // 备选
+// Compose 按配置层合并指定种类（shared、zsh_integration、packages、advanced_functions）的配置文件，
+// shared 包含内置默认值，packages 为当前平台使用的包配置
+func (cl *ConfigLoader) Compose(kind string) (*ComposedConfig, error) {
+	switch kind {
+	case "shared":
+		return cl.composeLayers(kind, builtinSharedDefaults())
+	case "packages":
+		_, composed, err := cl.LoadPackagesComposition()
+		return composed, err
+	}
+	if _, err := findConfigSchema(kind); err != nil {
+		return nil, err
 	}
+	return cl.composeLayers(kind, nil)
+}
 
-	for _, filename := range platformFiles {
-		configPath := filepath.Join(cl.configDir, filename)
-		if _, err := os.Stat(configPath); err == nil {
-			cl.logger.Debugf("尝试加载包配置: %s", configPath)
-			if config, err := loadJSONConfig[PackagesConfig](configPath); err == nil {
-				return config, nil
+// LayerFile 返回配置层中指定种类的配置文件路径；文件不存在时为该层目录下的 .json 路径
+//
+// packages 使用当前平台实际加载的包配置文件名（见 LoadPackagesComposition）。
+func (cl *ConfigLoader) LayerFile(layerName, kind string) (string, error) {
+	index := slices.IndexFunc(cl.Layers(), func(layer ConfigLayer) bool { return layer.Name == layerName })
+	if index < 0 || cl.Layers()[index].Dir == "" {
+		return "", fmt.Errorf("配置层 %s 不可用（可用: %s、%s、%s、%s）", layerName, LayerSystem, LayerUser, LayerRepo, LayerHost)
+	}
+	dir := cl.Layers()[index].Dir
+
+	base := kind
+	if kind == "packages" {
+		base = "packages/" + cl.platform
+		for _, name := range cl.packagesBases() {
+			if files, err := cl.layerFiles("packages/" + name); err == nil && len(files) > 0 {
+				base = "packages/" + name
+				break
 			}
 		}
+	} else if _, err := findConfigSchema(kind); err != nil {
+		return "", err
 	}
 
-	return nil, fmt.Errorf("未找到适合的包配置文件")
+	path, err := FindConfigFile(dir, base)
+	if errors.Is(err, os.ErrNotExist) {
+		return filepath.Join(dir, base+".json"), nil
+	}
+	return path, err
+}
+
+// PlatformPackagesPath 返回当前平台的包配置文件路径（已存在时保留其格式，否则为 .json，文件可能尚不存在）
+func (cl *ConfigLoader) PlatformPackagesPath() string {
+	packagesDir := filepath.Join(cl.configDir, "packages")
+	if path, err := FindConfigFile(packagesDir, cl.platform); err == nil {
+		return path
+	}
+	return filepath.Join(packagesDir, cl.platform+".json")
 }
 
 // loadFunctionsConfig 加载函数配置
 func (cl *ConfigLoader) loadFunctionsConfig() (*FunctionsConfig, error) {
-	configPath := filepath.Join(cl.configDir, "advanced_functions.json")
-
-	data, err := os.ReadFile(configPath)
+	composed, err := cl.composeLayers("advanced_functions", nil)
 	if err != nil {
 		return nil, err
 	}
 
+	// 顶层的 version 是格式版本而不是函数
+	if _, ok := composed.Data[VersionKey].(string); ok {
+		delete(composed.Data, VersionKey)
+	}
+
 	var functions map[string]FunctionInfo
-	if err := json.Unmarshal(data, &functions); err != nil {
+	if err := composed.Decode(&functions); err != nil {
 		return nil, fmt.Errorf("解析函数配置文件失败: %w", err)
 	}
 
@@ -208,21 +295,6 @@ func (cl *ConfigLoader) loadFunctionsConfig() (*FunctionsConfig, error) {
 	}, nil
 }
 
-// loadJSONConfig 通用 JSON 配置加载器
-func loadJSONConfig[T any](configPath string) (*T, error) {
-	data, err := os.ReadFile(configPath)
-	if err != nil {
-		return nil, err
-	}
-
-	var config T
-	if err := json.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("解析配置文件 %s 失败: %w", configPath, err)
-	}
-
-	return &config, nil
-}
-
 // validateConfig 验证配置
 func (cl *ConfigLoader) validateConfig(config *DotfilesConfig) error {
 	cl.logger.Debug("开始验证配置")
@@ -246,23 +318,35 @@ func (cl *ConfigLoader) formatValidationError(err error) error {
 	var messages []string
 
 	for _, fieldErr := range validationErrors {
+		var message string
 		switch fieldErr.Tag() {
 		case "required":
-			messages = append(messages, fmt.Sprintf("字段 %s 是必需的", fieldErr.Field()))
+			message = "字段是必需的"
 		case "email":
-			messages = append(messages, fmt.Sprintf("字段 %s 必须是有效的邮箱地址", fieldErr.Field()))
+			message = "必须是有效的邮箱地址"
 		case "min":
-			messages = append(messages, fmt.Sprintf("字段 %s 长度不能少于 %s", fieldErr.Field(), fieldErr.Param()))
+			message = fmt.Sprintf("长度不能少于 %s", fieldErr.Param())
 		case "semver":
-			messages = append(messages, fmt.Sprintf("字段 %s 必须符合语义版本格式", fieldErr.Field()))
+			message = "必须符合语义版本格式"
 		default:
-			messages = append(messages, fmt.Sprintf("字段 %s 验证失败: %s", fieldErr.Field(), fieldErr.Tag()))
+			message = fmt.Sprintf("验证失败: %s", fieldErr.Tag())
 		}
+		messages = append(messages, cl.sourceError(validationPath(fieldErr), message))
 	}
 
 	return fmt.Errorf("配置验证失败:\n  - %s", strings.Join(messages, "\n  - "))
 }
 
+// sourceError 将验证错误定位到提供该路径的配置层文件
+func (cl *ConfigLoader) sourceError(path, message string) string {
+	if cl.mainComposed != nil {
+		if source := cl.mainSources[cl.mainComposed.Source(path)]; source != nil {
+			return source.Error(path, message).Error()
+		}
+	}
+	return fmt.Sprintf("%s: %s", path, message)
+}
+
 // customValidation 自定义验证逻辑
 func (cl *ConfigLoader) customValidation(config *DotfilesConfig) error {
 	// 验证用户邮箱格式
@@ -294,30 +378,121 @@ func (cl *ConfigLoader) validatePaths(paths PathsConfig) error {
 	return nil
 }
 
-// postProcessConfig 配置后处理
-func (cl *ConfigLoader) postProcessConfig(config *DotfilesConfig) {
+// postProcessConfig 配置后处理，返回 ${VAR:?信息} 等展开错误
+func (cl *ConfigLoader) postProcessConfig(config *DotfilesConfig) error {
 	cl.logger.Debug("开始配置后处理")
 
 	// 展开环境变量
+	cl.expandErrors = nil
 	cl.expandEnvironmentVariables(config)
+	if len(cl.expandErrors) > 0 {
+		return errors.Join(cl.expandErrors...)
+	}
+
+	// 替换密钥引用（在展开环境变量之后，密钥值中的 $ 不会被展开）
+	// 密钥不分层: 只使用仓库配置目录（repo 层）中的 secrets.age，与 dotfiles secret 命令读写的文件相同，
+	// 其他层（包括 hosts/<主机名>/）中的 {{ secret }} 引用也从这里解密
+	config.Secrets = secrets.NewStore(cl.configDir, cl.logger)
+	if err := cl.resolveSecrets(config); err != nil {
+		return err
+	}
 
 	// 设置默认值
 	cl.setDefaultValues(config)
 
 	cl.logger.Debug("配置后处理完成")
+	return nil
+}
+
+// resolveSecrets 替换 environment 和代理配置中的 {{ secret "名称" }} 引用，没有引用时不解密密钥文件
+//
+// environment 中引用的密钥未设置时去掉该变量（没有密钥文件时只在调试日志中说明），其他错误（如解密失败）返回错误。
+func (cl *ConfigLoader) resolveSecrets(config *DotfilesConfig) error {
+	var errs []error
+	for _, field := range secretFields(config) {
+		if !secrets.HasReferences(field.value) {
+			continue
+		}
+		resolved, err := config.Secrets.Resolve(field.value)
+		switch {
+		case field.unset != nil && errors.Is(err, secrets.ErrNoStore):
+			// 还没有密钥文件时不导出该变量，由使用它的脚本（如 dev-services 的 valkey_auth）提示
+			cl.logger.Debugf("环境变量 %s 引用的密钥未设置，不导出: %v", strings.TrimPrefix(field.path, "environment."), err)
+			field.unset()
+		case field.unset != nil && errors.Is(err, secrets.ErrNotFound):
+			cl.logger.Warnf("环境变量 %s 引用的密钥未设置，不导出: %v", strings.TrimPrefix(field.path, "environment."), err)
+			field.unset()
+		case err != nil:
+			errs = append(errs, err)
+		default:
+			field.set(resolved)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("解析密钥引用失败: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// secretField 可以包含密钥引用的配置项
+type secretField struct {
+	kind  string // 配置种类
+	path  string // JSON 路径
+	value string
+	set   func(value string)
+	unset func() // 去掉该项，只有 environment 中的项可以去掉
+}
+
+// secretFields 返回可以包含密钥引用的配置项：environment 和代理配置的地址
+func secretFields(config *DotfilesConfig) []secretField {
+	var fields []secretField
+	for key, value := range config.Environment {
+		fields = append(fields, secretField{
+			kind:  "shared",
+			path:  "environment." + key,
+			value: value,
+			set:   func(value string) { config.Environment[key] = value },
+			unset: func() { delete(config.Environment, key) },
+		})
+	}
+	if config.ZshConfig == nil {
+		return fields
+	}
+
+	profiles := config.ZshConfig.Proxy.Profiles
+	for name, profile := range profiles {
+		// 各地址指向同一个副本，设置后整体写回
+		for key, value := range map[string]*string{
+			"https_proxy": &profile.HTTPSProxy,
+			"http_proxy":  &profile.HTTPProxy,
+			"all_proxy":   &profile.AllProxy,
+			"no_proxy":    &profile.NoProxy,
+		} {
+			fields = append(fields, secretField{
+				kind:  "zsh_integration",
+				path:  "proxy.profiles." + name + "." + key,
+				value: *value,
+				set: func(resolved string) {
+					*value = resolved
+					profiles[name] = profile
+				},
+			})
+		}
+	}
+	return fields
 }
 
 // expandEnvironmentVariables 展开环境变量
 func (cl *ConfigLoader) expandEnvironmentVariables(config *DotfilesConfig) {
 	// 展开路径中的环境变量
-	config.Paths.Projects = cl.expandPathValue(config.Paths.Projects)
-	config.Paths.Dotfiles = cl.expandPathValue(config.Paths.Dotfiles)
-	config.Paths.Scripts = cl.expandPathValue(config.Paths.Scripts)
-	config.Paths.Templates = cl.expandPathValue(config.Paths.Templates)
+	config.Paths.Projects = cl.expandPathValue("shared", "paths.projects", config.Paths.Projects)
+	config.Paths.Dotfiles = cl.expandPathValue("shared", "paths.dotfiles", config.Paths.Dotfiles)
+	config.Paths.Scripts = cl.expandPathValue("shared", "paths.scripts", config.Paths.Scripts)
+	config.Paths.Templates = cl.expandPathValue("shared", "paths.templates", config.Paths.Templates)
 
 	// 展开环境变量配置
 	for key, value := range config.Environment {
-		config.Environment[key] = cl.expandEnvVars(value)
+		config.Environment[key] = cl.expandEnvVars("shared", "environment."+key, value)
 	}
 
 	// 展开 Zsh 配置中的环境变量
@@ -326,16 +501,16 @@ func (cl *ConfigLoader) expandEnvironmentVariables(config *DotfilesConfig) {
 	}
 }
 
-// expandPathValue 展开路径值中的环境变量
-func (cl *ConfigLoader) expandPathValue(pv PathValue) PathValue {
+// expandPathValue 展开路径值中的环境变量；kind 和 path 为配置种类和路径值的 JSON 路径
+func (cl *ConfigLoader) expandPathValue(kind, path string, pv PathValue) PathValue {
 	if pv.Default != "" {
-		pv.Default = cl.expandEnvVars(pv.Default)
+		pv.Default = cl.expandEnvVars(kind, path, pv.Default)
 	}
 
 	if pv.Platform != nil {
 		expanded := make(map[string]string)
-		for platform, path := range pv.Platform {
-			expanded[platform] = cl.expandEnvVars(path)
+		for platform, value := range pv.Platform {
+			expanded[platform] = cl.expandEnvVars(kind, joinPath(path, platform), value)
 		}
 		pv.Platform = expanded
 	}
@@ -343,32 +518,55 @@ func (cl *ConfigLoader) expandPathValue(pv PathValue) PathValue {
 	return pv
 }
 
-// expandEnvVars 展开环境变量，支持多种格式
-func (cl *ConfigLoader) expandEnvVars(s string) string {
+// expandEnvVars 展开环境变量，错误在 postProcessConfig 中统一返回；Resolve 时记录读取过的变量和结果
+func (cl *ConfigLoader) expandEnvVars(kind, path, s string) string {
+	var env []string
+	lookup := func(name string) (string, bool) {
+		if !slices.Contains(env, name) {
+			env = append(env, name)
+		}
+		return os.LookupEnv(name)
+	}
+	expanded, err := expandConfigString(s, lookup)
+	if err != nil {
+		cl.expandErrors = append(cl.expandErrors, err)
+		expanded = s
+	}
+	cl.trace.recordExpansion(kind, path, expanded, env, err)
+	return expanded
+}
+
+// psEnvRegex PowerShell 格式的环境变量 $env:VARNAME
+var psEnvRegex = regexp.MustCompile(`\$env:([A-Za-z_][A-Za-z0-9_]*)`)
+
+// expandConfigString 展开配置值中的环境变量，支持多种格式
+func expandConfigString(s string, lookup func(string) (string, bool)) (string, error) {
 	// 先处理 PowerShell 格式的环境变量 $env:VARNAME
-	psEnvRegex := regexp.MustCompile(`\$env:([A-Za-z_][A-Za-z0-9_]*)`)
 	s = psEnvRegex.ReplaceAllStringFunc(s, func(match string) string {
 		varName := psEnvRegex.FindStringSubmatch(match)[1]
-		if value := os.Getenv(varName); value != "" {
+		if value, _ := lookup(varName); value != "" {
 			return value
 		}
 		return match // 如果环境变量不存在，保持原样
 	})
 
-	// 然后使用标准的 os.ExpandEnv 处理其他格式
-	return os.ExpandEnv(s)
+	// 然后按 POSIX 参数展开处理 $VAR、${VAR:-默认值} 等格式
+	return Expand(s, lookup)
 }
 
 // expandZshConfigVariables 展开 Zsh 配置中的环境变量
 func (cl *ConfigLoader) expandZshConfigVariables(zshConfig *ZshIntegrationConfig) {
+	// 展开代理配置名称（如 ${PROXY_PROFILE:-default}）；代理地址在生成模板时展开
+	zshConfig.Proxy.ActiveProfile = cl.expandEnvVars("zsh_integration", "proxy.active_profile", zshConfig.Proxy.ActiveProfile)
+
 	// 展开 XDG 目录配置
 	if zshConfig.XDGDirectories.Enabled {
-		zshConfig.XDGDirectories.ConfigHome = cl.expandPathValue(zshConfig.XDGDirectories.ConfigHome)
-		zshConfig.XDGDirectories.DataHome = cl.expandPathValue(zshConfig.XDGDirectories.DataHome)
-		zshConfig.XDGDirectories.StateHome = cl.expandPathValue(zshConfig.XDGDirectories.StateHome)
-		zshConfig.XDGDirectories.CacheHome = cl.expandPathValue(zshConfig.XDGDirectories.CacheHome)
-		zshConfig.XDGDirectories.RuntimeDir = cl.expandPathValue(zshConfig.XDGDirectories.RuntimeDir)
-		zshConfig.XDGDirectories.UserBin = cl.expandPathValue(zshConfig.XDGDirectories.UserBin)
+		zshConfig.XDGDirectories.ConfigHome = cl.expandPathValue("zsh_integration", "xdg_directories.config_home", zshConfig.XDGDirectories.ConfigHome)
+		zshConfig.XDGDirectories.DataHome = cl.expandPathValue("zsh_integration", "xdg_directories.data_home", zshConfig.XDGDirectories.DataHome)
+		zshConfig.XDGDirectories.StateHome = cl.expandPathValue("zsh_integration", "xdg_directories.state_home", zshConfig.XDGDirectories.StateHome)
+		zshConfig.XDGDirectories.CacheHome = cl.expandPathValue("zsh_integration", "xdg_directories.cache_home", zshConfig.XDGDirectories.CacheHome)
+		zshConfig.XDGDirectories.RuntimeDir = cl.expandPathValue("zsh_integration", "xdg_directories.runtime_dir", zshConfig.XDGDirectories.RuntimeDir)
+		zshConfig.XDGDirectories.UserBin = cl.expandPathValue("zsh_integration", "xdg_directories.user_bin", zshConfig.XDGDirectories.UserBin)
 	}
 
 	// 展开版本管理器配置
@@ -377,7 +575,7 @@ func (cl *ConfigLoader) expandZshConfigVariables(zshConfig *ZshIntegrationConfig
 			expanded := make(map[string]interface{})
 			for key, pathValue := range vm.EnvVars {
 				if pathVal, ok := pathValue.(PathValue); ok {
-					expanded[key] = cl.expandPathValue(pathVal)
+					expanded[key] = cl.expandPathValue("zsh_integration", "version_managers."+name+".env_vars."+key, pathVal)
 				} else {
 					expanded[key] = pathValue
 				}
@@ -391,7 +589,7 @@ func (cl *ConfigLoader) expandZshConfigVariables(zshConfig *ZshIntegrationConfig
 	for envName, envConfig := range zshConfig.DevelopmentEnvironments {
 		expanded := make(map[string]PathValue)
 		for key, pathValue := range envConfig {
-			expanded[key] = cl.expandPathValue(pathValue)
+			expanded[key] = cl.expandPathValue("zsh_integration", "development_environments."+envName+"."+key, pathValue)
 		}
 		zshConfig.DevelopmentEnvironments[envName] = expanded
 	}
@@ -401,15 +599,18 @@ func (cl *ConfigLoader) expandZshConfigVariables(zshConfig *ZshIntegrationConfig
 func (cl *ConfigLoader) setDefaultValues(config *DotfilesConfig) {
 	// 设置版本默认值
 	if config.Version == "" {
-		config.Version = "1.0.0"
+		config.Version = CurrentConfigVersion
+		cl.trace.recordDefault("shared", VersionKey, config.Version, nil)
 	}
 
 	// 设置默认编辑器
 	if config.User.Editor == "" {
 		if editor := os.Getenv("EDITOR"); editor != "" {
 			config.User.Editor = editor
+			cl.trace.recordDefault("shared", "user.editor", editor, []string{"EDITOR"})
 		} else {
 			config.User.Editor = "nano"
+			cl.trace.recordDefault("shared", "user.editor", "nano", nil)
 		}
 	}
 
@@ -419,23 +620,26 @@ func (cl *ConfigLoader) setDefaultValues(config *DotfilesConfig) {
 	}
 }
 
-// detectCurrentPlatform 检测当前平台
-func detectCurrentPlatform() string {
+// detectCurrentPlatform 检测当前平台，WSL 中同时返回发行版
+func detectCurrentPlatform() (string, string) {
 	detector := platform.NewDetector()
 	info, err := detector.DetectPlatform()
 	if err != nil {
-		return "linux" // 默认值
+		return "linux", "" // 默认值
 	}
 
 	if info.IsWSLEnvironment() {
-		return "wsl"
+		if info.Linux != nil && info.Linux.IsArch() {
+			return "wsl", "arch"
+		}
+		return "wsl", ""
 	}
 
 	if info.Linux != nil && info.Linux.IsArch() {
-		return "arch"
+		return "arch", ""
 	}
 
-	return info.OS
+	return info.OS, ""
 }
 
 // GetConfigDir 获取配置目录路径
@@ -460,60 +664,3 @@ func GetConfigDir() string {
 
 	return "configs" // 默认值
 }
-
-
-
-// 辅助解析函数
-func getStringFromMap(data map[string]interface{}, key string) string {
-	if val, ok := data[key]; ok {
-		if strVal, ok := val.(string); ok {
-			return strVal
-		}
-	}
-	return ""
-}
-
-func getBoolFromMap(data map[string]interface{}, key string) bool {
-	if val, ok := data[key]; ok {
-		if boolVal, ok := val.(bool); ok {
-			return boolVal
-		}
-	}
-	return false
-}
-
-func (cl *ConfigLoader) parsePathValue(data interface{}) PathValue {
-	if data == nil {
-		return PathValue{}
-	}
-
-	if strVal, ok := data.(string); ok {
-		return PathValue{
-			Default:  strVal,
-			Platform: nil,
-		}
-	}
-
-	if mapData, ok := data.(map[string]interface{}); ok {
-		platformMap := make(map[string]string)
-		for k, v := range mapData {
-			if strVal, ok := v.(string); ok {
-				platformMap[k] = strVal
-			}
-		}
-		return PathValue{
-			Default:  "",
-			Platform: platformMap,
-		}
-	}
-
-	return PathValue{}
-}
-
-func getMapKeys(data map[string]interface{}) []string {
-	keys := make([]string, 0, len(data))
-	for k := range data {
-		keys = append(keys, k)
-	}
-	return keys
-}
\ No newline at end of file
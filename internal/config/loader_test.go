@@ -0,0 +1,131 @@
+package config
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TestLoadPackagesComposition_WSLArch 测试 WSL 中的 Arch 先使用 arch.json，而不是回退到 linux.json
+func TestLoadPackagesComposition_WSLArch(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	files := map[string]string{
+		"linux.json": `{"categories": {"base": {"description": "Linux", "packages": {"git": {}}}}, "package_managers": {}}`,
+		"arch.json":  `{"categories": {"base": {"description": "Arch", "packages": {"wslu": {"when": {"wsl": true}}}}}, "package_managers": {}}`,
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "packages"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, "packages", name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	loader := NewConfigLoader(dir, logger)
+	loader.platform, loader.distro = "wsl", "arch"
+
+	if bases := loader.packagesBases(); !slices.Equal(bases, []string{"wsl", "arch", "linux"}) {
+		t.Errorf("包配置的尝试顺序不符: %v", bases)
+	}
+
+	packages, composed, err := loader.LoadPackagesComposition()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := composed.Files[len(composed.Files)-1]; filepath.Base(got) != "arch.json" {
+		t.Errorf("WSL Arch 应加载 arch.json，实际 %s", got)
+	}
+	if _, ok := packages.Categories["base"].Packages["wslu"]; !ok {
+		t.Errorf("应包含 arch.json 中的 wslu")
+	}
+
+	// 其他 WSL 发行版仍回退到 linux.json
+	loader.distro = ""
+	if _, composed, err = loader.LoadPackagesComposition(); err != nil {
+		t.Fatal(err)
+	}
+	if got := composed.Files[len(composed.Files)-1]; filepath.Base(got) != "linux.json" {
+		t.Errorf("未识别发行版的 WSL 应加载 linux.json，实际 %s", got)
+	}
+}
+
+// TestLoadConfig_YAML 测试加载 YAML 格式的主配置：schema 注释被忽略，路径值和环境变量展开与 JSON 相同
+func TestLoadConfig_YAML(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("LOADER_TEST_DIR", "/srv")
+
+	shared := `# yaml-language-server: $schema=../schemas/shared.schema.json
+version: 1.1.0
+user:
+  name: test
+  email: test@example.com
+  editor: nvim
+paths:
+  projects:
+    default: $LOADER_TEST_DIR/projects
+    plan9: /n/projects
+features:
+  completion_cache: true
+`
+	if err := os.WriteFile(filepath.Join(dir, "shared.yaml"), []byte(shared), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	config, err := NewConfigLoader(dir, logger).LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.User.Name != "test" || config.User.Editor != "nvim" || !config.Features.CompletionCache {
+		t.Errorf("YAML 中的值未正确加载: %+v %+v", config.User, config.Features)
+	}
+	if config.Paths.Projects.Platform["default"] != "/srv/projects" || config.Paths.Projects.Platform["plan9"] != "/n/projects" {
+		t.Errorf("路径值应展开环境变量: %+v", config.Paths.Projects)
+	}
+	if config.Paths.Dotfiles.Default == "" {
+		t.Errorf("未设置的路径应使用内置默认值")
+	}
+}
+
+// TestLoadConfig_UnsetSecret 测试 environment 引用的密钥未设置时只去掉该变量，不影响加载
+func TestLoadConfig_UnsetSecret(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("DOTFILES_AGE_IDENTITY", filepath.Join(t.TempDir(), "keys.txt"))
+
+	shared := `{
+  "user": {"name": "test", "email": "test@example.com"},
+  "environment": {
+    "LANG": "zh_CN.UTF-8",
+    "VALKEY_PASSWORD": "{{ secret \"valkey.password\" }}"
+  }
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "shared.json"), []byte(shared), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	config, err := NewConfigLoader(dir, logger).LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := config.Environment["VALKEY_PASSWORD"]; ok {
+		t.Errorf("未设置的密钥不应导出: %v", config.Environment)
+	}
+	if config.Environment["LANG"] != "zh_CN.UTF-8" {
+		t.Errorf("其他环境变量应保留: %v", config.Environment)
+	}
+}
@@ -0,0 +1,235 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// VersionKey 配置文件顶层声明格式版本的键
+const VersionKey = "version"
+
+// CurrentConfigVersion 当前的配置格式版本
+//
+// 修改配置结构（重命名、移动或删除字段）时提高版本号，并在 configMigrations 末尾追加对应的迁移。
+const CurrentConfigVersion = "1.1.0"
+
+// legacyConfigVersion 没有 version 键的配置文件按最初的格式处理
+const legacyConfigVersion = "1.0.0"
+
+// configMigration 将配置格式升级到 version 的迁移
+//
+// apply 按配置种类（schema 名称）修改文件，返回每项修改的说明；没有对应函数的种类只更新版本号。
+type configMigration struct {
+	version     string
+	description string
+	apply       map[string]func(doc *ConfigDocument) ([]string, error)
+}
+
+// configMigrations 按版本从低到高排列
+var configMigrations = []configMigration{
+	{
+		version:     "1.1.0",
+		description: "shared 的 features 不再包含 completion_cache、async_loading 和 path_deduplication（从未生效，由 zsh_integration 的 performance 控制）",
+		apply: map[string]func(*ConfigDocument) ([]string, error){
+			"shared": removeSharedPerformanceFeatures,
+		},
+	},
+}
+
+// removeSharedPerformanceFeatures 删除 shared.features 中与 zsh_integration.performance 重复的开关
+func removeSharedPerformanceFeatures(doc *ConfigDocument) ([]string, error) {
+	var changes []string
+	for _, key := range []string{"completion_cache", "async_loading", "path_deduplication"} {
+		path := []string{"features", key}
+		value, _ := doc.Get(path)
+		deleted, err := doc.Delete(path)
+		if err != nil {
+			return nil, err
+		}
+		if deleted {
+			changes = append(changes, fmt.Sprintf("删除 features.%s（原值 %v，如需要请在 zsh_integration 的 performance.%s 中设置）", key, value, key))
+		}
+	}
+	return changes, nil
+}
+
+// ConfigFile 配置文件及其种类（schema 名称: shared、zsh_integration、packages、advanced_functions）
+type ConfigFile struct {
+	Path string
+	Kind string
+}
+
+// ConfigFiles 返回 dir 中属于 kinds 的配置文件，packages 对应 packages/ 下的所有文件
+func ConfigFiles(dir string, kinds []string) ([]ConfigFile, error) {
+	var files []ConfigFile
+	for _, kind := range kinds {
+		if kind != "packages" {
+			path, err := FindConfigFile(dir, kind)
+			if err != nil {
+				if errors.Is(err, os.ErrNotExist) {
+					continue
+				}
+				return nil, err
+			}
+			files = append(files, ConfigFile{path, kind})
+			continue
+		}
+
+		entries, err := os.ReadDir(filepath.Join(dir, "packages"))
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if _, err := FormatFromPath(entry.Name()); entry.IsDir() || err != nil {
+				continue
+			}
+			files = append(files, ConfigFile{filepath.Join(dir, "packages", entry.Name()), kind})
+		}
+	}
+	return files, nil
+}
+
+// ConfigKindFromPath 根据文件名判断配置种类，packages/ 目录下的文件都是包配置
+func ConfigKindFromPath(path string) (string, error) {
+	if _, err := FormatFromPath(path); err != nil {
+		return "", err
+	}
+	base := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	for _, kind := range SchemaNames() {
+		if kind != "packages" && kind == base {
+			return kind, nil
+		}
+	}
+	if filepath.Base(filepath.Dir(path)) == "packages" {
+		return "packages", nil
+	}
+	return "", fmt.Errorf("无法判断 %s 的配置种类（应为 shared、zsh_integration、advanced_functions 或 packages/ 下的文件）", path)
+}
+
+// compareConfigVersion 比较配置文件声明的版本与当前版本，空版本按 1.0.0 处理
+func compareConfigVersion(version string) (int, error) {
+	if version == "" {
+		version = legacyConfigVersion
+	}
+	v, err := semver.StrictNewVersion(version)
+	if err != nil {
+		return 0, fmt.Errorf("无效的配置格式版本 %q", version)
+	}
+	return v.Compare(semver.MustParse(CurrentConfigVersion)), nil
+}
+
+// Migration 一个配置文件的迁移结果
+type Migration struct {
+	File    ConfigFile
+	From    string   // 原版本（没有 version 键时为 1.0.0）
+	Changes []string // 每项修改的说明
+	Before  []byte
+	After   []byte
+}
+
+// Changed 迁移是否修改了文件
+func (m *Migration) Changed() bool {
+	return !bytes.Equal(m.Before, m.After)
+}
+
+// MigrateConfigFile 计算配置文件升级到当前格式版本后的内容（不写入文件）
+func MigrateConfigFile(file ConfigFile) (*Migration, error) {
+	doc, err := LoadConfigDocument(file.Path)
+	if err != nil {
+		return nil, err
+	}
+	migration := &Migration{File: file, From: legacyConfigVersion, Before: doc.data, After: doc.data}
+
+	value, exists := doc.Get([]string{VersionKey})
+	if exists {
+		version, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s: version 必须是字符串", file.Path)
+		}
+		migration.From = version
+	}
+	cmp, err := compareConfigVersion(migration.From)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file.Path, err)
+	}
+	if cmp > 0 {
+		return nil, fmt.Errorf("%s 的配置格式版本 %s 高于本程序支持的 %s，请升级 dotfiles", file.Path, migration.From, CurrentConfigVersion)
+	}
+	if cmp == 0 {
+		return migration, nil
+	}
+
+	from := semver.MustParse(migration.From)
+	for _, m := range configMigrations {
+		if !semver.MustParse(m.version).GreaterThan(from) {
+			continue
+		}
+		apply, ok := m.apply[file.Kind]
+		if !ok {
+			continue
+		}
+		changes, err := apply(doc)
+		if err != nil {
+			return nil, fmt.Errorf("%s: 迁移到 %s 失败: %w", file.Path, m.version, err)
+		}
+		for _, change := range changes {
+			migration.Changes = append(migration.Changes, m.version+": "+change)
+		}
+	}
+
+	// version 放在最前面（$schema 引用之后）
+	index := 0
+	if len(doc.root.keys) > 0 && doc.root.keys[0] == SchemaKey {
+		index = 1
+	}
+	if err := doc.setAt([]string{VersionKey}, CurrentConfigVersion, index); err != nil {
+		return nil, err
+	}
+	migration.Changes = append(migration.Changes, fmt.Sprintf("version: %s → %s", migration.From, CurrentConfigVersion))
+
+	if migration.After, err = doc.Bytes(); err != nil {
+		return nil, err
+	}
+	return migration, nil
+}
+
+// Apply 备份原文件为 <文件>.<时间>.bak 后写入迁移结果，返回备份文件路径
+func (m *Migration) Apply() (string, error) {
+	info, err := os.Stat(m.File.Path)
+	if err != nil {
+		return "", err
+	}
+	backup := fmt.Sprintf("%s.%s.bak", m.File.Path, time.Now().Format("20060102-150405"))
+	if err := os.WriteFile(backup, m.Before, info.Mode().Perm()); err != nil {
+		return "", fmt.Errorf("备份 %s 失败: %w", m.File.Path, err)
+	}
+	if err := os.WriteFile(m.File.Path, m.After, info.Mode().Perm()); err != nil {
+		return backup, fmt.Errorf("写入 %s 失败: %w", m.File.Path, err)
+	}
+	return backup, nil
+}
+
+// MigrationNotes 返回高于 from 的各版本中涉及 kind 种类配置文件的格式变化说明
+func MigrationNotes(kind, from string) []string {
+	if from == "" {
+		from = legacyConfigVersion
+	}
+	version, err := semver.StrictNewVersion(from)
+	if err != nil {
+		return nil
+	}
+	var notes []string
+	for _, m := range configMigrations {
+		if _, ok := m.apply[kind]; ok && semver.MustParse(m.version).GreaterThan(version) {
+			notes = append(notes, m.version+": "+m.description)
+		}
+	}
+	return notes
+}
@@ -0,0 +1,136 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestMigrateConfigFile 测试迁移只修改涉及的键，并保留 JSON 的格式和 YAML 的注释
+func TestMigrateConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	shared := `{
+  "$schema": "./schemas/shared.schema.json",
+  "user": {"name": "test", "email": "test@example.com"},
+
+  "features": {
+    "git_integration": true,
+    "completion_cache": true,
+    "async_loading": false
+  }
+}
+`
+	sharedPath := filepath.Join(dir, "shared.json")
+	if err := os.WriteFile(sharedPath, []byte(shared), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	migration, err := MigrateConfigFile(ConfigFile{Path: sharedPath, Kind: "shared"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+  "$schema": "./schemas/shared.schema.json",
+  "version": "` + CurrentConfigVersion + `",
+  "user": {"name": "test", "email": "test@example.com"},
+
+  "features": {
+    "git_integration": true
+  }
+}
+`
+	if string(migration.After) != want {
+		t.Errorf("迁移结果不符:\n%s\n期望:\n%s", migration.After, want)
+	}
+	if migration.From != legacyConfigVersion || len(migration.Changes) != 3 {
+		t.Errorf("期望从 %s 迁移并有 3 项修改，实际 %s %v", legacyConfigVersion, migration.From, migration.Changes)
+	}
+
+	backup, err := migration.Apply()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(backup); string(data) != shared {
+		t.Errorf("备份内容不是原文件")
+	}
+	again, err := MigrateConfigFile(ConfigFile{Path: sharedPath, Kind: "shared"})
+	if err != nil || again.Changed() {
+		t.Errorf("已迁移的文件不应再修改: %v", err)
+	}
+
+	yamlPath := filepath.Join(dir, "zsh_integration.yaml")
+	yamlData := "# yaml-language-server: $schema=./schemas/zsh_integration.schema.json\nproxy:\n  enabled: true # 默认启用\n"
+	if err := os.WriteFile(yamlPath, []byte(yamlData), 0644); err != nil {
+		t.Fatal(err)
+	}
+	migration, err = MigrateConfigFile(ConfigFile{Path: yamlPath, Kind: "zsh_integration"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = "# yaml-language-server: $schema=./schemas/zsh_integration.schema.json\nversion: " + CurrentConfigVersion + "\nproxy:\n  enabled: true # 默认启用\n"
+	if string(migration.After) != want {
+		t.Errorf("YAML 迁移结果不符:\n%s\n期望:\n%s", migration.After, want)
+	}
+
+	newer := filepath.Join(dir, "advanced_functions.json")
+	if err := os.WriteFile(newer, []byte(`{"version": "99.0.0"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := MigrateConfigFile(ConfigFile{Path: newer, Kind: "advanced_functions"}); err == nil {
+		t.Errorf("高于当前版本的文件应该报错")
+	}
+}
+
+// TestConfigDocumentJSON 测试 JSON 原文的设置和删除
+func TestConfigDocumentJSON(t *testing.T) {
+	doc, err := ParseConfigDocument([]byte("{\n  \"a\": {},\n  \"b\": [1, 2]\n}\n"), FormatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.Set([]string{"a", "x", "y"}, "v"); err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.Set([]string{"b"}, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.Set([]string{"b", "c"}, "v"); err == nil {
+		t.Errorf("上级不是对象时应该报错")
+	}
+	if deleted, err := doc.Delete([]string{"a", "missing"}); err != nil || deleted {
+		t.Errorf("删除不存在的键应返回 false")
+	}
+
+	data, _ := doc.Bytes()
+	want := "{\n  \"a\": {\n    \"x\": {\n      \"y\": \"v\"\n    }\n  },\n  \"b\": true\n}\n"
+	if string(data) != want {
+		t.Errorf("结果不符:\n%s\n期望:\n%s", data, want)
+	}
+
+	if _, err := doc.Delete([]string{"a"}); err != nil {
+		t.Fatal(err)
+	}
+	data, _ = doc.Bytes()
+	if string(data) != "{\n  \"b\": true\n}\n" {
+		t.Errorf("删除第一个键后结果不符:\n%s", data)
+	}
+	if value, ok := doc.Get([]string{"b"}); !ok || value != true {
+		t.Errorf("Get 返回 %v", value)
+	}
+}
+
+// TestUnifiedDiff 测试差异只包含修改附近的行
+func TestUnifiedDiff(t *testing.T) {
+	lines := []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10"}
+	before := strings.Join(lines, "\n") + "\n"
+	lines[7] = "eight"
+	after := strings.Join(lines, "\n") + "\n"
+
+	want := "--- f\n+++ f\n@@ -5,6 +5,6 @@\n 5\n 6\n 7\n-8\n+eight\n 9\n 10\n"
+	if diff := UnifiedDiff("f", []byte(before), []byte(after)); diff != want {
+		t.Errorf("差异不符:\n%s\n期望:\n%s", diff, want)
+	}
+	if diff := UnifiedDiff("f", []byte(before), []byte(before)); diff != "" {
+		t.Errorf("内容相同时应为空: %s", diff)
+	}
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+)
+
+// LoadPackagesFile 加载单个包配置文件（JSON、YAML 或 TOML）
+func LoadPackagesFile(path string) (*PackagesConfig, error) {
+	return loadConfigFile[PackagesConfig](path)
+}
+
+// PackageRef 包清单中的一个包
+type PackageRef struct {
+	Category string
+	Key      string // 清单中的包名
+}
+
+// SavePackagesFile 将包清单中 refs 指定的包写入文件（JSON、YAML 或 TOML），文件不存在时创建
+//
+// 与 config set 相同，通过 ConfigDocument 只插入新包、只更新已有包中变化的键（新分类连同描述和优先级一起创建），
+// 文件的其余内容（键顺序、行内数组、YAML 注释、$delete 条目）保持不变。
+func SavePackagesFile(path string, packages *PackagesConfig, refs []PackageRef) error {
+	doc, err := LoadConfigDocument(path)
+	if errors.Is(err, os.ErrNotExist) {
+		doc, err = NewConfigDocument(path)
+	}
+	if err != nil {
+		return fmt.Errorf("读取包配置 %s 失败: %w", path, err)
+	}
+
+	for _, ref := range refs {
+		category, ok := packages.Categories[ref.Category]
+		if !ok {
+			return fmt.Errorf("包清单中没有分类 %s", ref.Category)
+		}
+		info, ok := category.Packages[ref.Key]
+		if !ok {
+			return fmt.Errorf("分类 %s 中没有包 %s", ref.Category, ref.Key)
+		}
+
+		keys, value := []string{"categories", ref.Category, "packages", ref.Key}, any(info)
+		if _, exists := doc.Get(keys[:2]); !exists {
+			category.Packages = map[string]PackageInfo{ref.Key: info}
+			keys, value = keys[:2], category
+		}
+		tree, err := configTree(value)
+		if err != nil {
+			return fmt.Errorf("序列化包 %s 失败: %w", ref.Key, err)
+		}
+		if err := mergeDocumentValue(doc, keys, tree); err != nil {
+			return fmt.Errorf("写入包 %s 失败: %w", ref.Key, err)
+		}
+	}
+
+	return doc.Save()
+}
+
+// configTree 将配置结构转换为中间表示（按结构体字段顺序，省略 omitempty 的空值）
+func configTree(value any) (any, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return parseJSONValue(string(data))
+}
+
+// mergeDocumentValue 将值合并到文档中：两边都是对象时逐键递归，其余值只在变化时写入，文档中没有的空值不写入
+func mergeDocumentValue(doc *ConfigDocument, path []string, value any) error {
+	current, exists := doc.Get(path)
+	if !exists {
+		if isEmptyTreeValue(value) {
+			return nil
+		}
+		return doc.Set(path, value)
+	}
+
+	object, isObject := value.(*orderedObject)
+	if _, currentIsObject := current.(*orderedObject); isObject && currentIsObject {
+		for _, key := range object.keys {
+			if err := mergeDocumentValue(doc, append(slices.Clone(path), key), object.values[key]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if FormatConfigValue(current) == FormatConfigValue(value) {
+		return nil
+	}
+	return doc.Set(path, value)
+}
+
+// isEmptyTreeValue 值是否为空字符串、null 或空对象
+func isEmptyTreeValue(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case *orderedObject:
+		return len(v.keys) == 0
+	}
+	return false
+}
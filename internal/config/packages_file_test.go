@@ -0,0 +1,165 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestSavePackagesFile_KeepsLayout 测试只写入有修改的包：行内数组、键顺序、$delete 条目和 YAML 注释保持不变
+func TestSavePackagesFile_KeepsLayout(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    string
+	}{
+		{
+			name: "JSON",
+			file: "arch.json",
+			content: `{
+  "version": "1.1.0",
+  "extends": "linux.json",
+  "categories": {
+    "base": {
+      "priority": 1,
+      "description": "Base",
+      "packages": {
+        "git": {"description": "Git", "tags": ["vcs", "cli"], "managers": {"pacman": "git"}},
+        "nano": {"$delete": true}
+      }
+    }
+  }
+}
+`,
+			want: `{
+  "version": "1.1.0",
+  "extends": "linux.json",
+  "categories": {
+    "base": {
+      "priority": 1,
+      "description": "Base",
+      "packages": {
+        "git": {"description": "Git", "tags": ["vcs", "cli"], "managers": {"pacman": "git", "winget": "Git.Git"}},
+        "nano": {"$delete": true},
+        "ripgrep": {
+          "description": "",
+          "managers": {
+            "pacman": "ripgrep"
+          }
+        }
+      }
+    },
+    "imported": {
+      "description": "Imported",
+      "priority": 2,
+      "packages": {
+        "fd": {
+          "description": "find",
+          "managers": {
+            "pacman": "fd"
+          }
+        }
+      }
+    }
+  }
+}
+`,
+		},
+		{
+			name: "YAML",
+			file: "arch.yaml",
+			content: `version: 1.1.0
+categories:
+  base:
+    # 基础工具
+    priority: 1
+    description: Base
+    packages:
+      git: {description: Git, tags: [vcs, cli], managers: {pacman: git}} # 版本控制
+      nano: {$delete: true}
+`,
+			want: `version: 1.1.0
+categories:
+  base:
+    # 基础工具
+    priority: 1
+    description: Base
+    packages:
+      git: {description: Git, tags: [vcs, cli], managers: {pacman: git, winget: Git.Git}} # 版本控制
+      nano: {$delete: true}
+      ripgrep:
+        description: ""
+        managers:
+          pacman: ripgrep
+  imported:
+    description: Imported
+    priority: 2
+    packages:
+      fd:
+        description: find
+        managers:
+          pacman: fd
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			packages, err := LoadPackagesFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			base := packages.Categories["base"]
+			git := base.Packages["git"]
+			git.Managers["winget"] = "Git.Git"
+			base.Packages["git"] = git
+			base.Packages["ripgrep"] = PackageInfo{Managers: map[string]string{"pacman": "ripgrep"}}
+			packages.Categories["imported"] = Category{
+				Description: "Imported",
+				Priority:    2,
+				Packages:    map[string]PackageInfo{"fd": {Description: "find", Managers: map[string]string{"pacman": "fd"}}},
+			}
+
+			refs := []PackageRef{{"base", "git"}, {"base", "ripgrep"}, {"imported", "fd"}}
+			if err := SavePackagesFile(path, packages, refs); err != nil {
+				t.Fatal(err)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("写入结果不符，实际:\n%s", data)
+			}
+		})
+	}
+}
+
+// TestSavePackagesFile_New 测试包配置文件不存在时创建
+func TestSavePackagesFile_New(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "packages", "linux.json")
+	packages := &PackagesConfig{Categories: map[string]Category{
+		"imported": {Description: "Imported", Priority: 1, Packages: map[string]PackageInfo{"git": {Managers: map[string]string{"pacman": "git"}}}},
+	}}
+	if err := SavePackagesFile(path, packages, []PackageRef{{"imported", "git"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadPackagesFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Version != CurrentConfigVersion || loaded.Categories["imported"].Packages["git"].Managers["pacman"] != "git" {
+		t.Errorf("新文件内容不符: %+v", loaded)
+	}
+	if data, _ := os.ReadFile(path); strings.Contains(string(data), "package_managers") {
+		t.Errorf("新文件不应包含空的 package_managers:\n%s", data)
+	}
+}
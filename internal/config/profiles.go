@@ -0,0 +1,128 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Profile 包清单中的命名配置方案，按机器角色组合分类和标签（如 minimal、work、full）
+type Profile struct {
+	Description string   `json:"description,omitempty"`
+	Extends     string   `json:"extends,omitempty"`    // 继承的 profile，在其结果上增删
+	Categories  []string `json:"categories,omitempty"` // 包含这些分类中的所有包
+	Tags        []string `json:"tags,omitempty"`       // 包含带有任一标签的包
+	Packages    []string `json:"packages,omitempty"`   // 额外包含的包
+	Exclude     []string `json:"exclude,omitempty"`    // 排除的包（最后应用）
+	Delete      bool     `json:"$delete,omitempty"`    // 删除标记：从继承的配置中移除该 profile
+}
+
+// ProfileNames 返回所有 profile 名称（已排序）
+func (pc *PackagesConfig) ProfileNames() []string {
+	names := make([]string, 0, len(pc.Profiles))
+	for name := range pc.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ResolveProfile 返回 profile 选中的包名集合
+func (pc *PackagesConfig) ResolveProfile(name string) (map[string]bool, error) {
+	return pc.resolveProfile(name, nil)
+}
+
+// ForProfile 返回只包含 profile 选中包的清单副本（没有选中包的分类被移除）
+func (pc *PackagesConfig) ForProfile(name string) (*PackagesConfig, error) {
+	selected, err := pc.ResolveProfile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := *pc
+	filtered.Categories = make(map[string]Category)
+	for categoryName, category := range pc.Categories {
+		packages := make(map[string]PackageInfo)
+		for pkgName, info := range category.Packages {
+			if selected[pkgName] {
+				packages[pkgName] = info
+			}
+		}
+		if len(packages) == 0 {
+			continue
+		}
+		category.Packages = packages
+		filtered.Categories[categoryName] = category
+	}
+	return &filtered, nil
+}
+
+// resolveProfile 递归解析 profile，chain 记录继承链用于检测循环
+func (pc *PackagesConfig) resolveProfile(name string, chain []string) (map[string]bool, error) {
+	if slices.Contains(chain, name) {
+		return nil, fmt.Errorf("profile 循环继承: %s", strings.Join(append(chain, name), " → "))
+	}
+	profile, ok := pc.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("profile %s 未定义（可用: %s）", name, strings.Join(pc.ProfileNames(), ", "))
+	}
+	chain = append(chain, name)
+
+	selected := make(map[string]bool)
+	if profile.Extends != "" {
+		parent, err := pc.resolveProfile(profile.Extends, chain)
+		if err != nil {
+			return nil, err
+		}
+		selected = parent
+	}
+
+	for _, categoryName := range profile.Categories {
+		category, ok := pc.Categories[categoryName]
+		if !ok {
+			return nil, fmt.Errorf("profile %s 引用了不存在的分类 %s", name, categoryName)
+		}
+		for pkgName := range category.Packages {
+			selected[pkgName] = true
+		}
+	}
+
+	if len(profile.Tags) > 0 {
+		for _, category := range pc.Categories {
+			for pkgName, info := range category.Packages {
+				for _, tag := range info.Tags {
+					if slices.Contains(profile.Tags, tag) {
+						selected[pkgName] = true
+						break
+					}
+				}
+			}
+		}
+	}
+
+	for _, pkgName := range profile.Packages {
+		if !pc.hasPackage(pkgName) {
+			return nil, fmt.Errorf("profile %s 引用了不存在的包 %s", name, pkgName)
+		}
+		selected[pkgName] = true
+	}
+	for _, pkgName := range profile.Exclude {
+		if !pc.hasPackage(pkgName) {
+			return nil, fmt.Errorf("profile %s 排除了不存在的包 %s", name, pkgName)
+		}
+		delete(selected, pkgName)
+	}
+
+	return selected, nil
+}
+
+// hasPackage 检查清单中是否存在该包
+func (pc *PackagesConfig) hasPackage(name string) bool {
+	for _, category := range pc.Categories {
+		if _, ok := category.Packages[name]; ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,336 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/bbq191/dotfiles-go/internal/secrets"
+)
+
+// ValueSource 解析后的配置项的来源
+type ValueSource struct {
+	File     string   `json:"file,omitempty"`     // 提供该值的配置文件
+	Line     int      `json:"line,omitempty"`     // 键在文件中的行号（TOML 文件没有行号）
+	Default  string   `json:"default,omitempty"`  // 不来自配置文件时的默认值说明
+	Platform string   `json:"platform,omitempty"` // 路径值使用的平台键
+	Env      []string `json:"env,omitempty"`      // 展开时读取的环境变量
+	Secret   bool     `json:"secret,omitempty"`   // 包含密钥引用（不解密）
+	Error    string   `json:"error,omitempty"`    // 展开失败的原因（值保持原样）
+}
+
+// 默认值说明
+const (
+	DefaultBuiltin = "内置默认值"     // 内置默认值层（见 builtinSharedDefaults）
+	DefaultLoader  = "加载时设置的默认值" // setDefaultValues 设置的默认值
+)
+
+// ResolvedSection 一种配置解析后的结果
+type ResolvedSection struct {
+	Kind    string                 // 配置种类（shared、zsh_integration、packages、advanced_functions）
+	Files   []string               // 参与合并的文件（按合并顺序）
+	Note    string                 // 补充说明，如包配置的平台回退
+	Data    map[string]interface{} // 解析后的值：路径值已按平台取值，环境变量已展开
+	Sources map[string]ValueSource // 叶子值的路径 -> 来源
+}
+
+// ResolvedConfig 当前平台解析后的完整配置
+type ResolvedConfig struct {
+	Platform     string // 当前平台（选择包配置文件）
+	PathPlatform string // 路径值使用的平台键
+	Sections     []*ResolvedSection
+}
+
+// Resolve 按加载配置时的规则解析当前平台的配置，并记录每个叶子值的来源
+//
+// 与 LoadConfig 相同：合并各配置层和 extends/include，路径值按平台取值（没有该平台时使用 default），
+// 环境变量展开和默认值由加载时的处理（见 traceLoad）得到。密钥引用不解密，配置中未出现的字段（使用零值）不列出。
+func (cl *ConfigLoader) Resolve() (*ResolvedConfig, error) {
+	resolved := &ResolvedConfig{Platform: cl.platform, PathPlatform: pathValuePlatform()}
+	sourceMaps := make(map[string]*SourceMap)
+
+	var kinds []string
+	composedKinds := make(map[string]*ComposedConfig)
+	for _, kind := range SchemaNames() {
+		composed, err := cl.Compose(kind)
+		if err != nil {
+			if kind == "shared" {
+				return nil, err
+			}
+			if !errors.Is(err, os.ErrNotExist) {
+				cl.logger.Warnf("加载 %s 失败: %v", kind, err)
+			}
+			continue
+		}
+		kinds = append(kinds, kind)
+		composedKinds[kind] = composed
+	}
+
+	trace, err := cl.traceLoad(composedKinds["shared"], composedKinds["zsh_integration"])
+	if err != nil {
+		return nil, err
+	}
+
+	for _, kind := range kinds {
+		composed := composedKinds[kind]
+		spec, _ := findConfigSchema(kind)
+		r := &resolver{
+			kind:       kind,
+			platform:   resolved.PathPlatform,
+			composed:   composed,
+			trace:      trace,
+			sourceMaps: sourceMaps,
+			section: &ResolvedSection{
+				Kind:    kind,
+				Files:   composed.Files,
+				Sources: make(map[string]ValueSource),
+			},
+		}
+		r.section.Data = r.object(spec.root, composed.Data, "")
+		r.setDefaultValues()
+		if kind == "packages" && len(composed.Files) > 0 {
+			file := composed.Files[len(composed.Files)-1]
+			if base := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file)); base != cl.platform {
+				r.section.Note = "当前平台 " + cl.platform + " 没有包配置，使用 packages/" + base
+			}
+		}
+		resolved.Sections = append(resolved.Sections, r.section)
+	}
+	return resolved, nil
+}
+
+// traceLoad 对合并后的主配置和 Zsh 配置执行加载时的处理（展开环境变量、设置默认值，不解密密钥），
+// 记录每个配置项的处理结果
+func (cl *ConfigLoader) traceLoad(shared, zsh *ComposedConfig) (*loadTrace, error) {
+	var config DotfilesConfig
+	if err := shared.Decode(&config); err != nil {
+		return nil, err
+	}
+	if zsh != nil {
+		var zshConfig ZshIntegrationConfig
+		if err := zsh.Decode(&zshConfig); err != nil {
+			cl.logger.Warnf("加载 zsh_integration 失败: %v", err)
+		} else {
+			config.ZshConfig = &zshConfig
+		}
+	}
+
+	trace := &loadTrace{values: make(map[string]tracedValue)}
+	cl.trace, cl.expandErrors = trace, nil
+	defer func() { cl.trace, cl.expandErrors = nil, nil }()
+
+	cl.expandEnvironmentVariables(&config)
+	for _, field := range secretFields(&config) {
+		if secrets.HasReferences(field.value) {
+			trace.recordSecret(field.kind, field.path)
+		}
+	}
+	cl.setDefaultValues(&config)
+	return trace, nil
+}
+
+// loadTrace 加载过程对配置项的处理，键为 配置种类:JSON 路径；方法在 nil 上调用时不记录
+type loadTrace struct {
+	values   map[string]tracedValue
+	defaults []tracedDefault // setDefaultValues 设置的默认值，按设置顺序
+}
+
+// tracedValue 配置项在加载时的处理结果
+type tracedValue struct {
+	expanded bool     // 展开过环境变量
+	value    string   // 展开后的值（展开失败时为原值）
+	env      []string // 展开时读取的环境变量
+	err      error    // 展开失败的原因
+	secret   bool     // 包含密钥引用
+}
+
+// tracedDefault 加载时设置的默认值
+type tracedDefault struct {
+	kind, path string
+	value      interface{}
+	env        []string // 默认值来自的环境变量
+}
+
+func traceKey(kind, path string) string {
+	return kind + ":" + path
+}
+
+// recordExpansion 记录环境变量展开的结果
+func (t *loadTrace) recordExpansion(kind, path, value string, env []string, err error) {
+	if t == nil {
+		return
+	}
+	traced := t.values[traceKey(kind, path)]
+	traced.expanded, traced.value, traced.env, traced.err = true, value, env, err
+	t.values[traceKey(kind, path)] = traced
+}
+
+// recordSecret 记录包含密钥引用的配置项
+func (t *loadTrace) recordSecret(kind, path string) {
+	if t == nil {
+		return
+	}
+	traced := t.values[traceKey(kind, path)]
+	traced.secret = true
+	t.values[traceKey(kind, path)] = traced
+}
+
+// recordDefault 记录加载时设置的默认值
+func (t *loadTrace) recordDefault(kind, path string, value interface{}, env []string) {
+	if t == nil {
+		return
+	}
+	t.defaults = append(t.defaults, tracedDefault{kind: kind, path: path, value: value, env: env})
+}
+
+// pathValuePlatform 当前系统在路径值中对应的平台键（与模板中的 getPlatformValue 一致）
+func pathValuePlatform() string {
+	switch runtime.GOOS {
+	case "windows":
+		return "windows"
+	case "darwin":
+		return "macos"
+	}
+	return "linux"
+}
+
+// resolver 解析一种配置的合并结果
+type resolver struct {
+	kind       string
+	platform   string
+	composed   *ComposedConfig
+	trace      *loadTrace            // 加载时的处理结果
+	sourceMaps map[string]*SourceMap // 文件 -> 键位置，多种配置共用
+	section    *ResolvedSection
+}
+
+// object 解析对象的每个键；t 为对应的 Go 类型，nil 表示任意值
+func (r *resolver) object(t reflect.Type, data map[string]interface{}, path string) map[string]interface{} {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	result := make(map[string]interface{}, len(data))
+	for _, key := range keys {
+		result[key] = r.value(childType(t, key, path == ""), data[key], joinPath(path, key))
+	}
+	return result
+}
+
+// value 解析一个值：路径值按平台取值，对象逐键解析，其他值为叶子
+func (r *resolver) value(t reflect.Type, value interface{}, path string) interface{} {
+	if t == pathValueType {
+		source, valuePath := r.source(path), path
+		if platforms, ok := value.(map[string]interface{}); ok {
+			value = ""
+			for _, key := range []string{r.platform, "default"} {
+				if v, exists := platforms[key]; exists {
+					value, valuePath = v, joinPath(path, key)
+					source = r.source(valuePath)
+					source.Platform = key
+					break
+				}
+			}
+		}
+		return r.leaf(value, path, valuePath, source)
+	}
+	if object, ok := value.(map[string]interface{}); ok {
+		return r.object(t, object, path)
+	}
+	return r.leaf(value, path, path, r.source(path))
+}
+
+// leaf 记录叶子值的来源，并使用加载时的处理结果（valuePath 为取值的路径，路径值按平台取值时包含平台键）
+func (r *resolver) leaf(value interface{}, path, valuePath string, source ValueSource) interface{} {
+	if traced, ok := r.trace.values[traceKey(r.kind, valuePath)]; ok {
+		if traced.expanded {
+			value, source.Env = traced.value, traced.env
+			if traced.err != nil {
+				source.Error = traced.err.Error()
+			}
+		}
+		source.Secret = traced.secret
+	}
+	r.section.Sources[path] = source
+	return value
+}
+
+// source 返回路径的来源文件和行号
+func (r *resolver) source(path string) ValueSource {
+	file := r.composed.Source(path)
+	if file == BuiltinLayerSource {
+		return ValueSource{Default: DefaultBuiltin}
+	}
+	source := ValueSource{File: file}
+	sm, ok := r.sourceMaps[file]
+	if !ok {
+		sm, _ = locateKeys(file)
+		r.sourceMaps[file] = sm
+	}
+	source.Line, _ = sm.Locate(path)
+	return source
+}
+
+// setDefaultValues 补充加载时设置的默认值
+func (r *resolver) setDefaultValues() {
+	for _, def := range r.trace.defaults {
+		if def.kind != r.kind {
+			continue
+		}
+		keys := strings.Split(def.path, ".")
+		object := r.section.Data
+		for _, key := range keys[:len(keys)-1] {
+			child, ok := object[key].(map[string]interface{})
+			if !ok {
+				child = make(map[string]interface{})
+				object[key] = child
+			}
+			object = child
+		}
+		object[keys[len(keys)-1]] = def.value
+		r.section.Sources[def.path] = ValueSource{Default: DefaultLoader, Env: def.env}
+	}
+}
+
+// childType 返回对象中键对应的 Go 类型，未知或任意值时返回 nil
+func childType(t reflect.Type, key string, root bool) reflect.Type {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil {
+		return nil
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		if field, ok := structFieldType(t, key); ok {
+			return field
+		}
+	case reflect.Map:
+		if !root || !isReservedKey(key) {
+			return t.Elem()
+		}
+	}
+	return nil
+}
+
+// locateKeys 读取配置文件并记录每个键的位置（不解码）
+func locateKeys(file string) (*SourceMap, error) {
+	data, locations, err := readConfigJSON(file)
+	if err != nil {
+		return nil, err
+	}
+	sm := &SourceMap{File: file, data: data, positions: make(map[string]int), locations: locations}
+	walker := &jsonWalker{dec: json.NewDecoder(bytes.NewReader(data)), source: sm}
+	if err := walker.walkValue(nil, ""); err != nil {
+		return nil, err
+	}
+	return sm, nil
+}
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TestResolve 测试解析后的值和来源：文件行号、路径值的平台键、环境变量和默认值
+func TestResolve(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("EDITOR", "")
+	t.Setenv("RESOLVE_TEST_DIR", "/srv")
+
+	shared := `{
+  "version": "1.1.0",
+  "user": {"name": "test", "email": "test@example.com"},
+  "paths": {
+    "projects": {
+      "default": "$RESOLVE_TEST_DIR/projects",
+      "plan9": "/n/projects"
+    },
+    "dotfiles": "$delete"
+  }
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "shared.json"), []byte(shared), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	resolved, err := NewConfigLoader(dir, logger).Resolve()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resolved.Sections) != 1 || resolved.Sections[0].Kind != "shared" {
+		t.Fatalf("期望只有 shared，实际 %d 种配置", len(resolved.Sections))
+	}
+	section := resolved.Sections[0]
+
+	paths := section.Data["paths"].(map[string]interface{})
+	if paths["projects"] != "/srv/projects" {
+		t.Errorf("路径值应使用 default 并展开环境变量，实际 %v", paths["projects"])
+	}
+	if _, ok := paths["dotfiles"]; ok {
+		t.Errorf("$delete 应删除内置默认值")
+	}
+	source := section.Sources["paths.projects"]
+	if source.Line != 6 || source.Platform != "default" || len(source.Env) != 1 || source.Env[0] != "RESOLVE_TEST_DIR" {
+		t.Errorf("paths.projects 的来源不符: %+v", source)
+	}
+	if source := section.Sources["user.email"]; source.Line != 3 {
+		t.Errorf("user.email 应位于第 3 行: %+v", source)
+	}
+
+	user := section.Data["user"].(map[string]interface{})
+	if user["editor"] != "nano" || section.Sources["user.editor"].Default != DefaultLoader {
+		t.Errorf("editor 应为加载时的默认值 nano: %v %+v", user["editor"], section.Sources["user.editor"])
+	}
+}
+
+// TestResolve_LoadTrace 测试来源标注与加载时的处理一致：密钥引用、未启用的 XDG 目录不展开、开发环境路径展开
+func TestResolve_LoadTrace(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("EDITOR", "hx")
+	t.Setenv("RESOLVE_TEST_DIR", "/srv")
+
+	files := map[string]string{
+		"shared.json": `{
+  "user": {"name": "test", "email": "test@example.com"},
+  "environment": {"VALKEY_PASSWORD": "{{ secret \"valkey.password\" }}"}
+}
+`,
+		"zsh_integration.json": `{
+  "proxy": {
+    "active_profile": "${RESOLVE_TEST_PROFILE:-work}",
+    "profiles": {"work": {"https_proxy": "{{ secret \"proxy.work\" }}", "no_proxy": "$RESOLVE_TEST_DIR"}}
+  },
+  "xdg_directories": {"enabled": false, "config_home": "$RESOLVE_TEST_DIR/config"},
+  "development_environments": {"go": {"gopath": "$RESOLVE_TEST_DIR/go"}}
+}
+`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	resolved, err := NewConfigLoader(dir, logger).Resolve()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sections := make(map[string]*ResolvedSection)
+	for _, section := range resolved.Sections {
+		sections[section.Kind] = section
+	}
+	shared, zsh := sections["shared"], sections["zsh_integration"]
+	if shared == nil || zsh == nil {
+		t.Fatalf("应包含 shared 和 zsh_integration: %v", sections)
+	}
+
+	if source := shared.Sources["environment.VALKEY_PASSWORD"]; !source.Secret {
+		t.Errorf("environment 中的密钥引用应标注: %+v", source)
+	}
+	if source := shared.Sources["user.editor"]; source.Default != DefaultLoader || len(source.Env) != 1 || source.Env[0] != "EDITOR" {
+		t.Errorf("editor 应为来自 $EDITOR 的默认值: %+v", source)
+	}
+
+	tests := []struct {
+		path   string
+		want   string
+		env    []string
+		secret bool
+	}{
+		{"proxy.active_profile", "work", []string{"RESOLVE_TEST_PROFILE"}, false},
+		{"proxy.profiles.work.https_proxy", `{{ secret "proxy.work" }}`, nil, true},
+		// 代理地址在生成模板时展开，加载时不展开
+		{"proxy.profiles.work.no_proxy", "$RESOLVE_TEST_DIR", nil, false},
+		// XDG 目录只在启用时展开
+		{"xdg_directories.config_home", "$RESOLVE_TEST_DIR/config", nil, false},
+		{"development_environments.go.gopath", "/srv/go", []string{"RESOLVE_TEST_DIR"}, false},
+	}
+	for _, tt := range tests {
+		value, _ := LookupValue(zsh.Data, strings.Split(tt.path, "."))
+		source := zsh.Sources[tt.path]
+		if value != tt.want || strings.Join(source.Env, ",") != strings.Join(tt.env, ",") || source.Secret != tt.secret {
+			t.Errorf("%s: 期望 %q（环境变量 %v，密钥 %v），实际 %v %+v", tt.path, tt.want, tt.env, tt.secret, value, source)
+		}
+	}
+}
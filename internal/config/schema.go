@@ -0,0 +1,629 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// SchemaKey 配置文件顶层的 JSON Schema 引用，只供编辑器使用，加载时忽略
+const SchemaKey = "$schema"
+
+// schemaDraft 生成的 JSON Schema 版本（VS Code 完整支持 draft-07）
+const schemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// KnownManagers 包配置中可以使用的包管理器名称
+var KnownManagers = []string{
+	"pacman", "yay", "apt", "yum", "snap", "winget", "scoop", "choco",
+	"npm", "cargo", "go", "pipx", "uv",
+}
+
+// pathValueKeys PathValue 对象形式支持的键：平台名、shell 名和 default
+var pathValueKeys = []string{"default", "linux", "macos", "windows", "zsh", "bash", "powershell"}
+
+// configSchema 可以生成 JSON Schema 的配置文件
+type configSchema struct {
+	name  string // 配置文件基名，也是 schema 文件名的前缀
+	title string
+	root  reflect.Type
+}
+
+var configSchemas = []configSchema{
+	{"shared", "dotfiles 主配置（shared.json）", reflect.TypeOf(DotfilesConfig{})},
+	{"zsh_integration", "Zsh 集成配置（zsh_integration.json）", reflect.TypeOf(ZshIntegrationConfig{})},
+	{"packages", "包配置（packages/<平台>.json）", reflect.TypeOf(PackagesConfig{})},
+	{"advanced_functions", "Shell 函数配置（advanced_functions.json）", reflect.TypeOf(map[string]FunctionInfo{})},
+}
+
+// configVersionDescription 各配置文件顶层 version 键的说明
+const configVersionDescription = "配置格式版本（semver），低于当前版本时可用 dotfiles config migrate 升级"
+
+// schemaDescriptions 字段说明: 类型名 -> JSON 键 -> 说明
+var schemaDescriptions = map[string]map[string]string{
+	"DotfilesConfig": {
+		"version":     configVersionDescription,
+		"user":        "用户信息",
+		"paths":       "常用目录",
+		"environment": "导出到 shell 的环境变量，值支持 ${VAR:-默认值} 展开和 {{ secret \"名称\" }} 密钥引用",
+		"features":    "功能开关",
+		"profile":     "默认使用的包清单 profile",
+	},
+	"UserConfig": {
+		"name":    "用户名",
+		"email":   "邮箱",
+		"editor":  "默认编辑器命令",
+		"browser": "默认浏览器命令",
+	},
+	"PathsConfig": {
+		"projects":  "项目目录",
+		"dotfiles":  "dotfiles 仓库目录",
+		"scripts":   "脚本目录",
+		"templates": "模板目录",
+	},
+	"FeaturesConfig": {
+		"git_integration":    "启用 Git 集成",
+		"nodejs_management":  "启用 Node.js 版本管理",
+		"python_management":  "启用 Python 版本管理",
+		"completion_cache":   "已废弃（从未生效），请使用 zsh_integration 的 performance.completion_cache",
+		"async_loading":      "已废弃（从未生效），请使用 zsh_integration 的 performance.async_loading",
+		"path_deduplication": "已废弃（从未生效），请使用 zsh_integration 的 performance.path_deduplication",
+	},
+	"ZshIntegrationConfig": {
+		"version":                  configVersionDescription,
+		"proxy":                    "代理配置",
+		"xdg_directories":          "XDG 基础目录",
+		"history_advanced":         "历史记录配置",
+		"completion_advanced":      "补全配置",
+		"modern_tools":             "现代命令行工具替代",
+		"development_environments": "开发环境变量: 环境名 -> 变量名 -> 路径",
+		"fzf_config":               "fzf 配置",
+		"keybindings":              "键绑定",
+		"version_managers":         "版本管理器（fnm、pyenv、sdkman、g 等）",
+		"git_tools":                "Git 工具（delta、lazygit 等）",
+		"external_tools":           "外部工具",
+		"performance":              "性能选项",
+	},
+	"ProxyConfig": {
+		"enabled":        "启用代理",
+		"auto_detect":    "自动检测代理是否可用",
+		"profiles":       "代理方案: 名称 -> 代理地址",
+		"active_profile": "使用的代理方案名称",
+	},
+	"ProxyProfile": {
+		"https_proxy": "https_proxy，支持 {{ secret \"名称\" }} 密钥引用",
+		"http_proxy":  "http_proxy，支持 {{ secret \"名称\" }} 密钥引用",
+		"all_proxy":   "all_proxy，支持 {{ secret \"名称\" }} 密钥引用",
+		"no_proxy":    "不走代理的地址，逗号分隔",
+	},
+	"XDGConfig": {
+		"enabled":     "设置 XDG 环境变量",
+		"config_home": "XDG_CONFIG_HOME",
+		"data_home":   "XDG_DATA_HOME",
+		"state_home":  "XDG_STATE_HOME",
+		"cache_home":  "XDG_CACHE_HOME",
+		"runtime_dir": "XDG_RUNTIME_DIR",
+		"user_bin":    "用户可执行文件目录",
+	},
+	"HistoryConfig": {
+		"file":       "历史记录文件",
+		"backup_dir": "历史记录备份目录",
+		"size":       "内存中保留的条数（HISTSIZE）",
+		"save_size":  "文件中保留的条数（SAVEHIST）",
+		"options":    "zsh 历史记录选项",
+	},
+	"CompletionConfig": {
+		"cache_path": "补全缓存目录",
+		"dump_file":  "compinit 的 dump 文件",
+		"options":    "补全选项",
+		"styles":     "zstyle 补全样式",
+	},
+	"ModernToolsConfig": {
+		"replacements": "被替代的命令 -> 替代工具",
+	},
+	"ToolReplacement": {
+		"tool":         "替代工具命令",
+		"fallback":     "替代工具不存在时使用的命令",
+		"aliases":      "别名",
+		"init_command": "初始化命令",
+		"env_vars":     "环境变量",
+	},
+	"FzfConfig": {
+		"enabled":  "启用 fzf",
+		"commands": "fzf 默认命令（FZF_DEFAULT_COMMAND 等）",
+		"theme":    "配色",
+		"preview":  "预览命令",
+	},
+	"KeybindingsConfig": {
+		"history_search":  "历史搜索键绑定",
+		"word_navigation": "按词移动键绑定",
+		"line_navigation": "行内移动键绑定",
+	},
+	"VersionManager": {
+		"enabled":        "启用该版本管理器",
+		"init_command":   "shell 初始化命令",
+		"env_vars":       "环境变量，值可以是字符串或 shell 名 -> 值的对象",
+		"path_additions": "添加到 PATH 的目录",
+		"post_install":   "安装后执行的命令",
+	},
+	"GitTool": {
+		"enabled":    "启用该工具",
+		"git_config": "写入 git 配置的键值",
+		"aliases":    "别名",
+		"extensions": "扩展",
+	},
+	"ExternalToolsConfig": {
+		"auto_init": "工具名 -> 初始化命令",
+	},
+	"PerformanceConfig": {
+		"makeflags":          "MAKEFLAGS",
+		"async_loading":      "异步加载插件",
+		"completion_cache":   "缓存补全结果",
+		"path_deduplication": "PATH 去重",
+	},
+	"PackagesConfig": {
+		"version":          configVersionDescription,
+		"extends":          "继承的包配置文件（相对当前文件的路径）",
+		"include":          "额外合并的包配置文件，按顺序覆盖 extends",
+		"categories":       "包分类",
+		"package_managers": "包管理器配置",
+		"aur_review":       "AUR PKGBUILD 审查配置",
+		"timeouts":         "默认安装超时",
+		"profiles":         "按机器角色组合的包选择方案",
+	},
+	"AURReviewConfig": {
+		"trusted_packages": "免审查的包和维护者",
+	},
+	"TrustedAURPackage": {
+		"name":       "AUR 包名",
+		"maintainer": "维护者（需与包名同时匹配）",
+	},
+	"Category": {
+		"description": "分类说明",
+		"priority":    "安装顺序，数字越小越先安装",
+		"packages":    "包名 -> 包信息",
+		"when":        "平台条件，不满足时整个分类被忽略",
+		"$delete":     "从继承的配置中移除该分类",
+	},
+	"PackageInfo": {
+		"description":          "包说明",
+		"tags":                 "标签，用于按标签安装和 profile 选择",
+		"managers":             "包管理器 -> 包名",
+		"preferred_manager":    "首选包管理器，需在 managers 中有映射",
+		"optional":             "可选包，安装失败不影响整体结果",
+		"post_install":         "安装后执行的命令（只在 install --run-hooks 时于整批安装完成后依次执行，可交互）",
+		"version":              "版本约束: semver 范围（如 >=0.10）或 pacman 精确版本 pkgver-pkgrel（如 0.10.2-1，不带运算符）；semver 预发布版本需要写运算符（如 =1.2.3-1）",
+		"timeout":              "安装超时（Go duration，如 20m），覆盖 timeouts.install",
+		"post_install_timeout": "post_install 命令超时，覆盖 timeouts.post_install",
+		"when":                 "平台条件，不满足时忽略该包",
+		"$delete":              "从继承的配置中移除该包",
+	},
+	"Manager": {
+		"command":      "包管理器命令",
+		"install_args": "安装参数",
+		"priority":     "优先级，数字越小越优先",
+		"parallel":     "支持并行安装",
+		"$delete":      "从继承的配置中移除该包管理器",
+	},
+	"TimeoutConfig": {
+		"install":      "单个包的安装超时（Go duration，如 20m、90s，0 表示不限制）",
+		"post_install": "单个 post_install 命令的超时",
+		"batch":        "整批安装的超时",
+	},
+	"Profile": {
+		"description": "说明",
+		"extends":     "继承的 profile，在其结果上增删",
+		"categories":  "包含这些分类中的所有包",
+		"tags":        "包含带有任一标签的包",
+		"packages":    "额外包含的包",
+		"exclude":     "排除的包（最后应用）",
+		"$delete":     "从继承的配置中移除该 profile",
+	},
+	"Condition": {
+		"os":         "操作系统（runtime.GOOS），! 开头表示排除",
+		"wsl":        "true 仅在 WSL2 中，false 仅在非 WSL2 环境",
+		"distro":     "Linux 发行版 ID（如 arch、ubuntu、fedora），! 开头表示排除",
+		"arch":       "系统架构（runtime.GOARCH），! 开头表示排除",
+		"powershell": "PowerShell 版本类型，! 开头表示排除",
+	},
+	"FunctionInfo": {
+		"description": "函数说明",
+		"bash":        "Bash 实现",
+		"zsh":         "Zsh 实现",
+		"powershell":  "PowerShell 实现",
+		"when":        "平台条件，不满足时生成配置时忽略该函数",
+	},
+}
+
+// conditionEnums when 条件中取值固定的键
+var conditionEnums = map[string][]string{
+	"os":         {"linux", "windows", "darwin"},
+	"arch":       {"amd64", "arm64", "386", "arm"},
+	"powershell": {"core", "desktop"},
+}
+
+// JSONSchema JSON Schema（draft-07）中用到的关键字
+type JSONSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	Ref                  string                 `json:"$ref,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	Pattern              string                 `json:"pattern,omitempty"`
+	MinLength            int                    `json:"minLength,omitempty"`
+	Enum                 []string               `json:"enum,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	PropertyNames        *JSONSchema            `json:"propertyNames,omitempty"`
+	AdditionalProperties any                    `json:"additionalProperties,omitempty"` // false 或 *JSONSchema
+	OneOf                []*JSONSchema          `json:"oneOf,omitempty"`
+	Definitions          map[string]*JSONSchema `json:"definitions,omitempty"`
+}
+
+// SchemaNames 返回可以生成 JSON Schema 的配置文件基名
+func SchemaNames() []string {
+	names := make([]string, 0, len(configSchemas))
+	for _, schema := range configSchemas {
+		names = append(names, schema.name)
+	}
+	return names
+}
+
+// SchemaFileName 返回配置对应的 schema 文件名
+func SchemaFileName(name string) string {
+	return name + ".schema.json"
+}
+
+// GenerateSchema 根据配置结构生成 JSON Schema（两空格缩进）
+func GenerateSchema(name string) ([]byte, error) {
+	root, err := buildSchema(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(root); err != nil {
+		return nil, fmt.Errorf("序列化 JSON Schema 失败: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// buildSchema 生成配置的 JSON Schema
+func buildSchema(name string) (*JSONSchema, error) {
+	spec, err := findConfigSchema(name)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &schemaGenerator{definitions: make(map[string]*JSONSchema)}
+	var root *JSONSchema
+	if spec.root.Kind() == reflect.Struct {
+		root = g.structSchema(spec.root)
+	} else {
+		root = g.schemaFor(spec.root)
+		root.Properties = map[string]*JSONSchema{
+			SchemaKey:  schemaKeySchema(),
+			VersionKey: {Type: "string", Description: configVersionDescription},
+		}
+	}
+	root.Schema = schemaDraft
+	root.Title = spec.title
+	if len(g.definitions) > 0 {
+		root.Definitions = g.definitions
+	}
+	return root, nil
+}
+
+// findConfigSchema 按名称查找配置
+func findConfigSchema(name string) (configSchema, error) {
+	index := slices.IndexFunc(configSchemas, func(s configSchema) bool { return s.name == name })
+	if index < 0 {
+		return configSchema{}, fmt.Errorf("未知的配置: %s（可用: %s）", name, strings.Join(SchemaNames(), ", "))
+	}
+	return configSchemas[index], nil
+}
+
+// schemaGenerator 按 encoding/json 的规则把 Go 类型转换为 JSON Schema，命名结构体放入 definitions
+type schemaGenerator struct {
+	definitions map[string]*JSONSchema
+}
+
+var (
+	pathValueType       = reflect.TypeOf(PathValue{})
+	conditionValuesType = reflect.TypeOf(ConditionValues{})
+)
+
+// schemaFor 返回类型对应的 schema；命名结构体返回 definitions 中的引用
+func (g *schemaGenerator) schemaFor(t reflect.Type) *JSONSchema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t {
+	case pathValueType:
+		return g.define("PathValue", func() *JSONSchema {
+			return &JSONSchema{
+				Description: "路径: 字符串，或平台/shell 名 -> 路径的对象（未匹配时使用 default）",
+				OneOf: []*JSONSchema{
+					{Type: "string"},
+					{
+						Type:                 "object",
+						PropertyNames:        &JSONSchema{Enum: pathValueKeys},
+						AdditionalProperties: &JSONSchema{Type: "string"},
+					},
+				},
+			}
+		})
+	case conditionValuesType:
+		return g.define("ConditionValues", func() *JSONSchema {
+			return conditionValuesSchema(nil)
+		})
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		return g.define(t.Name(), func() *JSONSchema { return g.structSchema(t) })
+	case reflect.Map:
+		value := g.schemaFor(t.Elem())
+		if elem := t.Elem(); elem.Kind() == reflect.Struct && elem != pathValueType {
+			// 合并时对象项可以写成 "$delete" 删除继承的项
+			value = &JSONSchema{OneOf: []*JSONSchema{value, deleteMarkerSchema()}}
+		}
+		return &JSONSchema{Type: "object", AdditionalProperties: value}
+	case reflect.Slice, reflect.Array:
+		return &JSONSchema{Type: "array", Items: g.schemaFor(t.Elem())}
+	case reflect.String:
+		return &JSONSchema{Type: "string"}
+	case reflect.Bool:
+		return &JSONSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &JSONSchema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &JSONSchema{Type: "number"}
+	}
+	// interface{} 等任意值
+	return &JSONSchema{}
+}
+
+// define 首次引用时生成定义，返回 $ref
+func (g *schemaGenerator) define(name string, build func() *JSONSchema) *JSONSchema {
+	if _, ok := g.definitions[name]; !ok {
+		g.definitions[name] = nil // 占位，防止递归类型无限展开
+		g.definitions[name] = build()
+	}
+	return &JSONSchema{Ref: "#/definitions/" + name}
+}
+
+// structSchema 生成结构体的对象 schema：json 标签为属性名，validate 标签中的 required、email、semver、min=1 转换为约束
+func (g *schemaGenerator) structSchema(t reflect.Type) *JSONSchema {
+	schema := &JSONSchema{
+		Type:                 "object",
+		Properties:           make(map[string]*JSONSchema),
+		AdditionalProperties: false,
+	}
+	descriptions := schemaDescriptions[t.Name()]
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if name == SchemaKey {
+			schema.Properties[name] = schemaKeySchema()
+			continue
+		}
+
+		property := g.fieldSchema(t, field, name)
+		if description, ok := descriptions[name]; ok {
+			property.Description = description
+		}
+		for _, rule := range strings.Split(field.Tag.Get("validate"), ",") {
+			switch {
+			case rule == "required":
+				schema.Required = append(schema.Required, name)
+			case rule == "email":
+				property.Format = "email"
+			case rule == "semver":
+				property.Pattern = `^v?\d+\.\d+\.\d+(-[0-9A-Za-z.-]+)?(\+[0-9A-Za-z.-]+)?$`
+			case rule == "min=1" && field.Type.Kind() == reflect.String:
+				property.MinLength = 1
+			}
+		}
+		schema.Properties[name] = property
+	}
+	return schema
+}
+
+// fieldSchema 生成字段的 schema，包管理器名称和 when 条件中取值固定的键使用枚举
+func (g *schemaGenerator) fieldSchema(parent reflect.Type, field reflect.StructField, name string) *JSONSchema {
+	switch {
+	case parent == reflect.TypeOf(PackageInfo{}) && name == "managers":
+		return &JSONSchema{
+			Type:                 "object",
+			PropertyNames:        &JSONSchema{Enum: KnownManagers},
+			AdditionalProperties: &JSONSchema{Type: "string", MinLength: 1},
+		}
+	case parent == reflect.TypeOf(PackageInfo{}) && name == "preferred_manager":
+		return &JSONSchema{Type: "string", Enum: KnownManagers}
+	case parent == reflect.TypeOf(PackagesConfig{}) && name == "package_managers":
+		return &JSONSchema{
+			Type:          "object",
+			PropertyNames: &JSONSchema{Enum: KnownManagers},
+			AdditionalProperties: &JSONSchema{
+				OneOf: []*JSONSchema{g.schemaFor(reflect.TypeOf(Manager{})), deleteMarkerSchema()},
+			},
+		}
+	case parent == reflect.TypeOf(PackagesConfig{}) && name == "include":
+		// include 也可以是单个路径
+		return &JSONSchema{OneOf: []*JSONSchema{{Type: "string"}, g.schemaFor(field.Type)}}
+	case field.Type == conditionValuesType && conditionEnums[name] != nil:
+		return conditionValuesSchema(conditionEnums[name])
+	}
+	return g.schemaFor(field.Type)
+}
+
+// conditionValuesSchema when 条件的取值：字符串或字符串数组；values 不为空时只允许这些值及其 ! 排除形式
+func conditionValuesSchema(values []string) *JSONSchema {
+	value := &JSONSchema{Type: "string"}
+	if values != nil {
+		for _, v := range values {
+			value.Enum = append(value.Enum, v, "!"+v)
+		}
+	}
+	return &JSONSchema{
+		OneOf: []*JSONSchema{value, {Type: "array", Items: value}},
+	}
+}
+
+// deleteMarkerSchema 删除标记 "$delete"
+func deleteMarkerSchema() *JSONSchema {
+	return &JSONSchema{Enum: []string{DeleteMarker}, Description: "从继承的配置中移除该项"}
+}
+
+// schemaKeySchema 配置文件中 $schema 引用的 schema
+func schemaKeySchema() *JSONSchema {
+	return &JSONSchema{Type: "string", Description: "JSON Schema 路径，供编辑器补全和校验，加载时忽略"}
+}
+
+// LinkSchema 在配置文件中写入指向 schemaPath 的引用，已有引用时更新，返回文件是否被修改
+//
+// JSON 写入顶层 "$schema" 键；YAML 写入 yaml-language-server 的注释，TOML 写入 Taplo 的 #:schema 注释。
+// 引用使用相对于配置文件的路径，其余内容保持不变。
+func LinkSchema(path, schemaPath string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	format, err := FormatFromPath(path)
+	if err != nil {
+		return false, err
+	}
+
+	ref, err := filepath.Rel(filepath.Dir(path), schemaPath)
+	if err != nil {
+		return false, err
+	}
+	ref = filepath.ToSlash(ref)
+	if !strings.HasPrefix(ref, ".") {
+		ref = "./" + ref
+	}
+
+	var linked []byte
+	switch format {
+	case FormatJSON:
+		linked, err = linkJSONSchema(data, ref)
+		if err != nil {
+			return false, &DecodeError{File: path, Message: err.Error()}
+		}
+	default:
+		linked = linkCommentSchema(data, format, ref)
+	}
+
+	if bytes.Equal(linked, data) {
+		return false, nil
+	}
+	if err := os.WriteFile(path, linked, 0644); err != nil {
+		return false, fmt.Errorf("写入 %s 失败: %w", path, err)
+	}
+	return true, nil
+}
+
+// linkJSONSchema 替换顶层 $schema 的值，没有时插入为第一个键（沿用文件的缩进）
+func linkJSONSchema(data []byte, ref string) ([]byte, error) {
+	value, _ := json.Marshal(ref)
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := decoder.Token(); err != nil || tok != json.Delim('{') {
+		return nil, fmt.Errorf("顶层必须是 JSON 对象")
+	}
+	open := int(decoder.InputOffset())
+
+	for decoder.More() {
+		tok, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		keyEnd := int(decoder.InputOffset())
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			return nil, err
+		}
+		if tok == SchemaKey {
+			valueEnd := int(decoder.InputOffset())
+			return slices.Concat(data[:keyEnd], []byte(": "), value, data[valueEnd:]), nil
+		}
+	}
+
+	// 插入到 { 之后，缩进与第一个键相同
+	rest := data[open:]
+	trimmed := bytes.TrimLeft(rest, " \t\r\n")
+	indent := "  "
+	if ws := rest[:len(rest)-len(trimmed)]; bytes.Contains(ws, []byte("\n")) {
+		indent = string(ws[bytes.LastIndexByte(ws, '\n')+1:])
+	}
+	entry := fmt.Sprintf("\n%s%q: %s", indent, SchemaKey, value)
+	if bytes.HasPrefix(trimmed, []byte("}")) {
+		return slices.Concat(data[:open], []byte(entry+"\n"), trimmed), nil
+	}
+	return slices.Concat(data[:open], []byte(entry+","), rest), nil
+}
+
+// schemaComments YAML 和 TOML 中引用 schema 的注释前缀（yaml-language-server 和 Taplo）
+var schemaComments = map[ConfigFormat]string{
+	FormatYAML: "# yaml-language-server: $schema=",
+	FormatTOML: "#:schema ",
+}
+
+// linkCommentSchema 替换引用 schema 的注释行，没有时插入到文件第一行
+func linkCommentSchema(data []byte, format ConfigFormat, ref string) []byte {
+	prefix := schemaComments[format]
+	lines := strings.SplitAfter(string(data), "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, prefix) {
+			lines[i] = prefix + ref + "\n"
+			return []byte(strings.Join(lines, ""))
+		}
+	}
+	return append([]byte(prefix+ref+"\n"), data...)
+}
+
+// commentSchemaRef 返回 YAML 或 TOML 文件中注释引用的 schema 路径
+func commentSchemaRef(data []byte, format ConfigFormat) string {
+	prefix, ok := schemaComments[format]
+	if !ok {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if ref, found := strings.CutPrefix(strings.TrimRight(line, "\r"), prefix); found {
+			return strings.TrimSpace(ref)
+		}
+	}
+	return ""
+}
+
+// takeSchemaRef 取出并移除顶层的 $schema 键
+func takeSchemaRef(object *orderedObject) string {
+	ref, ok := object.values[SchemaKey].(string)
+	if !ok {
+		return ""
+	}
+	delete(object.values, SchemaKey)
+	object.keys = slices.DeleteFunc(object.keys, func(key string) bool { return key == SchemaKey })
+	return ref
+}
@@ -0,0 +1,173 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// CheckSchema 按配置种类的 JSON Schema 检查文档的当前内容，返回所有不符合的项
+//
+// 只支持生成的 schema 用到的关键字: $ref、type、enum、pattern、format(email)、minLength、
+// properties、propertyNames、additionalProperties、items 和 oneOf。配置层和 extends/include
+// 使单个文件可以只包含部分配置，因此不检查 required，必填项由加载后的验证负责。
+func (d *ConfigDocument) CheckSchema(kind string) (*Report, error) {
+	schema, err := buildSchema(kind)
+	if err != nil {
+		return nil, err
+	}
+	checker := &schemaChecker{definitions: schema.Definitions, report: NewReport()}
+	checker.check(schema, d.root, "")
+	for i := range checker.report.Findings {
+		checker.report.Findings[i].File = d.Path
+	}
+	return checker.report, nil
+}
+
+// schemaChecker 按 schema 检查中间表示
+type schemaChecker struct {
+	definitions map[string]*JSONSchema
+	report      *Report
+}
+
+func (c *schemaChecker) check(schema *JSONSchema, value any, path string) {
+	if schema.Ref != "" {
+		c.check(c.definitions[strings.TrimPrefix(schema.Ref, "#/definitions/")], value, path)
+		return
+	}
+	if len(schema.OneOf) > 0 {
+		c.checkOneOf(schema.OneOf, value, path)
+		return
+	}
+
+	if schema.Type != "" && !schemaTypeMatches(schema.Type, value) {
+		c.report.Add(SeverityError, "schema", path, "应为 %s，实际为 %s", schemaTypeNames[schema.Type], schemaTypeNames[treeTypeName(value)])
+		return
+	}
+	if len(schema.Enum) > 0 {
+		if s, ok := value.(string); !ok || !slices.Contains(schema.Enum, s) {
+			c.report.Add(SeverityError, "schema", path, "无效的值 %v（可用: %s）", value, strings.Join(schema.Enum, ", "))
+			return
+		}
+	}
+
+	switch v := value.(type) {
+	case string:
+		if schema.MinLength > 0 && utf8.RuneCountInString(v) < schema.MinLength {
+			c.report.Add(SeverityError, "schema", path, "不能为空")
+		}
+		if schema.Pattern != "" && !regexp.MustCompile(schema.Pattern).MatchString(v) {
+			c.report.Add(SeverityError, "schema", path, "%q 格式不正确", v)
+		}
+		if schema.Format == "email" {
+			if _, err := mail.ParseAddress(v); err != nil {
+				c.report.Add(SeverityError, "schema", path, "%q 不是有效的邮箱地址", v)
+			}
+		}
+	case *orderedObject:
+		c.checkObject(schema, v, path)
+	case []any:
+		if schema.Items != nil {
+			for i, item := range v {
+				c.check(schema.Items, item, fmt.Sprintf("%s[%d]", path, i))
+			}
+		}
+	}
+}
+
+// checkObject 检查对象的属性名和各属性的值
+func (c *schemaChecker) checkObject(schema *JSONSchema, object *orderedObject, path string) {
+	for _, key := range object.keys {
+		childPath := joinPath(path, key)
+		if property, ok := schema.Properties[key]; ok {
+			c.check(property, object.values[key], childPath)
+			continue
+		}
+		if schema.PropertyNames != nil && len(schema.PropertyNames.Enum) > 0 && !slices.Contains(schema.PropertyNames.Enum, key) {
+			c.report.Add(SeverityError, "schema", childPath, "无效的键（可用: %s）", strings.Join(schema.PropertyNames.Enum, ", "))
+			continue
+		}
+		switch additional := schema.AdditionalProperties.(type) {
+		case bool:
+			if !additional {
+				c.report.Add(SeverityError, "schema", childPath, "未知字段")
+			}
+		case *JSONSchema:
+			c.check(additional, object.values[key], childPath)
+		}
+	}
+}
+
+// checkOneOf 值只需符合其中一种形式；都不符合时报告类型匹配的那种形式的问题
+func (c *schemaChecker) checkOneOf(options []*JSONSchema, value any, path string) {
+	var closest *Report
+	var types []string
+	for _, option := range options {
+		trial := &schemaChecker{definitions: c.definitions, report: NewReport()}
+		trial.check(option, value, path)
+		if len(trial.report.Findings) == 0 {
+			return
+		}
+		resolved := c.resolve(option)
+		if resolved.Type != "" {
+			types = append(types, schemaTypeNames[resolved.Type])
+		}
+		if closest == nil && (resolved.Type == "" || schemaTypeMatches(resolved.Type, value)) {
+			closest = trial.report
+		}
+	}
+	if closest != nil {
+		c.report.Findings = append(c.report.Findings, closest.Findings...)
+		return
+	}
+	c.report.Add(SeverityError, "schema", path, "应为 %s，实际为 %s", strings.Join(types, "或"), schemaTypeNames[treeTypeName(value)])
+}
+
+// resolve 返回 $ref 指向的定义
+func (c *schemaChecker) resolve(schema *JSONSchema) *JSONSchema {
+	if schema.Ref != "" {
+		return c.resolve(c.definitions[strings.TrimPrefix(schema.Ref, "#/definitions/")])
+	}
+	return schema
+}
+
+// schemaTypeNames JSON Schema 类型的中文名称
+var schemaTypeNames = map[string]string{
+	"object":  "对象",
+	"array":   "数组",
+	"string":  "字符串",
+	"boolean": "布尔值",
+	"integer": "整数",
+	"number":  "数字",
+	"null":    "null",
+}
+
+// treeTypeName 返回中间表示的值对应的 JSON Schema 类型
+func treeTypeName(value any) string {
+	switch v := value.(type) {
+	case *orderedObject:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case json.Number:
+		if strings.ContainsAny(string(v), ".eE") {
+			return "number"
+		}
+		return "integer"
+	}
+	return "null"
+}
+
+// schemaTypeMatches 判断值是否为 schema 类型（整数也是 number）
+func schemaTypeMatches(schemaType string, value any) bool {
+	actual := treeTypeName(value)
+	return actual == schemaType || (schemaType == "number" && actual == "integer")
+}
@@ -0,0 +1,169 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// generateTestSchema 生成配置的 JSON Schema 并解析为 map
+func generateTestSchema(t *testing.T, name string) map[string]any {
+	t.Helper()
+	data, err := GenerateSchema(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schema map[string]any
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("生成的 schema 不是合法的 JSON: %v", err)
+	}
+	return schema
+}
+
+// schemaAt 按键路径取 schema 中的值
+func schemaAt(t *testing.T, schema any, keys ...string) any {
+	t.Helper()
+	value := schema
+	for i, key := range keys {
+		switch v := value.(type) {
+		case map[string]any:
+			value = v[key]
+		case []any:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(v) {
+				t.Fatalf("schema 中没有 %s", strings.Join(keys[:i+1], "."))
+			}
+			value = v[index]
+		default:
+			t.Fatalf("schema 中没有 %s", strings.Join(keys[:i+1], "."))
+		}
+	}
+	return value
+}
+
+// stringList 将 JSON 数组转换为字符串切片
+func stringList(value any) []string {
+	items, _ := value.([]any)
+	list := make([]string, 0, len(items))
+	for _, item := range items {
+		s, _ := item.(string)
+		list = append(list, s)
+	}
+	return list
+}
+
+// TestGenerateSchema_PathValue 测试路径值的 schema 为字符串或平台/shell 名 -> 路径的对象
+func TestGenerateSchema_PathValue(t *testing.T) {
+	schema := generateTestSchema(t, "shared")
+
+	if ref := schemaAt(t, schema, "properties", "paths", "$ref"); ref != "#/definitions/PathsConfig" {
+		t.Fatalf("paths 应引用 PathsConfig，实际 %v", ref)
+	}
+	if ref := schemaAt(t, schema, "definitions", "PathsConfig", "properties", "projects", "$ref"); ref != "#/definitions/PathValue" {
+		t.Errorf("paths.projects 应引用 PathValue，实际 %v", ref)
+	}
+
+	oneOf, _ := schemaAt(t, schema, "definitions", "PathValue", "oneOf").([]any)
+	if len(oneOf) != 2 {
+		t.Fatalf("PathValue 应有两种形式，实际 %v", oneOf)
+	}
+	if typ := schemaAt(t, oneOf, "0", "type"); typ != "string" {
+		t.Errorf("PathValue 的第一种形式应为字符串，实际 %v", typ)
+	}
+	if typ := schemaAt(t, oneOf, "1", "type"); typ != "object" {
+		t.Errorf("PathValue 的第二种形式应为对象，实际 %v", typ)
+	}
+	if keys := stringList(schemaAt(t, oneOf, "1", "propertyNames", "enum")); !slices.Equal(keys, pathValueKeys) {
+		t.Errorf("PathValue 对象的键应为 %v，实际 %v", pathValueKeys, keys)
+	}
+	if typ := schemaAt(t, oneOf, "1", "additionalProperties", "type"); typ != "string" {
+		t.Errorf("PathValue 对象的值应为字符串，实际 %v", typ)
+	}
+
+	if _, err := GenerateSchema("unknown"); err == nil {
+		t.Error("未知的配置应返回错误")
+	}
+}
+
+// TestGenerateSchema_Managers 测试包管理器名称使用枚举
+func TestGenerateSchema_Managers(t *testing.T) {
+	schema := generateTestSchema(t, "packages")
+
+	tests := []struct {
+		name string
+		keys []string
+	}{
+		{"managers 的键", []string{"definitions", "PackageInfo", "properties", "managers", "propertyNames", "enum"}},
+		{"preferred_manager", []string{"definitions", "PackageInfo", "properties", "preferred_manager", "enum"}},
+		{"package_managers 的键", []string{"properties", "package_managers", "propertyNames", "enum"}},
+	}
+	for _, tt := range tests {
+		if managers := stringList(schemaAt(t, schema, tt.keys...)); !slices.Equal(managers, KnownManagers) {
+			t.Errorf("%s 应为 %v，实际 %v", tt.name, KnownManagers, managers)
+		}
+	}
+	if minLength := schemaAt(t, schema, "definitions", "PackageInfo", "properties", "managers", "additionalProperties", "minLength"); minLength != 1.0 {
+		t.Errorf("映射的包名不能为空，实际 minLength %v", minLength)
+	}
+}
+
+// TestLinkSchema 测试三种格式写入和更新 schema 引用，重复执行不修改文件
+func TestLinkSchema(t *testing.T) {
+	tests := []struct {
+		file    string
+		content string
+		want    string
+	}{
+		{
+			file:    "shared.json",
+			content: "{\n    \"user\": {\"name\": \"test\"}\n}\n",
+			want:    "{\n    \"$schema\": \"../schemas/shared.schema.json\",\n    \"user\": {\"name\": \"test\"}\n}\n",
+		},
+		{
+			file:    "shared.yaml",
+			content: "# 用户信息\nuser:\n  name: test\n",
+			want:    "# yaml-language-server: $schema=../schemas/shared.schema.json\n# 用户信息\nuser:\n  name: test\n",
+		},
+		{
+			file:    "shared.toml",
+			content: "[user]\nname = \"test\"\n",
+			want:    "#:schema ../schemas/shared.schema.json\n[user]\nname = \"test\"\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "configs", tt.file)
+			writeLayerFile(t, filepath.Dir(path), tt.file, tt.content)
+			schemaPath := filepath.Join(dir, "schemas", "shared.schema.json")
+
+			if changed, err := LinkSchema(path, schemaPath); err != nil || !changed {
+				t.Fatalf("首次写入应修改文件: changed=%v err=%v", changed, err)
+			}
+			if data, _ := os.ReadFile(path); string(data) != tt.want {
+				t.Errorf("写入结果不符，实际:\n%s", data)
+			}
+
+			if changed, err := LinkSchema(path, schemaPath); err != nil || changed {
+				t.Errorf("重复写入不应修改文件: changed=%v err=%v", changed, err)
+			}
+			if data, _ := os.ReadFile(path); string(data) != tt.want {
+				t.Errorf("重复写入后内容不应变化，实际:\n%s", data)
+			}
+
+			// 引用的 schema 位置变化时只替换原有引用
+			if changed, err := LinkSchema(path, filepath.Join(dir, "configs", "shared.schema.json")); err != nil || !changed {
+				t.Fatalf("更新引用应修改文件: changed=%v err=%v", changed, err)
+			}
+			data, _ := os.ReadFile(path)
+			if want := strings.Replace(tt.want, "../schemas/", "./", 1); string(data) != want {
+				t.Errorf("更新引用的结果不符，实际:\n%s", data)
+			}
+		})
+	}
+}
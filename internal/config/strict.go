@@ -0,0 +1,187 @@
+package config
+
+import (
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/bbq191/dotfiles-go/internal/platform"
+)
+
+// strictChecks 严格模式的额外检查：编辑器和工具是否在 PATH 中、包是否有可用的包管理器、
+// 未使用的代理方案和多个分类中重复的包。未知字段由 ConfigLoader.StrictDecode 检查。
+func (cv *ConfigValidator) strictChecks(config *DotfilesConfig, report *Report) {
+	cv.checkEditors(config, report)
+
+	if config.ZshConfig != nil {
+		cv.checkTools(config.ZshConfig, report)
+		checkProxyProfiles(config.ZshConfig.Proxy, report)
+	}
+
+	if config.Packages != nil {
+		checkDuplicatePackages(config.Packages, report)
+
+		info, err := platform.NewDetector().DetectPlatform()
+		if err != nil {
+			cv.logger.Warnf("检测平台失败，按所有平台检查包映射: %v", err)
+			info = nil
+		}
+		cv.checkUnmappedPackages(config.Packages.ForPlatform(info), report)
+	}
+}
+
+// checkEditors 检查 user.editor 和环境变量 EDITOR、VISUAL 中的编辑器命令
+func (cv *ConfigValidator) checkEditors(config *DotfilesConfig, report *Report) {
+	editors := map[string]string{"user.editor": config.User.Editor}
+	for _, name := range []string{"EDITOR", "VISUAL"} {
+		editors["environment."+name] = config.Environment[name]
+	}
+	for path, editor := range editors {
+		if command := commandName(editor); command != "" && !cv.onPath(command) {
+			report.Add(SeverityWarning, "missing-tool", path, "编辑器 %s 不在 PATH 中", command)
+		}
+	}
+}
+
+// checkTools 检查 modern_tools 和 external_tools 引用的命令
+func (cv *ConfigValidator) checkTools(zshConfig *ZshIntegrationConfig, report *Report) {
+	for name, replacement := range zshConfig.ModernTools.Replacements {
+		command := commandName(replacement.Tool)
+		if command == "" || cv.onPath(command) {
+			continue
+		}
+		path := "modern_tools.replacements." + name + ".tool"
+		if replacement.Fallback != "" {
+			report.Add(SeverityInfo, "missing-tool", path, "%s 不在 PATH 中，将使用 fallback: %s", command, replacement.Fallback)
+		} else {
+			report.Add(SeverityWarning, "missing-tool", path, "%s 不在 PATH 中，%s 的别名将不可用", command, name)
+		}
+	}
+
+	for name, initCommand := range zshConfig.ExternalTools.AutoInit {
+		command := initCommandName(initCommand)
+		if command == "" {
+			command = name
+		}
+		if !cv.onPath(command) {
+			report.Add(SeverityWarning, "missing-tool", "external_tools.auto_init."+name, "%s 不在 PATH 中", command)
+		}
+	}
+}
+
+// checkProxyProfiles 报告 active_profile 之外的代理方案（只能通过 proxy_on <名称> 手动启用）
+func checkProxyProfiles(proxy ProxyConfig, report *Report) {
+	if len(proxy.Profiles) == 0 {
+		return
+	}
+	if !proxy.Enabled {
+		report.Add(SeverityInfo, "unused-proxy-profile", "proxy.enabled", "代理未启用，%d 个代理方案均未使用", len(proxy.Profiles))
+		return
+	}
+	for name := range proxy.Profiles {
+		if name != proxy.ActiveProfile {
+			report.Add(SeverityInfo, "unused-proxy-profile", "proxy.profiles."+name,
+				"代理方案 %s 不是 active_profile（%s），只能通过 proxy_on %s 手动启用", name, proxy.ActiveProfile, name)
+		}
+	}
+}
+
+// checkDuplicatePackages 报告出现在多个分类中的包
+func checkDuplicatePackages(packages *PackagesConfig, report *Report) {
+	categories := make(map[string][]string)
+	for categoryName, category := range packages.Categories {
+		for packageName := range category.Packages {
+			categories[packageName] = append(categories[packageName], categoryName)
+		}
+	}
+	for packageName, names := range categories {
+		if len(names) < 2 {
+			continue
+		}
+		sort.Strings(names)
+		report.Add(SeverityWarning, "duplicate-package", "categories."+names[0]+".packages."+packageName,
+			"包 %s 同时出现在分类 %s 中", packageName, strings.Join(names, "、"))
+	}
+}
+
+// checkUnmappedPackages 报告在当前系统的可用包管理器中都没有映射的包
+//
+// 没有映射的包已由 validatePackageInfo 报告为错误，这里不再重复报告。
+func (cv *ConfigValidator) checkUnmappedPackages(packages *PackagesConfig, report *Report) {
+	available := make(map[string]bool)
+	anyAvailable := false
+	isAvailable := func(manager string) bool {
+		if ok, checked := available[manager]; checked {
+			return ok
+		}
+		binary := manager
+		if config, ok := packages.Managers[manager]; ok {
+			if command := commandName(strings.TrimPrefix(strings.TrimSpace(config.Command), "sudo ")); command != "" {
+				binary = command
+			}
+		}
+		available[manager] = cv.onPath(binary)
+		anyAvailable = anyAvailable || available[manager]
+		return available[manager]
+	}
+
+	type unmapped struct{ path, name, managers string }
+	var candidates []unmapped
+	for name := range packages.Managers {
+		isAvailable(name)
+	}
+	for categoryName, category := range packages.Categories {
+		for packageName, info := range category.Packages {
+			if len(info.Managers) == 0 {
+				continue
+			}
+			managers := make([]string, 0, len(info.Managers))
+			for manager := range info.Managers {
+				managers = append(managers, manager)
+			}
+			if slices.ContainsFunc(managers, isAvailable) {
+				continue
+			}
+			sort.Strings(managers)
+			candidates = append(candidates, unmapped{
+				path:     "categories." + categoryName + ".packages." + packageName,
+				name:     packageName,
+				managers: strings.Join(managers, ", "),
+			})
+		}
+	}
+
+	if !anyAvailable {
+		report.Add(SeverityWarning, "unmapped-package", "package_managers", "当前系统没有可用的包管理器，跳过包映射检查")
+		return
+	}
+	for _, c := range candidates {
+		report.Add(SeverityWarning, "unmapped-package", c.path, "包 %s 只映射到 %s，当前系统没有可用的包管理器能安装它", c.name, c.managers)
+	}
+}
+
+// onPath 检查命令是否在 PATH 中
+func (cv *ConfigValidator) onPath(command string) bool {
+	_, err := cv.lookPath(command)
+	return err == nil
+}
+
+// commandName 返回命令行的第一个字段（命令名）
+func commandName(commandLine string) string {
+	fields := strings.Fields(commandLine)
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
+
+// initCommandName 返回初始化命令实际调用的命令，如 eval "$(starship init zsh)" 中的 starship
+func initCommandName(initCommand string) string {
+	if _, inner, found := strings.Cut(initCommand, "$("); found {
+		return commandName(inner)
+	}
+	if command := commandName(initCommand); command != "eval" && command != "source" {
+		return command
+	}
+	return ""
+}
@@ -0,0 +1,130 @@
+package config
+
+import (
+	"io"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// newTestValidator 创建只认为 commands 中的命令在 PATH 中的验证器
+func newTestValidator(commands ...string) *ConfigValidator {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	cv := NewConfigValidator(logger)
+	cv.lookPath = func(name string) (string, error) {
+		for _, command := range commands {
+			if command == name {
+				return "/usr/bin/" + name, nil
+			}
+		}
+		return "", exec.ErrNotFound
+	}
+	return cv
+}
+
+func testConfig() *DotfilesConfig {
+	return &DotfilesConfig{
+		User:  UserConfig{Name: "test", Email: "test@example.com", Editor: "nvim"},
+		Paths: PathsConfig{Projects: PathValue{Default: "~/Projects"}, Dotfiles: PathValue{Default: "~/dotfiles"}},
+		ZshConfig: &ZshIntegrationConfig{
+			Proxy: ProxyConfig{
+				Enabled:       true,
+				ActiveProfile: "default",
+				Profiles:      map[string]ProxyProfile{"default": {}, "work": {}},
+			},
+			ModernTools: ModernToolsConfig{Replacements: map[string]ToolReplacement{
+				"ls":  {Tool: "eza", Fallback: "ls --color=auto"},
+				"cat": {Tool: "bat"},
+			}},
+			ExternalTools: ExternalToolsConfig{AutoInit: map[string]string{
+				"gh_copilot": `eval "$(gh copilot alias -- {shell})"`,
+			}},
+		},
+		Packages: &PackagesConfig{
+			Managers: map[string]Manager{"pacman": {Command: "sudo pacman"}},
+			Categories: map[string]Category{
+				"essential": {Packages: map[string]PackageInfo{
+					"git":   {Managers: map[string]string{"pacman": "git"}},
+					"notes": {Managers: map[string]string{"winget": "Notes.Notes"}},
+				}},
+				"dev": {Packages: map[string]PackageInfo{
+					"git": {Managers: map[string]string{"pacman": "git"}},
+				}},
+			},
+		},
+	}
+}
+
+// findingsByCheck 按检查项分组诊断结果的路径
+func findingsByCheck(report *Report) map[string][]string {
+	grouped := make(map[string][]string)
+	for _, finding := range report.Findings {
+		key := string(finding.Severity) + " " + finding.Check
+		grouped[key] = append(grouped[key], finding.Path)
+	}
+	return grouped
+}
+
+// TestDiagnoseStrict 测试严格模式的各项检查及其级别
+func TestDiagnoseStrict(t *testing.T) {
+	report := newTestValidator("pacman").Diagnose(testConfig(), true)
+	got := findingsByCheck(report)
+
+	expected := map[string]string{
+		"warning missing-tool":      "external_tools.auto_init.gh_copilot,modern_tools.replacements.cat.tool,user.editor",
+		"info missing-tool":         "modern_tools.replacements.ls.tool",
+		"info unused-proxy-profile": "proxy.profiles.work",
+		"warning duplicate-package": "categories.dev.packages.git",
+		"warning unmapped-package":  "categories.essential.packages.notes",
+	}
+	for key, paths := range expected {
+		if strings.Join(got[key], ",") != paths {
+			t.Errorf("%s: 期望 %s，实际 %v", key, paths, got[key])
+		}
+	}
+	if len(got) != len(expected) {
+		t.Errorf("诊断结果多余: %v", got)
+	}
+	if report.Err() != nil {
+		t.Errorf("严格检查不应产生错误: %v", report.Err())
+	}
+
+	if report := newTestValidator().Diagnose(testConfig(), false); len(report.Findings) != 0 {
+		t.Errorf("非严格模式不应执行严格检查: %v", report.Findings)
+	}
+}
+
+// TestDiagnoseStrict_EmptyManagers 测试没有映射的包只报告一次错误，不再报告为只映射到空列表
+func TestDiagnoseStrict_EmptyManagers(t *testing.T) {
+	config := testConfig()
+	config.Packages.Categories["essential"].Packages["tldr"] = PackageInfo{}
+
+	got := findingsByCheck(newTestValidator("pacman").Diagnose(config, true))
+	if paths := strings.Join(got["warning unmapped-package"], ","); paths != "categories.essential.packages.notes" {
+		t.Errorf("没有映射的包不应报告为 unmapped-package，实际 %s", paths)
+	}
+	if paths := strings.Join(got["error packages"], ","); paths != "categories.essential.packages.tldr" {
+		t.Errorf("没有映射的包应报告为错误，实际 %s", paths)
+	}
+}
+
+// TestDiagnoseCollectsErrors 测试所有错误都被收集而不是在第一个处停止
+func TestDiagnoseCollectsErrors(t *testing.T) {
+	config := testConfig()
+	config.User.Email = ""
+	config.Environment = map[string]string{"bad-name": "x"}
+	config.ZshConfig.Proxy.ActiveProfile = "missing"
+	config.Packages.Categories["essential"].Packages["broken"] = PackageInfo{Version: "not a version!"}
+
+	report := newTestValidator().Diagnose(config, false)
+	got := findingsByCheck(report)
+	if len(got["error field"]) != 1 || len(got["error environment"]) != 1 || len(got["error proxy"]) != 1 || len(got["error packages"]) != 1 {
+		t.Errorf("期望 4 类错误各一个，实际 %v", got)
+	}
+	if err := report.Err(); err == nil || !strings.Contains(err.Error(), "user.email") {
+		t.Errorf("Err 应包含所有错误，实际 %v", err)
+	}
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TimeoutConfig 包安装的默认超时配置（Go duration 格式，如 "20m"、"90s"，"0" 表示不限制）
+type TimeoutConfig struct {
+	Install     string `json:"install,omitempty"`      // 单个包的安装超时
+	PostInstall string `json:"post_install,omitempty"` // 单个 post_install 命令的超时
+	Batch       string `json:"batch,omitempty"`        // 整批安装的超时
+}
+
+// ParseTimeout 解析超时时长，空字符串返回 0
+func ParseTimeout(raw string) (time.Duration, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return 0, nil
+	}
+
+	timeout, err := time.ParseDuration(raw)
+	if err != nil {
+		return 0, fmt.Errorf("无效的超时时长 %q: %w", raw, err)
+	}
+	if timeout < 0 {
+		return 0, fmt.Errorf("超时时长不能为负数: %q", raw)
+	}
+	return timeout, nil
+}
+
+// validate 检查所有超时时长格式
+func (tc *TimeoutConfig) validate() error {
+	fields := []struct{ name, value string }{
+		{"install", tc.Install},
+		{"post_install", tc.PostInstall},
+		{"batch", tc.Batch},
+	}
+	for _, field := range fields {
+		if _, err := ParseTimeout(field.value); err != nil {
+			return fmt.Errorf("timeouts.%s: %w", field.name, err)
+		}
+	}
+	return nil
+}
@@ -3,18 +3,23 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+
+	"github.com/bbq191/dotfiles-go/internal/secrets"
 )
 
 // DotfilesConfig 主配置结构
 type DotfilesConfig struct {
+	Schema      string                `json:"$schema,omitempty"` // 编辑器使用的 JSON Schema 路径
 	Version     string                `json:"version,omitempty" validate:"omitempty,semver"`
 	User        UserConfig            `json:"user" validate:"required"`
 	Paths       PathsConfig           `json:"paths"`
 	Environment map[string]string     `json:"environment"`
 	Features    FeaturesConfig        `json:"features"`
-	ZshConfig   *ZshIntegrationConfig `json:"-"` // 从单独文件加载
-	Packages    *PackagesConfig       `json:"-"` // 从单独文件加载
-	Functions   *FunctionsConfig      `json:"-"` // 从单独文件加载
+	Profile     string                `json:"profile,omitempty"` // 默认使用的包清单 profile
+	ZshConfig   *ZshIntegrationConfig `json:"-"`                 // 从单独文件加载
+	Packages    *PackagesConfig       `json:"-"`                 // 从单独文件加载
+	Functions   *FunctionsConfig      `json:"-"`                 // 从单独文件加载
+	Secrets     *secrets.Store        `json:"-"`                 // age 加密的密钥，按需解密
 }
 
 // UserConfig 用户配置
@@ -84,9 +89,11 @@ func (p PathValue) Get(platform string) string {
 
 // FeaturesConfig 功能配置
 type FeaturesConfig struct {
-	GitIntegration    bool `json:"git_integration"`
-	NodejsManagement  bool `json:"nodejs_management"`
-	PythonManagement  bool `json:"python_management"`
+	GitIntegration   bool `json:"git_integration"`
+	NodejsManagement bool `json:"nodejs_management"`
+	PythonManagement bool `json:"python_management"`
+	// 以下三项已废弃: 从未生效（由 zsh_integration 的 performance 控制），格式 1.1.0 起由迁移删除，
+	// 保留字段以便继续加载旧版本的配置文件
 	CompletionCache   bool `json:"completion_cache,omitempty"`
 	AsyncLoading      bool `json:"async_loading,omitempty"`
 	PathDeduplication bool `json:"path_deduplication,omitempty"`
@@ -94,6 +101,8 @@ type FeaturesConfig struct {
 
 // ZshIntegrationConfig Zsh 集成配置（从 zsh_integration.json 加载）
 type ZshIntegrationConfig struct {
+	Schema                  string                          `json:"$schema,omitempty"` // 编辑器使用的 JSON Schema 路径
+	Version                 string                          `json:"version,omitempty" validate:"omitempty,semver"`
 	Proxy                   ProxyConfig                     `json:"proxy"`
 	XDGDirectories          XDGConfig                       `json:"xdg_directories"`
 	HistoryAdvanced         HistoryConfig                   `json:"history_advanced"`
@@ -213,8 +222,26 @@ type PerformanceConfig struct {
 
 // PackagesConfig 包配置（从包文件加载）
 type PackagesConfig struct {
+	Schema     string              `json:"$schema,omitempty"` // 编辑器使用的 JSON Schema 路径
+	Version    string              `json:"version,omitempty" validate:"omitempty,semver"`
+	Extends    string              `json:"extends,omitempty"` // 继承的包配置文件（相对路径），加载时合并
+	Include    []string            `json:"include,omitempty"` // 额外合并的包配置文件，按顺序覆盖 extends
 	Categories map[string]Category `json:"categories"`
 	Managers   map[string]Manager  `json:"package_managers"`
+	AURReview  *AURReviewConfig    `json:"aur_review,omitempty"`
+	Timeouts   *TimeoutConfig      `json:"timeouts,omitempty"` // 默认安装超时
+	Profiles   map[string]Profile  `json:"profiles,omitempty"` // 按机器角色组合的包选择方案
+}
+
+// AURReviewConfig AUR PKGBUILD 审查配置
+type AURReviewConfig struct {
+	TrustedPackages []TrustedAURPackage `json:"trusted_packages,omitempty"` // 免审查的包和维护者
+}
+
+// TrustedAURPackage 信任的AUR包（包名和维护者需同时匹配）
+type TrustedAURPackage struct {
+	Name       string `json:"name"`
+	Maintainer string `json:"maintainer"`
 }
 
 // Category 包分类
@@ -222,15 +249,26 @@ type Category struct {
 	Description string                 `json:"description"`
 	Priority    int                    `json:"priority"`
 	Packages    map[string]PackageInfo `json:"packages"`
+	When        *Condition             `json:"when,omitempty"`    // 平台条件，不满足时整个分类被忽略
+	Delete      bool                   `json:"$delete,omitempty"` // 删除标记：从继承的配置中移除该分类
 }
 
 // PackageInfo 包信息
 type PackageInfo struct {
-	Description string            `json:"description"`
-	Tags        []string          `json:"tags,omitempty"`
-	Managers    map[string]string `json:"managers"` // 包管理器 -> 包名映射
-	Optional    bool              `json:"optional,omitempty"`
-	PostInstall []string          `json:"post_install,omitempty"`
+	Description      string            `json:"description"`
+	Tags             []string          `json:"tags,omitempty"`
+	Managers         map[string]string `json:"managers"`                    // 包管理器 -> 包名映射
+	PreferredManager string            `json:"preferred_manager,omitempty"` // 首选包管理器，需在 managers 中有映射
+	Optional         bool              `json:"optional,omitempty"`
+	PostInstall      []string          `json:"post_install,omitempty"`
+	Version          string            `json:"version,omitempty"` // 版本约束（semver 范围或 pacman pkgver-pkgrel）
+
+	Timeout            string `json:"timeout,omitempty"`              // 安装超时，覆盖 timeouts.install
+	PostInstallTimeout string `json:"post_install_timeout,omitempty"` // post_install 命令超时，覆盖 timeouts.post_install
+
+	When *Condition `json:"when,omitempty"` // 平台条件，不满足时安装和状态检查忽略该包
+
+	Delete bool `json:"$delete,omitempty"` // 删除标记：从继承的配置中移除该包
 }
 
 // Manager 包管理器配置
@@ -239,6 +277,7 @@ type Manager struct {
 	InstallArgs []string `json:"install_args"`
 	Priority    int      `json:"priority"`
 	Parallel    bool     `json:"parallel"`
+	Delete      bool     `json:"$delete,omitempty"` // 删除标记：从继承的配置中移除该包管理器
 }
 
 // FunctionsConfig 函数配置（从 advanced_functions.json 加载）
@@ -252,4 +291,6 @@ type FunctionInfo struct {
 	Bash        string `json:"bash,omitempty"`
 	Zsh         string `json:"zsh,omitempty"`
 	PowerShell  string `json:"powershell,omitempty"`
+
+	When *Condition `json:"when,omitempty"` // 平台条件，不满足时生成配置时忽略该函数
 }
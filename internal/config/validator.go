@@ -3,7 +3,9 @@ package config
 import (
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"reflect"
 	"regexp"
 	"strings"
 
@@ -15,14 +17,16 @@ import (
 type ConfigValidator struct {
 	validator *validator.Validate
 	logger    *logrus.Logger
+	lookPath  func(string) (string, error) // 严格模式检查命令是否在 PATH 中
 }
 
 // NewConfigValidator 创建新的配置验证器
 func NewConfigValidator(logger *logrus.Logger) *ConfigValidator {
-	v := validator.New()
+	v := newJSONValidator()
 	cv := &ConfigValidator{
 		validator: v,
 		logger:    logger,
+		lookPath:  exec.LookPath,
 	}
 
 	// 注册自定义验证规则
@@ -52,56 +56,69 @@ func (cv *ConfigValidator) registerCustomValidators() {
 	cv.validator.RegisterValidation("packagename", cv.validatePackageName)
 }
 
-// ValidateConfig 验证完整配置
+// ValidateConfig 验证完整配置，返回所有错误（多个错误合并为一个）
 func (cv *ConfigValidator) ValidateConfig(config *DotfilesConfig) error {
+	return cv.Diagnose(config, false).Err()
+}
+
+// Diagnose 验证配置并收集所有问题；strict 为 true 时额外执行严格检查（见 strictChecks）
+func (cv *ConfigValidator) Diagnose(config *DotfilesConfig, strict bool) *Report {
 	cv.logger.Debug("开始配置验证")
+	report := NewReport()
 
 	// 结构体标签验证
 	if err := cv.validator.Struct(config); err != nil {
-		return cv.formatValidationError(err)
+		cv.addValidationErrors(report, err)
 	}
 
 	// 业务逻辑验证
-	if err := cv.validateBusinessLogic(config); err != nil {
-		return err
+	cv.validateBusinessLogic(config, report)
+
+	if strict {
+		cv.strictChecks(config, report)
 	}
 
-	cv.logger.Debug("配置验证通过")
-	return nil
+	report.Sort()
+	cv.logger.Debugf("配置验证完成: %s", report)
+	return report
 }
 
 // validateBusinessLogic 业务逻辑验证
-func (cv *ConfigValidator) validateBusinessLogic(config *DotfilesConfig) error {
+func (cv *ConfigValidator) validateBusinessLogic(config *DotfilesConfig, report *Report) {
 	// 验证用户配置
 	if err := cv.validateUserConfig(config.User); err != nil {
-		return fmt.Errorf("用户配置验证失败: %w", err)
+		report.Add(SeverityError, "user", "user", "%v", err)
 	}
 
 	// 验证路径配置
-	if err := cv.validatePathsConfig(config.Paths); err != nil {
-		return fmt.Errorf("路径配置验证失败: %w", err)
-	}
+	cv.validatePathsConfig(config.Paths, report)
 
 	// 验证环境变量配置
-	if err := cv.validateEnvironmentConfig(config.Environment); err != nil {
-		return fmt.Errorf("环境变量配置验证失败: %w", err)
-	}
+	cv.validateEnvironmentConfig(config.Environment, report)
 
 	// 验证 Zsh 配置
 	if config.ZshConfig != nil {
-		if err := cv.validateZshConfig(config.ZshConfig); err != nil {
-			return fmt.Errorf("ZSH 配置验证失败: %w", err)
+		cv.validateZshConfig(config.ZshConfig, report)
+	}
+
+	// 验证函数配置
+	if config.Functions != nil {
+		for name, fn := range config.Functions.Functions {
+			if err := fn.When.Validate(); err != nil {
+				report.Add(SeverityError, "functions", name+".when", "%v", err)
+			}
 		}
 	}
 
 	// 验证包配置
 	if config.Packages != nil {
-		if err := cv.validatePackagesConfig(config.Packages); err != nil {
-			return fmt.Errorf("包配置验证失败: %w", err)
+		cv.validatePackagesConfig(config.Packages, report)
+		if config.Profile != "" {
+			if _, ok := config.Packages.Profiles[config.Profile]; !ok {
+				report.Add(SeverityError, "packages", "profile", "profile %s 未在包清单中定义", config.Profile)
+			}
 		}
 	}
-
-	return nil
 }
 
 // validateUserConfig 验证用户配置
@@ -125,7 +142,7 @@ func (cv *ConfigValidator) validateUserConfig(user UserConfig) error {
 }
 
 // validatePathsConfig 验证路径配置
-func (cv *ConfigValidator) validatePathsConfig(paths PathsConfig) error {
+func (cv *ConfigValidator) validatePathsConfig(paths PathsConfig, report *Report) {
 	pathFields := map[string]PathValue{
 		"projects":  paths.Projects,
 		"dotfiles":  paths.Dotfiles,
@@ -135,11 +152,9 @@ func (cv *ConfigValidator) validatePathsConfig(paths PathsConfig) error {
 
 	for name, pathValue := range pathFields {
 		if err := cv.validatePathValue(name, pathValue); err != nil {
-			return err
+			report.Add(SeverityError, "paths", "paths."+name, "%v", err)
 		}
 	}
-
-	return nil
 }
 
 // validatePathValue 验证路径值
@@ -202,67 +217,58 @@ func (cv *ConfigValidator) validateSinglePath(path string) error {
 }
 
 // validateEnvironmentConfig 验证环境变量配置
-func (cv *ConfigValidator) validateEnvironmentConfig(env map[string]string) error {
+func (cv *ConfigValidator) validateEnvironmentConfig(env map[string]string, report *Report) {
 	for key, value := range env {
 		// 验证环境变量名格式
 		if !cv.isValidEnvVarName(key) {
-			return fmt.Errorf("无效的环境变量名: %s", key)
+			report.Add(SeverityError, "environment", "environment."+key, "无效的环境变量名: %s", key)
+			continue
 		}
 
 		// 验证特殊环境变量
 		if err := cv.validateSpecialEnvVar(key, value); err != nil {
-			return err
+			report.Add(SeverityError, "environment", "environment."+key, "%v", err)
 		}
 	}
-
-	return nil
 }
 
 // validateZshConfig 验证 Zsh 配置
-func (cv *ConfigValidator) validateZshConfig(zshConfig *ZshIntegrationConfig) error {
+func (cv *ConfigValidator) validateZshConfig(zshConfig *ZshIntegrationConfig, report *Report) {
 	// 验证代理配置
-	if err := cv.validateProxyConfig(zshConfig.Proxy); err != nil {
-		return fmt.Errorf("代理配置验证失败: %w", err)
-	}
+	cv.validateProxyConfig(zshConfig.Proxy, report)
 
 	// 验证 XDG 配置
 	if zshConfig.XDGDirectories.Enabled {
-		if err := cv.validateXDGConfig(zshConfig.XDGDirectories); err != nil {
-			return fmt.Errorf("XDG 配置验证失败: %w", err)
-		}
+		cv.validateXDGConfig(zshConfig.XDGDirectories, report)
 	}
 
 	// 验证版本管理器配置
 	for name, vm := range zshConfig.VersionManagers {
 		if err := cv.validateVersionManager(name, vm); err != nil {
-			return fmt.Errorf("版本管理器 %s 配置验证失败: %w", name, err)
+			report.Add(SeverityError, "zsh", "version_managers."+name, "%v", err)
 		}
 	}
-
-	return nil
 }
 
 // validateProxyConfig 验证代理配置
-func (cv *ConfigValidator) validateProxyConfig(proxy ProxyConfig) error {
+func (cv *ConfigValidator) validateProxyConfig(proxy ProxyConfig, report *Report) {
 	if !proxy.Enabled {
-		return nil
+		return
 	}
 
 	// 验证活动配置文件存在
 	if proxy.ActiveProfile != "" && !strings.Contains(proxy.ActiveProfile, "$") {
 		if _, exists := proxy.Profiles[proxy.ActiveProfile]; !exists {
-			return fmt.Errorf("活动代理配置文件 %s 不存在", proxy.ActiveProfile)
+			report.Add(SeverityError, "proxy", "proxy.active_profile", "活动代理配置文件 %s 不存在", proxy.ActiveProfile)
 		}
 	}
 
 	// 验证代理配置文件
 	for name, profile := range proxy.Profiles {
 		if err := cv.validateProxyProfile(name, profile); err != nil {
-			return err
+			report.Add(SeverityError, "proxy", "proxy.profiles."+name, "%v", err)
 		}
 	}
-
-	return nil
 }
 
 // validateProxyProfile 验证代理配置文件
@@ -285,7 +291,7 @@ func (cv *ConfigValidator) validateProxyProfile(name string, profile ProxyProfil
 }
 
 // validateXDGConfig 验证 XDG 配置
-func (cv *ConfigValidator) validateXDGConfig(xdg XDGConfig) error {
+func (cv *ConfigValidator) validateXDGConfig(xdg XDGConfig, report *Report) {
 	xdgPaths := map[string]PathValue{
 		"config_home": xdg.ConfigHome,
 		"data_home":   xdg.DataHome,
@@ -297,11 +303,9 @@ func (cv *ConfigValidator) validateXDGConfig(xdg XDGConfig) error {
 
 	for name, pathValue := range xdgPaths {
 		if err := cv.validatePathValue("xdg."+name, pathValue); err != nil {
-			return err
+			report.Add(SeverityError, "xdg", "xdg_directories."+name, "%v", err)
 		}
 	}
-
-	return nil
 }
 
 // validateVersionManager 验证版本管理器配置
@@ -337,22 +341,41 @@ func (cv *ConfigValidator) validateVersionManager(name string, vm VersionManager
 }
 
 // validatePackagesConfig 验证包配置
-func (cv *ConfigValidator) validatePackagesConfig(packages *PackagesConfig) error {
+func (cv *ConfigValidator) validatePackagesConfig(packages *PackagesConfig, report *Report) {
 	// 验证包管理器配置
 	for name, manager := range packages.Managers {
 		if err := cv.validatePackageManager(name, manager); err != nil {
-			return err
+			report.Add(SeverityError, "packages", "package_managers."+name, "%v", err)
 		}
 	}
 
 	// 验证包分类
 	for categoryName, category := range packages.Categories {
-		if err := cv.validatePackageCategory(categoryName, category); err != nil {
-			return err
+		cv.validatePackageCategory(categoryName, category, report)
+	}
+
+	// 验证AUR审查信任列表
+	if packages.AURReview != nil {
+		for i, trusted := range packages.AURReview.TrustedPackages {
+			if trusted.Name == "" || trusted.Maintainer == "" {
+				report.Add(SeverityError, "packages", fmt.Sprintf("aur_review.trusted_packages[%d]", i), "必须同时指定 name 和 maintainer")
+			}
 		}
 	}
 
-	return nil
+	// 验证 profile（分类、包引用和继承关系）
+	for _, name := range packages.ProfileNames() {
+		if _, err := packages.ResolveProfile(name); err != nil {
+			report.Add(SeverityError, "packages", "profiles."+name, "%v", err)
+		}
+	}
+
+	// 验证默认超时
+	if packages.Timeouts != nil {
+		if err := packages.Timeouts.validate(); err != nil {
+			report.Add(SeverityError, "packages", "timeouts", "%v", err)
+		}
+	}
 }
 
 // validatePackageManager 验证包管理器配置
@@ -369,14 +392,17 @@ func (cv *ConfigValidator) validatePackageManager(name string, manager Manager)
 }
 
 // validatePackageCategory 验证包分类
-func (cv *ConfigValidator) validatePackageCategory(categoryName string, category Category) error {
+func (cv *ConfigValidator) validatePackageCategory(categoryName string, category Category, report *Report) {
+	path := "categories." + categoryName
+	if err := category.When.Validate(); err != nil {
+		report.Add(SeverityError, "packages", path+".when", "%v", err)
+	}
+
 	for packageName, packageInfo := range category.Packages {
 		if err := cv.validatePackageInfo(packageName, packageInfo); err != nil {
-			return fmt.Errorf("分类 %s 中的包 %s 验证失败: %w", categoryName, packageName, err)
+			report.Add(SeverityError, "packages", path+".packages."+packageName, "%v", err)
 		}
 	}
-
-	return nil
 }
 
 // validatePackageInfo 验证包信息
@@ -391,6 +417,29 @@ func (cv *ConfigValidator) validatePackageInfo(packageName string, info PackageI
 		}
 	}
 
+	if info.PreferredManager != "" {
+		if _, ok := info.Managers[info.PreferredManager]; !ok {
+			return fmt.Errorf("包 %s 的首选管理器 %s 没有包名映射", packageName, info.PreferredManager)
+		}
+	}
+
+	if info.Version != "" {
+		if _, err := ParseVersionConstraint(info.Version); err != nil {
+			return fmt.Errorf("包 %s 的版本约束无效: %w", packageName, err)
+		}
+	}
+
+	if _, err := ParseTimeout(info.Timeout); err != nil {
+		return fmt.Errorf("包 %s 的 timeout 无效: %w", packageName, err)
+	}
+	if _, err := ParseTimeout(info.PostInstallTimeout); err != nil {
+		return fmt.Errorf("包 %s 的 post_install_timeout 无效: %w", packageName, err)
+	}
+
+	if err := info.When.Validate(); err != nil {
+		return fmt.Errorf("包 %s 的条件无效: %w", packageName, err)
+	}
+
 	return nil
 }
 
@@ -490,48 +539,64 @@ func (cv *ConfigValidator) validateShellCommand(command string) error {
 	return nil
 }
 
-// formatValidationError 格式化验证错误
-func (cv *ConfigValidator) formatValidationError(err error) error {
+// addValidationErrors 将结构体标签验证错误逐个添加到报告
+func (cv *ConfigValidator) addValidationErrors(report *Report, err error) {
 	validationErrors, ok := err.(validator.ValidationErrors)
 	if !ok {
-		return fmt.Errorf("验证错误格式异常: %w", err)
+		report.Add(SeverityError, "field", "", "验证错误格式异常: %v", err)
+		return
 	}
-	var messages []string
 
 	for _, fieldErr := range validationErrors {
-		fieldName := cv.getFieldDisplayName(fieldErr)
-
+		var message string
 		switch fieldErr.Tag() {
 		case "required":
-			messages = append(messages, fmt.Sprintf("字段 %s 是必需的", fieldName))
+			message = "字段是必需的"
 		case "email":
-			messages = append(messages, fmt.Sprintf("字段 %s 必须是有效的邮箱地址", fieldName))
+			message = "必须是有效的邮箱地址"
 		case "min":
-			messages = append(messages, fmt.Sprintf("字段 %s 长度不能少于 %s", fieldName, fieldErr.Param()))
+			message = fmt.Sprintf("长度不能少于 %s", fieldErr.Param())
 		case "semver":
-			messages = append(messages, fmt.Sprintf("字段 %s 必须符合语义版本格式", fieldName))
+			message = "必须符合语义版本格式"
 		case "validpath":
-			messages = append(messages, fmt.Sprintf("字段 %s 必须是有效的路径", fieldName))
+			message = "必须是有效的路径"
 		case "command":
-			messages = append(messages, fmt.Sprintf("字段 %s 必须是有效的命令", fieldName))
+			message = "必须是有效的命令"
 		case "envvar":
-			messages = append(messages, fmt.Sprintf("字段 %s 必须是有效的环境变量名", fieldName))
+			message = "必须是有效的环境变量名"
 		case "proxyurl":
-			messages = append(messages, fmt.Sprintf("字段 %s 必须是有效的代理 URL", fieldName))
+			message = "必须是有效的代理 URL"
 		case "packagename":
-			messages = append(messages, fmt.Sprintf("字段 %s 必须是有效的包名", fieldName))
+			message = "必须是有效的包名"
 		default:
-			messages = append(messages, fmt.Sprintf("字段 %s 验证失败: %s", fieldName, fieldErr.Tag()))
+			message = fmt.Sprintf("验证失败: %s", fieldErr.Tag())
 		}
+		report.Add(SeverityError, "field", cv.getFieldDisplayName(fieldErr), "%s", message)
 	}
-
-	return fmt.Errorf("配置验证失败:\n  - %s", strings.Join(messages, "\n  - "))
 }
 
-// getFieldDisplayName 获取字段显示名称
+// getFieldDisplayName 获取字段显示名称（JSON 路径）
 func (cv *ConfigValidator) getFieldDisplayName(fieldErr validator.FieldError) string {
-	// 可以在这里添加字段名称映射逻辑
-	return fieldErr.Field()
+	return validationPath(fieldErr)
+}
+
+// newJSONValidator 创建使用 JSON 键名报告字段的验证器
+func newJSONValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	return v
+}
+
+// validationPath 将验证错误的命名空间（DotfilesConfig.user.email）转换为 JSON 路径（user.email）
+func validationPath(fieldErr validator.FieldError) string {
+	_, path, _ := strings.Cut(fieldErr.Namespace(), ".")
+	return path
 }
 
 // isWindowsPath 检查是否是 Windows 路径格式
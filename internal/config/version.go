@@ -0,0 +1,106 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// pacmanVersionRegex 匹配 pacman 的 [epoch:]pkgver-pkgrel 精确版本
+var pacmanVersionRegex = regexp.MustCompile(`^(\d+:)?[A-Za-z0-9._+]+-\d+(\.\d+)?$`)
+
+// prereleaseRegex 匹配 semver 约束中带预发布部分的版本（如 1.2.3-1，不包括 "1.2 - 1.4" 范围）
+var prereleaseRegex = regexp.MustCompile(`\d-[0-9A-Za-z]`)
+
+// VersionConstraint 包版本约束
+//
+// 支持两种写法：
+//   - semver 范围，例如 ">=0.10", "~1.2", "^2.0.0 <2.5"
+//   - pacman 精确版本 pkgver-pkgrel，例如 "0.10.2-1" 或 "2:9.1.0-1"
+//
+// 不带运算符、以 -数字 结尾的版本按 pacman 的 pkgver-pkgrel 处理；
+// semver 预发布版本（如 1.2.3-1）需要写运算符，例如 "=1.2.3-1" 或 ">=1.2.3-1"。
+type VersionConstraint struct {
+	Raw      string
+	Exact    string // pacman 精确版本（为空表示 semver 范围）
+	semverCs *semver.Constraints
+	// prerelease 约束中写了预发布版本，此时已安装版本的 -后缀按 semver 预发布比较，而不是作为 pkgrel 去掉
+	prerelease bool
+}
+
+// ParseVersionConstraint 解析版本约束字符串
+func ParseVersionConstraint(raw string) (*VersionConstraint, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, fmt.Errorf("版本约束不能为空")
+	}
+
+	if pacmanVersionRegex.MatchString(raw) {
+		return &VersionConstraint{Raw: raw, Exact: raw}, nil
+	}
+
+	cs, err := semver.NewConstraint(raw)
+	if err != nil {
+		return nil, fmt.Errorf("无效的版本约束 %q: %w", raw, err)
+	}
+	return &VersionConstraint{Raw: raw, semverCs: cs, prerelease: prereleaseRegex.MatchString(raw)}, nil
+}
+
+// IsExact 是否为精确版本约束
+func (vc *VersionConstraint) IsExact() bool {
+	return vc.Exact != ""
+}
+
+// ExactVersion 返回可直接传给包管理器的精确版本，范围约束返回空字符串
+func (vc *VersionConstraint) ExactVersion() string {
+	if vc.IsExact() {
+		return vc.Exact
+	}
+	// 形如 "=1.2.3" 或 "1.2.3" 的 semver 约束也视为精确版本
+	trimmed := strings.TrimPrefix(vc.Raw, "=")
+	if v, err := semver.StrictNewVersion(strings.TrimSpace(trimmed)); err == nil {
+		return v.Original()
+	}
+	return ""
+}
+
+// Check 检查版本是否满足约束
+func (vc *VersionConstraint) Check(version string) (bool, error) {
+	version = strings.TrimSpace(version)
+	if version == "" {
+		return false, fmt.Errorf("版本为空")
+	}
+
+	if vc.IsExact() {
+		return version == vc.Exact, nil
+	}
+
+	comparable := normalizePackageVersion(version)
+	if vc.prerelease {
+		// 只去掉 epoch，保留 -后缀作为预发布版本
+		comparable = version[strings.Index(version, ":")+1:]
+	}
+	v, err := semver.NewVersion(comparable)
+	if err != nil {
+		return false, fmt.Errorf("无法将版本 %q 与约束 %q 比较: %w", version, vc.Raw, err)
+	}
+	return vc.semverCs.Check(v), nil
+}
+
+// String 返回约束的原始写法
+func (vc *VersionConstraint) String() string {
+	return vc.Raw
+}
+
+// normalizePackageVersion 去除 pacman 版本中的 epoch 和 pkgrel，便于按 semver 比较
+func normalizePackageVersion(version string) string {
+	if idx := strings.Index(version, ":"); idx >= 0 {
+		version = version[idx+1:]
+	}
+	if pacmanVersionRegex.MatchString(version) {
+		version = version[:strings.LastIndex(version, "-")]
+	}
+	return version
+}
@@ -0,0 +1,399 @@
+package installer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/bbq191/dotfiles-go/internal/config"
+	"github.com/bbq191/dotfiles-go/internal/xdg"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultAURBaseURL AUR 官方地址
+const DefaultAURBaseURL = "https://aur.archlinux.org"
+
+// AURRPCInfo AUR RPC info 接口返回的包元数据
+type AURRPCInfo struct {
+	Name        string `json:"Name"`
+	PackageBase string `json:"PackageBase"`
+	Version     string `json:"Version"`
+	Maintainer  string `json:"Maintainer"`
+	URLPath     string `json:"URLPath"`
+}
+
+// AURClient AUR RPC 客户端
+type AURClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewAURClient 创建AUR客户端，baseURL 为空时使用官方地址
+func NewAURClient(baseURL string) *AURClient {
+	if baseURL == "" {
+		baseURL = DefaultAURBaseURL
+	}
+	return &AURClient{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Info 查询AUR包元数据，包不存在时返回 nil
+func (c *AURClient) Info(ctx context.Context, packageName string) (*AURRPCInfo, error) {
+	endpoint := fmt.Sprintf("%s/rpc/v5/info?arg[]=%s", c.baseURL, url.QueryEscape(packageName))
+
+	var response struct {
+		ResultCount int          `json:"resultcount"`
+		Results     []AURRPCInfo `json:"results"`
+		Type        string       `json:"type"`
+		Error       string       `json:"error"`
+	}
+
+	body, err := c.get(ctx, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("解析AUR RPC响应失败: %w", err)
+	}
+	if response.Type == "error" {
+		return nil, fmt.Errorf("AUR RPC返回错误: %s", response.Error)
+	}
+
+	for _, result := range response.Results {
+		if result.Name == packageName {
+			info := result
+			return &info, nil
+		}
+	}
+	return nil, nil
+}
+
+// FetchPKGBUILD 获取包基础的PKGBUILD内容
+func (c *AURClient) FetchPKGBUILD(ctx context.Context, packageBase string) (string, error) {
+	endpoint := fmt.Sprintf("%s/cgit/aur.git/plain/PKGBUILD?h=%s", c.baseURL, url.QueryEscape(packageBase))
+	body, err := c.get(ctx, endpoint)
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
+
+// get 执行HTTP GET请求
+func (c *AURClient) get(ctx context.Context, endpoint string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求AUR失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("请求AUR失败: %s 返回 %d", endpoint, resp.StatusCode)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+// AURApproval 已批准的PKGBUILD记录
+type AURApproval struct {
+	Package    string    `json:"package"`
+	Maintainer string    `json:"maintainer"`
+	Version    string    `json:"version"`
+	SHA256     string    `json:"sha256"`
+	ApprovedAt time.Time `json:"approved_at"`
+	Trusted    bool      `json:"trusted"` // 是否通过白名单自动批准
+}
+
+// ReviewStore 审查记录存储（位于 XDG_STATE_HOME/dotfiles/aur-review）
+type ReviewStore struct {
+	dir string
+}
+
+// NewReviewStore 创建审查记录存储
+func NewReviewStore(dir string) *ReviewStore {
+	return &ReviewStore{dir: dir}
+}
+
+// DefaultReviewStoreDir 获取默认审查记录目录
+func DefaultReviewStoreDir(logger *logrus.Logger) (string, error) {
+	stateHome, err := xdg.NewManager(logger, runtime.GOOS).GetXDGPath(xdg.StateHome)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(stateHome, "dotfiles", "aur-review"), nil
+}
+
+// validatePackageName 包名用作记录目录名，拒绝可能逃出记录目录的名称
+func validatePackageName(packageName string) error {
+	if packageName == "" || packageName == "." || strings.Contains(packageName, "/") || strings.Contains(packageName, `\`) || strings.Contains(packageName, "..") {
+		return fmt.Errorf("无效的AUR包名: %q", packageName)
+	}
+	return nil
+}
+
+// Load 读取包的批准记录和上次批准的PKGBUILD，不存在时返回 nil
+func (s *ReviewStore) Load(packageName string) (*AURApproval, string, error) {
+	if err := validatePackageName(packageName); err != nil {
+		return nil, "", err
+	}
+	pkgDir := filepath.Join(s.dir, packageName)
+
+	data, err := os.ReadFile(filepath.Join(pkgDir, "approval.json"))
+	if os.IsNotExist(err) {
+		return nil, "", nil
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	var approval AURApproval
+	if err := json.Unmarshal(data, &approval); err != nil {
+		return nil, "", fmt.Errorf("解析审查记录失败: %w", err)
+	}
+
+	pkgbuild, err := os.ReadFile(filepath.Join(pkgDir, "PKGBUILD"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, "", err
+	}
+
+	return &approval, string(pkgbuild), nil
+}
+
+// Save 保存批准记录和对应的PKGBUILD
+func (s *ReviewStore) Save(approval AURApproval, pkgbuild string) error {
+	if err := validatePackageName(approval.Package); err != nil {
+		return err
+	}
+	pkgDir := filepath.Join(s.dir, approval.Package)
+	if err := os.MkdirAll(pkgDir, 0755); err != nil {
+		return fmt.Errorf("创建审查记录目录失败: %w", err)
+	}
+
+	data, err := json.MarshalIndent(approval, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(pkgDir, "PKGBUILD"), []byte(pkgbuild), 0644); err != nil {
+		return fmt.Errorf("保存PKGBUILD失败: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(pkgDir, "approval.json"), data, 0644); err != nil {
+		return fmt.Errorf("保存审查记录失败: %w", err)
+	}
+	return nil
+}
+
+// ReviewRequest 待审查的PKGBUILD
+type ReviewRequest struct {
+	Package    string
+	Maintainer string
+	Version    string
+	PKGBUILD   string
+	Diff       string // 与上次批准版本的差异，首次审查时为空
+	Previous   *AURApproval
+}
+
+// ReviewApprover 审查批准回调，返回 true 表示批准安装
+type ReviewApprover func(req ReviewRequest) (bool, error)
+
+// PKGBUILDReviewer AUR PKGBUILD 审查器
+//
+// 审查逐个进行（同一时间只有一个确认提示），本次运行中拒绝过的 PKGBUILD 不再重复询问。
+type PKGBUILDReviewer struct {
+	client   *AURClient
+	store    *ReviewStore
+	trusted  []config.TrustedAURPackage
+	approver ReviewApprover
+	logger   *logrus.Logger
+	mu       sync.Mutex
+	rejected map[string]string // 包名 -> 本次运行中拒绝的PKGBUILD校验和
+}
+
+// NewPKGBUILDReviewer 创建PKGBUILD审查器
+func NewPKGBUILDReviewer(client *AURClient, store *ReviewStore, reviewConfig *config.AURReviewConfig, approver ReviewApprover, logger *logrus.Logger) *PKGBUILDReviewer {
+	reviewer := &PKGBUILDReviewer{
+		client:   client,
+		store:    store,
+		approver: approver,
+		logger:   logger,
+		rejected: make(map[string]string),
+	}
+	if reviewConfig != nil {
+		reviewer.trusted = reviewConfig.TrustedPackages
+	}
+	return reviewer
+}
+
+// IsAURPackage 查询包是否存在于AUR
+func (r *PKGBUILDReviewer) IsAURPackage(ctx context.Context, packageName string) (bool, error) {
+	info, err := r.client.Info(ctx, packageName)
+	if err != nil {
+		return false, err
+	}
+	return info != nil, nil
+}
+
+// Review 审查AUR包的PKGBUILD，未获批准时返回错误
+func (r *PKGBUILDReviewer) Review(ctx context.Context, packageName string) error {
+	if err := validatePackageName(packageName); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	info, err := r.client.Info(ctx, packageName)
+	if err != nil {
+		return fmt.Errorf("获取AUR包 %s 信息失败: %w", packageName, err)
+	}
+	if info == nil {
+		return fmt.Errorf("AUR中未找到包 %s", packageName)
+	}
+
+	packageBase := info.PackageBase
+	if packageBase == "" {
+		packageBase = info.Name
+	}
+
+	pkgbuild, err := r.client.FetchPKGBUILD(ctx, packageBase)
+	if err != nil {
+		return fmt.Errorf("获取 %s 的PKGBUILD失败: %w", packageName, err)
+	}
+
+	checksum := pkgbuildChecksum(pkgbuild)
+	approval := AURApproval{
+		Package:    packageName,
+		Maintainer: info.Maintainer,
+		Version:    info.Version,
+		SHA256:     checksum,
+		ApprovedAt: time.Now(),
+	}
+
+	// 白名单中的包和维护者自动批准
+	if r.isTrusted(packageName, info.Maintainer) {
+		r.logger.Infof("AUR包 %s (维护者: %s) 在信任列表中，跳过人工审查", packageName, info.Maintainer)
+		approval.Trusted = true
+		return r.store.Save(approval, pkgbuild)
+	}
+
+	previous, previousPKGBUILD, err := r.store.Load(packageName)
+	if err != nil {
+		r.logger.Warnf("读取 %s 的审查记录失败: %v", packageName, err)
+	}
+
+	// 与上次批准的内容一致时无需再次审查
+	if previous != nil && previous.SHA256 == checksum && previous.Maintainer == info.Maintainer {
+		r.logger.Debugf("AUR包 %s 的PKGBUILD未变化，沿用 %s 的批准", packageName, previous.ApprovedAt.Format(time.RFC3339))
+		return nil
+	}
+
+	if r.rejected[packageName] == checksum {
+		return fmt.Errorf("AUR包 %s 的PKGBUILD未获批准，已取消安装", packageName)
+	}
+
+	req := ReviewRequest{
+		Package:    packageName,
+		Maintainer: info.Maintainer,
+		Version:    info.Version,
+		PKGBUILD:   pkgbuild,
+		Previous:   previous,
+	}
+	if previous != nil && previousPKGBUILD != "" {
+		req.Diff = unifiedLineDiff(previousPKGBUILD, pkgbuild)
+	}
+
+	if r.approver == nil {
+		return fmt.Errorf("AUR包 %s 需要审查PKGBUILD，但当前环境无法确认\n\n💡 解决方案:\n1. 在终端中运行以审查PKGBUILD\n2. 将包和维护者加入 aur_review.trusted_packages", packageName)
+	}
+
+	approved, err := r.approver(req)
+	if err != nil {
+		return fmt.Errorf("审查 %s 的PKGBUILD失败: %w", packageName, err)
+	}
+	if !approved {
+		r.rejected[packageName] = checksum
+		return fmt.Errorf("AUR包 %s 的PKGBUILD未获批准，已取消安装", packageName)
+	}
+
+	r.logger.Infof("已批准AUR包 %s 的PKGBUILD (版本: %s)", packageName, info.Version)
+	return r.store.Save(approval, pkgbuild)
+}
+
+// isTrusted 检查包和维护者是否在信任列表中
+func (r *PKGBUILDReviewer) isTrusted(packageName, maintainer string) bool {
+	if maintainer == "" {
+		return false // 孤儿包永远需要人工审查
+	}
+	for _, trusted := range r.trusted {
+		if trusted.Name == packageName && trusted.Maintainer == maintainer {
+			return true
+		}
+	}
+	return false
+}
+
+// TerminalApprover 在终端中展示PKGBUILD或差异并请求确认
+func TerminalApprover(req ReviewRequest) (bool, error) {
+	fmt.Printf("\n🔍 AUR包审查: %s %s (维护者: %s)\n", req.Package, req.Version, displayMaintainer(req.Maintainer))
+	fmt.Printf("═══════════════════════════════════════\n")
+
+	if req.Previous != nil && req.Previous.Maintainer != req.Maintainer {
+		fmt.Printf("⚠️  维护者已变更: %s -> %s\n\n", displayMaintainer(req.Previous.Maintainer), displayMaintainer(req.Maintainer))
+	}
+
+	if req.Diff != "" {
+		fmt.Printf("与上次批准版本 (%s) 的差异:\n\n%s\n", req.Previous.Version, req.Diff)
+	} else {
+		fmt.Printf("%s\n", req.PKGBUILD)
+	}
+
+	var approved bool
+	prompt := &survey.Confirm{
+		Message: fmt.Sprintf("是否批准安装 %s?", req.Package),
+		Default: false,
+		Help:    "批准记录会保存到 XDG_STATE_HOME，PKGBUILD 未变化时不会再次询问",
+	}
+	if err := survey.AskOne(prompt, &approved); err != nil {
+		return false, err
+	}
+	return approved, nil
+}
+
+// displayMaintainer 格式化维护者名称
+func displayMaintainer(maintainer string) string {
+	if maintainer == "" {
+		return "无 (孤儿包)"
+	}
+	return maintainer
+}
+
+// pkgbuildChecksum 计算PKGBUILD内容的SHA256
+func pkgbuildChecksum(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// unifiedLineDiff 生成简单的逐行差异，每行以 "  "、"- " 或 "+ " 开头
+func unifiedLineDiff(oldText, newText string) string {
+	var diff strings.Builder
+	for _, line := range config.DiffLines(strings.Split(oldText, "\n"), strings.Split(newText, "\n")) {
+		diff.WriteString(string(line.Op) + " " + line.Text + "\n")
+	}
+	return diff.String()
+}
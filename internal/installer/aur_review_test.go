@@ -0,0 +1,224 @@
+package installer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bbq191/dotfiles-go/internal/config"
+	"github.com/sirupsen/logrus"
+)
+
+// fakeAURServer 本地替代的AUR RPC服务器
+type fakeAURServer struct {
+	packages  map[string]AURRPCInfo
+	pkgbuilds map[string]string
+}
+
+func newFakeAURServer() *fakeAURServer {
+	return &fakeAURServer{
+		packages:  make(map[string]AURRPCInfo),
+		pkgbuilds: make(map[string]string),
+	}
+}
+
+func (f *fakeAURServer) addPackage(name, maintainer, version, pkgbuild string) {
+	f.packages[name] = AURRPCInfo{
+		Name:        name,
+		PackageBase: name,
+		Version:     version,
+		Maintainer:  maintainer,
+	}
+	f.pkgbuilds[name] = pkgbuild
+}
+
+func (f *fakeAURServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/rpc/v5/info"):
+		results := make([]AURRPCInfo, 0)
+		for _, name := range r.URL.Query()["arg[]"] {
+			if info, ok := f.packages[name]; ok {
+				results = append(results, info)
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"resultcount": len(results),
+			"results":     results,
+			"type":        "multiinfo",
+			"version":     5,
+		})
+	case r.URL.Path == "/cgit/aur.git/plain/PKGBUILD":
+		pkgbuild, ok := f.pkgbuilds[r.URL.Query().Get("h")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(pkgbuild))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// recordingApprover 记录审查请求的批准回调
+type recordingApprover struct {
+	requests []ReviewRequest
+	approve  bool
+}
+
+func (a *recordingApprover) approver(req ReviewRequest) (bool, error) {
+	a.requests = append(a.requests, req)
+	return a.approve, nil
+}
+
+func newTestReviewer(t *testing.T, server *httptest.Server, reviewConfig *config.AURReviewConfig, approver ReviewApprover) *PKGBUILDReviewer {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+	return NewPKGBUILDReviewer(NewAURClient(server.URL), NewReviewStore(t.TempDir()), reviewConfig, approver, logger)
+}
+
+// TestPKGBUILDReviewer_TrustedPackage 测试信任列表中的包无需人工审查
+func TestPKGBUILDReviewer_TrustedPackage(t *testing.T) {
+	fake := newFakeAURServer()
+	fake.addPackage("yay-bin", "jguer", "12.3.5-1", "pkgname=yay-bin\n")
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	approver := &recordingApprover{}
+	reviewConfig := &config.AURReviewConfig{
+		TrustedPackages: []config.TrustedAURPackage{{Name: "yay-bin", Maintainer: "jguer"}},
+	}
+	reviewer := newTestReviewer(t, server, reviewConfig, approver.approver)
+
+	if err := reviewer.Review(context.Background(), "yay-bin"); err != nil {
+		t.Fatalf("信任的包应该直接通过审查: %v", err)
+	}
+	if len(approver.requests) != 0 {
+		t.Errorf("信任的包不应该请求人工批准，实际请求了 %d 次", len(approver.requests))
+	}
+}
+
+// TestPKGBUILDReviewer_MaintainerMismatch 测试维护者不匹配时需要审查
+func TestPKGBUILDReviewer_MaintainerMismatch(t *testing.T) {
+	fake := newFakeAURServer()
+	fake.addPackage("yay-bin", "someone-else", "12.3.5-1", "pkgname=yay-bin\n")
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	approver := &recordingApprover{approve: false}
+	reviewConfig := &config.AURReviewConfig{
+		TrustedPackages: []config.TrustedAURPackage{{Name: "yay-bin", Maintainer: "jguer"}},
+	}
+	reviewer := newTestReviewer(t, server, reviewConfig, approver.approver)
+
+	if err := reviewer.Review(context.Background(), "yay-bin"); err == nil {
+		t.Error("维护者变更且未批准时应该返回错误")
+	}
+	if len(approver.requests) != 1 {
+		t.Errorf("期望请求 1 次人工批准，实际 %d 次", len(approver.requests))
+	}
+}
+
+// TestPKGBUILDReviewer_ApprovalRecorded 测试批准记录避免重复审查
+func TestPKGBUILDReviewer_ApprovalRecorded(t *testing.T) {
+	fake := newFakeAURServer()
+	fake.addPackage("paru", "Morganamilo", "2.0.4-1", "pkgname=paru\npkgver=2.0.4\n")
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	approver := &recordingApprover{approve: true}
+	reviewer := newTestReviewer(t, server, nil, approver.approver)
+	ctx := context.Background()
+
+	if err := reviewer.Review(ctx, "paru"); err != nil {
+		t.Fatalf("批准后应该通过审查: %v", err)
+	}
+	if err := reviewer.Review(ctx, "paru"); err != nil {
+		t.Fatalf("重复安装应该沿用批准记录: %v", err)
+	}
+	if len(approver.requests) != 1 {
+		t.Errorf("PKGBUILD 未变化时只应请求 1 次批准，实际 %d 次", len(approver.requests))
+	}
+
+	// PKGBUILD 更新后需要重新审查，并展示差异
+	fake.addPackage("paru", "Morganamilo", "2.0.5-1", "pkgname=paru\npkgver=2.0.5\n")
+	if err := reviewer.Review(ctx, "paru"); err != nil {
+		t.Fatalf("批准更新后应该通过审查: %v", err)
+	}
+	if len(approver.requests) != 2 {
+		t.Fatalf("PKGBUILD 变化后应该重新请求批准，实际 %d 次", len(approver.requests))
+	}
+
+	diff := approver.requests[1].Diff
+	if !strings.Contains(diff, "- pkgver=2.0.4") || !strings.Contains(diff, "+ pkgver=2.0.5") {
+		t.Errorf("差异应该包含版本变化，实际为:\n%s", diff)
+	}
+}
+
+// TestPKGBUILDReviewer_NoApprover 测试无法确认时拒绝安装
+func TestPKGBUILDReviewer_NoApprover(t *testing.T) {
+	fake := newFakeAURServer()
+	fake.addPackage("paru", "Morganamilo", "2.0.4-1", "pkgname=paru\n")
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	reviewer := newTestReviewer(t, server, nil, nil)
+
+	if err := reviewer.Review(context.Background(), "paru"); err == nil {
+		t.Error("没有批准回调时应该拒绝未信任的包")
+	}
+}
+
+// TestPKGBUILDReviewer_IsAURPackage 测试AUR包存在性查询
+func TestPKGBUILDReviewer_IsAURPackage(t *testing.T) {
+	fake := newFakeAURServer()
+	fake.addPackage("paru", "Morganamilo", "2.0.4-1", "pkgname=paru\n")
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	reviewer := newTestReviewer(t, server, nil, nil)
+	ctx := context.Background()
+
+	if isAUR, err := reviewer.IsAURPackage(ctx, "paru"); err != nil || !isAUR {
+		t.Errorf("paru 应该是AUR包，实际: %v, 错误: %v", isAUR, err)
+	}
+	if isAUR, err := reviewer.IsAURPackage(ctx, "bash"); err != nil || isAUR {
+		t.Errorf("bash 不应该是AUR包，实际: %v, 错误: %v", isAUR, err)
+	}
+}
+
+// TestPKGBUILDReviewer_RejectionRemembered 测试本次运行中拒绝过的 PKGBUILD 不再重复询问
+func TestPKGBUILDReviewer_RejectionRemembered(t *testing.T) {
+	fake := newFakeAURServer()
+	fake.addPackage("paru", "Morganamilo", "2.0.4-1", "pkgname=paru\n")
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	approver := &recordingApprover{approve: false}
+	reviewer := newTestReviewer(t, server, nil, approver.approver)
+	ctx := context.Background()
+
+	for range 2 {
+		if err := reviewer.Review(ctx, "paru"); err == nil {
+			t.Fatal("拒绝后应该返回错误")
+		}
+	}
+	if len(approver.requests) != 1 {
+		t.Errorf("拒绝后不应再次询问，实际请求了 %d 次", len(approver.requests))
+	}
+}
+
+// TestPKGBUILDReviewer_InvalidName 测试拒绝可能逃出记录目录的包名
+func TestPKGBUILDReviewer_InvalidName(t *testing.T) {
+	server := httptest.NewServer(newFakeAURServer())
+	defer server.Close()
+
+	reviewer := newTestReviewer(t, server, nil, (&recordingApprover{approve: true}).approver)
+	for _, name := range []string{"../evil", "a/b", "..", ""} {
+		if err := reviewer.Review(context.Background(), name); err == nil || !strings.Contains(err.Error(), "无效的AUR包名") {
+			t.Errorf("包名 %q 应该被拒绝，实际: %v", name, err)
+		}
+	}
+}
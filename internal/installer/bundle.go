@@ -0,0 +1,540 @@
+package installer
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 离线包目录结构
+const (
+	BundleManifestFile = "bundle.json" // 离线包清单
+	BundleChecksumFile = "SHA256SUMS"  // sha256sum -c 兼容的校验和文件
+	bundleRepoDir      = "repo"        // pacman -Syw 下载的官方仓库包
+	bundleAURDir       = "aur"         // makepkg 构建的 AUR 包
+)
+
+// 离线包中包的来源
+const (
+	BundleSourceRepo = "repo"
+	BundleSourceAUR  = "aur"
+)
+
+// BundleManifest 离线包清单（bundle.json）
+type BundleManifest struct {
+	Created      time.Time       `json:"created"`
+	Architecture string          `json:"architecture"`
+	Profile      string          `json:"profile,omitempty"`
+	Packages     []BundlePackage `json:"packages"`          // 打包的清单包
+	Skipped      []string        `json:"skipped,omitempty"` // 没有 pacman/yay 映射或仓库中找不到而未打包的包
+	Files        []BundleFile    `json:"files"`             // 包文件及校验和
+}
+
+// BundlePackage 离线包中的一个清单包
+type BundlePackage struct {
+	Name    string `json:"name"`    // 清单中的包名
+	Package string `json:"package"` // 仓库或 AUR 中的实际包名
+	Source  string `json:"source"`  // repo 或 aur
+}
+
+// BundleFile 离线包中的一个文件
+type BundleFile struct {
+	Path   string `json:"path"` // 相对离线包目录的路径（/ 分隔）
+	SHA256 string `json:"sha256"`
+	Size   int64  `json:"size"`
+}
+
+// BundleOptions 离线包创建选项
+type BundleOptions struct {
+	Dir     string // 输出目录
+	Profile string // 使用的 profile（仅记录到清单）
+}
+
+// CreateBundle 下载包文件到离线包目录：官方仓库包及其完整依赖通过 pacman -Syw 下载，
+// AUR 包审查 PKGBUILD 后用 makepkg 构建，最后写入 bundle.json 和 SHA256SUMS
+func (i *Installer) CreateBundle(ctx context.Context, packages []string, opts BundleOptions) (*BundleManifest, error) {
+	dir, err := filepath.Abs(opts.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("解析输出目录失败: %w", err)
+	}
+	for _, sub := range []string{bundleRepoDir, bundleAURDir} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0755); err != nil {
+			return nil, fmt.Errorf("创建目录失败: %w", err)
+		}
+	}
+
+	manifest := &BundleManifest{
+		Created:      time.Now(),
+		Architecture: runtime.GOARCH,
+		Profile:      opts.Profile,
+	}
+	var repoNames, aurNames []string
+	for _, name := range packages {
+		pkg, ok := i.resolveBundlePackage(ctx, name)
+		if !ok {
+			i.logger.Warnf("包 %s 没有可用的 pacman/yay 映射，不加入离线包", name)
+			manifest.Skipped = append(manifest.Skipped, name)
+			continue
+		}
+		manifest.Packages = append(manifest.Packages, pkg)
+		if pkg.Source == BundleSourceAUR {
+			aurNames = append(aurNames, pkg.Package)
+		} else {
+			repoNames = append(repoNames, pkg.Package)
+		}
+	}
+
+	// AUR 包先构建，其官方仓库依赖一并下载
+	for _, name := range aurNames {
+		deps, err := i.buildAURPackage(ctx, name, filepath.Join(dir, bundleAURDir))
+		if err != nil {
+			return nil, err
+		}
+		for _, dep := range deps {
+			if !slices.Contains(repoNames, dep) {
+				repoNames = append(repoNames, dep)
+			}
+		}
+	}
+
+	if len(repoNames) > 0 {
+		if err := i.downloadRepoPackages(ctx, repoNames, filepath.Join(dir, bundleRepoDir)); err != nil {
+			return nil, err
+		}
+	}
+
+	if manifest.Files, err = collectBundleFiles(dir); err != nil {
+		return nil, err
+	}
+	if err := writeBundleManifest(dir, manifest); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// downloadRepoPackages 下载官方仓库包及其完整依赖闭包到 repoDir
+//
+// pacman -Sw 会跳过本机已安装的依赖，因此使用空的临时数据库（--dbpath）同步仓库后下载：
+// pacman 认为没有任何包已安装，从而下载目标机器可能缺少的所有依赖。
+func (i *Installer) downloadRepoPackages(ctx context.Context, names []string, repoDir string) error {
+	dbPath, err := os.MkdirTemp("", "dotfiles-bundle-db-")
+	if err != nil {
+		return fmt.Errorf("创建临时数据库目录失败: %w", err)
+	}
+	defer os.RemoveAll(dbPath)
+
+	args := append([]string{"-Syw", "--noconfirm", "--dbpath", dbPath, "--cachedir", repoDir}, names...)
+	cmd := privilegedCommand(ctx, "pacman", args...)
+	i.logger.Infof("下载 %d 个官方仓库包及其依赖到 %s", len(names), repoDir)
+	i.logger.Debugf("执行命令: %s", strings.Join(cmd.Args, " "))
+	output, err := cmd.CombinedOutput()
+
+	// pacman 以 root 身份写入缓存目录和临时数据库，交还给当前用户以便后续复制和删除
+	if os.Geteuid() != 0 {
+		owner := fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid())
+		if chownOutput, err := privilegedCommand(ctx, "chown", "-R", owner, repoDir, dbPath).CombinedOutput(); err != nil {
+			i.logger.Warnf("修改 %s 所有者失败: %v (%s)", repoDir, err, strings.TrimSpace(string(chownOutput)))
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("pacman -Syw 下载失败: %w\n输出: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// resolveBundlePackage 确定包的来源：官方仓库中存在 pacman 映射时使用仓库包，否则使用 yay 映射构建 AUR 包
+func (i *Installer) resolveBundlePackage(ctx context.Context, name string) (BundlePackage, bool) {
+	pacmanName, yayName := name, name
+	if info := i.FindPackageInfo(name); info != nil {
+		pacmanName, yayName = info.Managers["pacman"], info.Managers["yay"]
+	}
+
+	for _, candidate := range []string{pacmanName, yayName} {
+		if candidate != "" && inSyncRepos(ctx, candidate) {
+			return BundlePackage{Name: name, Package: candidate, Source: BundleSourceRepo}, true
+		}
+	}
+	if yayName != "" {
+		return BundlePackage{Name: name, Package: yayName, Source: BundleSourceAUR}, true
+	}
+	return BundlePackage{}, false
+}
+
+// buildAURPackage 审查 PKGBUILD 后通过 yay -G 获取构建文件，用 makepkg 构建到 dest，返回官方仓库中的运行依赖
+//
+// 不使用 makepkg --syncdeps（会把 makedepends 静默安装到本机）：构建依赖需要已安装在本机，
+// 缺少时返回错误并列出，然后以 --nodeps 构建。运行依赖由 CreateBundle 下载到离线包中。
+func (i *Installer) buildAURPackage(ctx context.Context, name, dest string) ([]string, error) {
+	reviewer := i.aurReviewer()
+	if reviewer == nil {
+		return nil, fmt.Errorf("未配置PKGBUILD审查器，无法构建AUR包 %s", name)
+	}
+	if err := reviewer.Review(ctx, name); err != nil {
+		return nil, err
+	}
+
+	workDir, err := os.MkdirTemp("", "dotfiles-bundle-")
+	if err != nil {
+		return nil, fmt.Errorf("创建构建目录失败: %w", err)
+	}
+	defer os.RemoveAll(workDir)
+
+	i.logger.Infof("获取AUR包 %s 的构建文件", name)
+	getCmd := setProcessGroup(exec.CommandContext(ctx, "yay", "-G", name))
+	getCmd.Dir = workDir
+	if output, err := getCmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("yay -G %s 失败: %w\n输出: %s", name, err, strings.TrimSpace(string(output)))
+	}
+	buildDir, err := singleSubdir(workDir)
+	if err != nil {
+		return nil, fmt.Errorf("未找到 %s 的构建目录: %w", name, err)
+	}
+
+	srcinfo := setProcessGroup(exec.CommandContext(ctx, "makepkg", "--printsrcinfo"))
+	srcinfo.Dir = buildDir
+	output, err := srcinfo.Output()
+	if err != nil {
+		return nil, fmt.Errorf("读取 %s 的 .SRCINFO 失败: %w", name, err)
+	}
+	var repoDeps []string
+	for _, dep := range parseSrcinfoDepends(string(output), "depends") {
+		if inSyncRepos(ctx, dep) {
+			repoDeps = append(repoDeps, dep)
+		} else {
+			i.logger.Warnf("AUR包 %s 的依赖 %s 不在官方仓库中，需要单独加入清单", name, dep)
+		}
+	}
+
+	missing, err := missingDepends(ctx, parseSrcinfoDepends(string(output), "depends", "makedepends", "checkdepends"))
+	if err != nil {
+		return nil, err
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("构建AUR包 %s 需要先在本机安装: %s", name, strings.Join(missing, " "))
+	}
+
+	i.logger.Infof("构建AUR包 %s", name)
+	build := setProcessGroup(exec.CommandContext(ctx, "makepkg", "--nodeps", "--noconfirm", "--force"))
+	build.Dir = buildDir
+	build.Env = append(os.Environ(), "PKGDEST="+dest)
+	if output, err := build.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("makepkg 构建 %s 失败: %w\n输出: %s", name, err, strings.TrimSpace(string(output)))
+	}
+	return repoDeps, nil
+}
+
+// aurReviewer 返回已注册的 yay 管理器上的 PKGBUILD 审查器
+func (i *Installer) aurReviewer() *PKGBUILDReviewer {
+	for _, manager := range i.managers {
+		if yay, ok := manager.(*YayManager); ok && yay.reviewer != nil {
+			return yay.reviewer
+		}
+	}
+	return nil
+}
+
+// InstallBundle 通过 pacman -U 安装已由 VerifyBundle 校验的离线包，不访问网络
+//
+// 只有清单中的包作为显式安装；依赖闭包中本次新安装的包随后用 pacman -D --asdeps 标记为依赖，
+// 使 pacman -Qdt 能识别孤立包，pkg import 也不会把它们加入清单。安装前已存在的包保留原有的安装原因。
+func (i *Installer) InstallBundle(ctx context.Context, dir string, manifest *BundleManifest) error {
+	explicit := make(map[string]bool)
+	for _, pkg := range manifest.Packages {
+		explicit[pkg.Package] = true
+	}
+	var files, deps []string
+	for _, file := range manifest.Files {
+		if !isPackageArchive(file.Path) {
+			continue
+		}
+		files = append(files, filepath.Join(dir, filepath.FromSlash(file.Path)))
+		if name := archivePackageName(file.Path); !explicit[name] && !slices.Contains(deps, name) {
+			deps = append(deps, name)
+		}
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("离线包 %s 中没有可安装的包文件", dir)
+	}
+
+	installed, err := queryInstalledNames(ctx, "pacman", "-Qq")
+	if err != nil {
+		return err
+	}
+	var newDeps []string
+	for _, name := range deps {
+		if !installed.Contains(name) {
+			newDeps = append(newDeps, name)
+		}
+	}
+
+	args := append([]string{"-U", "--noconfirm", "--needed"}, files...)
+	cmd := privilegedCommand(ctx, "pacman", args...)
+	i.logger.Infof("从离线包安装 %d 个包文件", len(files))
+	i.logger.Debugf("执行命令: %s", strings.Join(cmd.Args, " "))
+	output, err := cmd.CombinedOutput()
+	i.InvalidateInstalledCache()
+	if err != nil {
+		return fmt.Errorf("pacman -U 安装失败: %w\n输出: %s", err, strings.TrimSpace(string(output)))
+	}
+	i.logger.Debugf("安装输出: %s", string(output))
+
+	if len(newDeps) > 0 {
+		args := append([]string{"-D", "--asdeps"}, newDeps...)
+		if output, err := privilegedCommand(ctx, "pacman", args...).CombinedOutput(); err != nil {
+			return fmt.Errorf("标记 %d 个依赖包失败: %w\n输出: %s", len(newDeps), err, strings.TrimSpace(string(output)))
+		}
+		i.logger.Debugf("已将 %d 个依赖包标记为非显式安装", len(newDeps))
+	}
+	return nil
+}
+
+// VerifyBundle 读取 bundle.json 并校验所有文件的大小和 SHA256
+//
+// 清单中的路径必须位于离线包目录内，并与 SHA256SUMS 一致；目录中清单未列出的包文件视为校验失败。
+func VerifyBundle(dir string) (*BundleManifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, BundleManifestFile))
+	if err != nil {
+		return nil, fmt.Errorf("读取离线包清单失败: %w", err)
+	}
+	var manifest BundleManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("解析离线包清单失败: %w", err)
+	}
+	sums, err := readBundleChecksums(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []string
+	listed := make(map[string]bool)
+	for _, expected := range manifest.Files {
+		if !filepath.IsLocal(filepath.FromSlash(expected.Path)) {
+			problems = append(problems, fmt.Sprintf("%s: 路径超出离线包目录", expected.Path))
+			continue
+		}
+		listed[expected.Path] = true
+		if sums[expected.Path] != expected.SHA256 {
+			problems = append(problems, fmt.Sprintf("%s: 与 %s 不一致", expected.Path, BundleChecksumFile))
+		}
+
+		path := filepath.Join(dir, filepath.FromSlash(expected.Path))
+		actual, err := hashBundleFile(dir, path)
+		switch {
+		case err != nil:
+			problems = append(problems, fmt.Sprintf("%s: %v", expected.Path, err))
+		case actual.SHA256 != expected.SHA256 || actual.Size != expected.Size:
+			problems = append(problems, fmt.Sprintf("%s: 校验和不匹配", expected.Path))
+		}
+	}
+	for path := range sums {
+		if !listed[path] {
+			problems = append(problems, fmt.Sprintf("%s: 在 %s 中但不在清单中", path, BundleChecksumFile))
+		}
+	}
+
+	paths, err := bundleFilePaths(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range paths {
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return nil, err
+		}
+		if rel = filepath.ToSlash(rel); isPackageArchive(rel) && !listed[rel] {
+			problems = append(problems, fmt.Sprintf("%s: 包文件不在清单中", rel))
+		}
+	}
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return nil, fmt.Errorf("离线包校验失败:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return &manifest, nil
+}
+
+// readBundleChecksums 读取 SHA256SUMS，返回路径 -> SHA256
+func readBundleChecksums(dir string) (map[string]string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, BundleChecksumFile))
+	if err != nil {
+		return nil, fmt.Errorf("读取校验和文件失败: %w", err)
+	}
+	sums := make(map[string]string)
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		sum, path, ok := strings.Cut(line, "  ")
+		if !ok {
+			// sha256sum 的二进制模式: <校验和> *<路径>
+			if sum, path, ok = strings.Cut(line, " *"); !ok {
+				return nil, fmt.Errorf("%s 格式错误: %q", BundleChecksumFile, line)
+			}
+		}
+		sums[path] = sum
+	}
+	return sums, nil
+}
+
+// collectBundleFiles 计算 repo/ 和 aur/ 下所有文件的校验和（按路径排序）
+func collectBundleFiles(dir string) ([]BundleFile, error) {
+	paths, err := bundleFilePaths(dir)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]BundleFile, 0, len(paths))
+	for _, path := range paths {
+		file, err := hashBundleFile(dir, path)
+		if err != nil {
+			return nil, fmt.Errorf("计算校验和失败: %w", err)
+		}
+		files = append(files, file)
+	}
+	sort.Slice(files, func(a, b int) bool { return files[a].Path < files[b].Path })
+	return files, nil
+}
+
+// bundleFilePaths 返回 repo/ 和 aur/ 下的所有文件
+func bundleFilePaths(dir string) ([]string, error) {
+	var paths []string
+	for _, sub := range []string{bundleRepoDir, bundleAURDir} {
+		err := filepath.WalkDir(filepath.Join(dir, sub), func(path string, d os.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			paths = append(paths, path)
+			return nil
+		})
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("读取离线包目录失败: %w", err)
+		}
+	}
+	return paths, nil
+}
+
+// hashBundleFile 计算文件的 SHA256 和大小
+func hashBundleFile(dir, path string) (BundleFile, error) {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return BundleFile{}, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return BundleFile{}, err
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, f)
+	if err != nil {
+		return BundleFile{}, err
+	}
+	return BundleFile{Path: filepath.ToSlash(rel), SHA256: hex.EncodeToString(hash.Sum(nil)), Size: size}, nil
+}
+
+// writeBundleManifest 写入 bundle.json 和 SHA256SUMS
+func writeBundleManifest(dir string, manifest *BundleManifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化离线包清单失败: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, BundleManifestFile), append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("写入离线包清单失败: %w", err)
+	}
+
+	var sums strings.Builder
+	for _, file := range manifest.Files {
+		fmt.Fprintf(&sums, "%s  %s\n", file.SHA256, file.Path)
+	}
+	if err := os.WriteFile(filepath.Join(dir, BundleChecksumFile), []byte(sums.String()), 0644); err != nil {
+		return fmt.Errorf("写入校验和文件失败: %w", err)
+	}
+	return nil
+}
+
+// parseSrcinfoDepends 解析 .SRCINFO 中指定键（如 depends、makedepends）的依赖，去掉版本约束
+func parseSrcinfoDepends(srcinfo string, keys ...string) []string {
+	var deps []string
+	scanner := bufio.NewScanner(strings.NewReader(srcinfo))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(strings.TrimSpace(scanner.Text()), " = ")
+		if !ok || !slices.Contains(keys, key) {
+			continue
+		}
+		name := strings.TrimSpace(value)
+		if idx := strings.IndexAny(name, "<>="); idx >= 0 {
+			name = name[:idx]
+		}
+		if name != "" && !slices.Contains(deps, name) {
+			deps = append(deps, name)
+		}
+	}
+	return deps
+}
+
+// missingDepends 返回本机未安装（pacman -T 不满足）的依赖
+func missingDepends(ctx context.Context, deps []string) ([]string, error) {
+	if len(deps) == 0 {
+		return nil, nil
+	}
+	output, err := exec.CommandContext(ctx, "pacman", append([]string{"-T"}, deps...)...).Output()
+	var exitErr *exec.ExitError
+	if err != nil && !(errors.As(err, &exitErr) && exitErr.ExitCode() == 127) {
+		return nil, fmt.Errorf("检查依赖失败: %w", err)
+	}
+	return strings.Fields(string(output)), nil
+}
+
+// archivePackageName 从包文件名（<包名>-<版本>-<发布号>-<架构>.pkg.tar.*）中取出包名
+func archivePackageName(path string) string {
+	parts := strings.Split(filepath.Base(path), "-")
+	if len(parts) < 4 {
+		return filepath.Base(path)
+	}
+	return strings.Join(parts[:len(parts)-3], "-")
+}
+
+// isPackageArchive 检查文件是否为 pacman 包（排除签名文件）
+func isPackageArchive(path string) bool {
+	return strings.Contains(filepath.Base(path), ".pkg.tar") && !strings.HasSuffix(path, ".sig")
+}
+
+// singleSubdir 返回目录下唯一的子目录
+func singleSubdir(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	var found string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if found != "" {
+			return "", fmt.Errorf("%s 下有多个目录", dir)
+		}
+		found = filepath.Join(dir, entry.Name())
+	}
+	if found == "" {
+		return "", fmt.Errorf("%s 下没有目录", dir)
+	}
+	return found, nil
+}
+
+// inSyncRepos 检查包是否存在于官方同步仓库
+func inSyncRepos(ctx context.Context, packageName string) bool {
+	return exec.CommandContext(ctx, "pacman", "-Si", packageName).Run() == nil
+}
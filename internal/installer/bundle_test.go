@@ -0,0 +1,174 @@
+package installer
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TestVerifyBundle 测试离线包清单写入、校验和篡改检测
+func TestVerifyBundle(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"repo/git-2.45.0-1-x86_64.pkg.tar.zst":     "git package",
+		"repo/git-2.45.0-1-x86_64.pkg.tar.zst.sig": "signature",
+		"aur/yay-bin-12.3.5-1-x86_64.pkg.tar.zst":  "yay package",
+	}
+	for path, content := range files {
+		full := filepath.Join(dir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	collected, err := collectBundleFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(collected) != 3 || collected[0].Path != "aur/yay-bin-12.3.5-1-x86_64.pkg.tar.zst" {
+		t.Fatalf("收集的文件错误: %+v", collected)
+	}
+	if err := writeBundleManifest(dir, &BundleManifest{Files: collected}); err != nil {
+		t.Fatal(err)
+	}
+	sums, err := os.ReadFile(filepath.Join(dir, BundleChecksumFile))
+	if err != nil || strings.Count(string(sums), "\n") != 3 {
+		t.Errorf("SHA256SUMS 内容错误: %q, %v", sums, err)
+	}
+
+	manifest, err := VerifyBundle(dir)
+	if err != nil {
+		t.Fatalf("未修改的离线包应该校验通过: %v", err)
+	}
+
+	// 离线包中没有包文件时拒绝安装
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+	manifest.Files = manifest.Files[2:]
+	if err := NewInstaller(logger).InstallBundle(context.Background(), dir, manifest); err == nil {
+		t.Error("只有签名文件的离线包应该返回错误")
+	}
+
+	tampered := filepath.Join(dir, "repo", "git-2.45.0-1-x86_64.pkg.tar.zst")
+	if err := os.WriteFile(tampered, []byte("git packagX"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = VerifyBundle(dir)
+	if err == nil || !strings.Contains(err.Error(), "repo/git-2.45.0-1-x86_64.pkg.tar.zst") {
+		t.Errorf("被篡改的文件应该校验失败，实际: %v", err)
+	}
+}
+
+// TestVerifyBundle_Rejects 测试超出目录的路径、清单未列出的包文件和与 SHA256SUMS 不一致的清单
+func TestVerifyBundle_Rejects(t *testing.T) {
+	newBundle := func(t *testing.T) (string, *BundleManifest) {
+		dir := t.TempDir()
+		full := filepath.Join(dir, "repo", "git-2.45.0-1-x86_64.pkg.tar.zst")
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte("git package"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		files, err := collectBundleFiles(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return dir, &BundleManifest{Files: files}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(t *testing.T, dir string, manifest *BundleManifest)
+		want   string
+	}{
+		{
+			name: "超出目录的路径",
+			modify: func(t *testing.T, dir string, manifest *BundleManifest) {
+				manifest.Files = append(manifest.Files, BundleFile{Path: "../outside.pkg.tar.zst", SHA256: "00"})
+			},
+			want: "../outside.pkg.tar.zst: 路径超出离线包目录",
+		},
+		{
+			name: "绝对路径",
+			modify: func(t *testing.T, dir string, manifest *BundleManifest) {
+				manifest.Files = append(manifest.Files, BundleFile{Path: "/etc/passwd", SHA256: "00"})
+			},
+			want: "/etc/passwd: 路径超出离线包目录",
+		},
+		{
+			name: "清单未列出的包文件",
+			modify: func(t *testing.T, dir string, manifest *BundleManifest) {
+				extra := filepath.Join(dir, "aur", "evil-1.0-1-x86_64.pkg.tar.zst")
+				if err := os.MkdirAll(filepath.Dir(extra), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(extra, []byte("evil"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: "aur/evil-1.0-1-x86_64.pkg.tar.zst: 包文件不在清单中",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, manifest := newBundle(t)
+			tt.modify(t, dir, manifest)
+			if err := writeBundleManifest(dir, manifest); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := VerifyBundle(dir); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("期望包含 %q 的错误，实际: %v", tt.want, err)
+			}
+		})
+	}
+
+	// 清单与 SHA256SUMS 不一致（只改了 bundle.json）
+	dir, manifest := newBundle(t)
+	if err := writeBundleManifest(dir, manifest); err != nil {
+		t.Fatal(err)
+	}
+	manifest.Files[0].SHA256 = strings.Repeat("0", 64)
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, BundleManifestFile), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := VerifyBundle(dir); err == nil || !strings.Contains(err.Error(), "与 SHA256SUMS 不一致") {
+		t.Errorf("清单与 SHA256SUMS 不一致时应校验失败，实际: %v", err)
+	}
+}
+
+// TestParseSrcinfoDepends 测试 .SRCINFO 依赖解析
+func TestParseSrcinfoDepends(t *testing.T) {
+	srcinfo := "pkgbase = yay-bin\n\tpkgver = 12.3.5\n\tmakedepends = go\n\tdepends = pacman>6.1\n\tdepends = git\n\npkgname = yay-bin\n\tdepends = git\n"
+	if got := strings.Join(parseSrcinfoDepends(srcinfo, "depends"), ","); got != "pacman,git" {
+		t.Errorf("期望 pacman,git，实际 %s", got)
+	}
+	if got := strings.Join(parseSrcinfoDepends(srcinfo, "depends", "makedepends"), ","); got != "go,pacman,git" {
+		t.Errorf("期望 go,pacman,git，实际 %s", got)
+	}
+	if !isPackageArchive("repo/git-2.45.0-1-x86_64.pkg.tar.zst") || isPackageArchive("repo/git-2.45.0-1-x86_64.pkg.tar.zst.sig") {
+		t.Error("包文件识别错误")
+	}
+	for path, want := range map[string]string{
+		"repo/git-2.45.0-1-x86_64.pkg.tar.zst":          "git",
+		"repo/python-pip-24.0-1-any.pkg.tar.zst":        "python-pip",
+		"aur/yay-bin-debug-12.3.5-1-x86_64.pkg.tar.zst": "yay-bin-debug",
+		"repo/vim-1:9.1.0-1-x86_64.pkg.tar.zst":         "vim",
+	} {
+		if got := archivePackageName(path); got != want {
+			t.Errorf("archivePackageName(%q) = %q，期望 %q", path, got, want)
+		}
+	}
+}
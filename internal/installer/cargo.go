@@ -0,0 +1,161 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// CargoManager cargo install 包管理器实现
+type CargoManager struct {
+	toolEnvironment
+	logger *logrus.Logger
+}
+
+// NewCargoManager 创建Cargo管理器实例
+func NewCargoManager(logger *logrus.Logger) *CargoManager {
+	return &CargoManager{
+		logger: logger,
+	}
+}
+
+// Name 返回包管理器名称
+func (c *CargoManager) Name() string {
+	return "cargo"
+}
+
+// IsAvailable 检查cargo是否可用
+func (c *CargoManager) IsAvailable() bool {
+	_, err := exec.LookPath("cargo")
+	available := err == nil
+	c.logger.Debugf("Cargo 可用性检查: %v", available)
+	return available
+}
+
+// Install 安装crate
+func (c *CargoManager) Install(ctx context.Context, packageName string) error {
+	c.logger.Infof("使用 Cargo 安装包: %s", packageName)
+
+	args := []string{"install", "--locked", packageName}
+	return c.runInstall(ctx, packageName, args)
+}
+
+// InstallVersion 安装指定版本的crate
+func (c *CargoManager) InstallVersion(ctx context.Context, packageName, version string) error {
+	c.logger.Infof("使用 Cargo 安装包: %s (版本 %s)", packageName, version)
+
+	args := []string{"install", "--locked", packageName, "--version", version}
+	return c.runInstall(ctx, packageName, args)
+}
+
+// runInstall 执行安装命令
+func (c *CargoManager) runInstall(ctx context.Context, packageName string, args []string) error {
+	cmd := setProcessGroup(c.command(exec.CommandContext(ctx, "cargo", args...)))
+	c.logger.Debugf("执行命令: cargo %s", strings.Join(args, " "))
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		c.logger.Errorf("安装 %s 失败: %v", packageName, err)
+		c.logger.Debugf("命令输出: %s", string(output))
+		if notFound := notFoundIn(c.Name(), packageName, string(output), "could not find `"); notFound != nil {
+			return notFound
+		}
+		return fmt.Errorf("cargo install 失败: %w", err)
+	}
+
+	c.logger.Infof("成功安装 %s", packageName)
+	return nil
+}
+
+// IsInstalled 检查crate是否已安装
+func (c *CargoManager) IsInstalled(packageName string) bool {
+	installed, err := c.installedCrates()
+	if err != nil {
+		c.logger.Debugf("读取 cargo 已安装列表失败: %v", err)
+		return false
+	}
+	_, ok := installed[packageName]
+	return ok
+}
+
+// InstalledSnapshot 通过 cargo install --list 一次性列出所有已安装的 crate
+func (c *CargoManager) InstalledSnapshot(ctx context.Context) (*InstalledSet, error) {
+	installed, err := c.installedCrates()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(installed))
+	for name := range installed {
+		names = append(names, name)
+	}
+	return NewInstalledSet(names, false), nil
+}
+
+// InstalledVersion 返回已安装的版本
+func (c *CargoManager) InstalledVersion(packageName string) (string, error) {
+	installed, err := c.installedCrates()
+	if err != nil {
+		return "", err
+	}
+	version, ok := installed[packageName]
+	if !ok {
+		return "", fmt.Errorf("crate %s 未安装", packageName)
+	}
+	return version, nil
+}
+
+// AvailableVersion 返回 crates.io 上的最新版本
+func (c *CargoManager) AvailableVersion(packageName string) (string, error) {
+	output, err := c.command(exec.Command("cargo", "search", packageName, "--limit", "1")).Output()
+	if err != nil {
+		return "", err
+	}
+	return parseCargoSearchVersion(string(output), packageName)
+}
+
+// Priority 返回优先级
+func (c *CargoManager) Priority() int {
+	return 10 // 语言包管理器排在系统包管理器之后
+}
+
+// installedCrates 解析 cargo install --list 输出
+func (c *CargoManager) installedCrates() (map[string]string, error) {
+	output, err := c.command(exec.Command("cargo", "install", "--list")).Output()
+	if err != nil {
+		return nil, err
+	}
+	return parseCargoInstallList(string(output)), nil
+}
+
+// parseCargoInstallList 解析 "crate v1.2.3:" 格式的已安装列表（缩进行为二进制名）
+func parseCargoInstallList(output string) map[string]string {
+	crates := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		if line == "" || strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
+			continue
+		}
+		fields := strings.Fields(strings.TrimSuffix(strings.TrimSpace(line), ":"))
+		if len(fields) >= 2 {
+			crates[fields[0]] = strings.TrimPrefix(fields[1], "v")
+		}
+	}
+	return crates
+}
+
+// parseCargoSearchVersion 解析 cargo search 输出中的版本（name = "1.2.3"    # 描述）
+func parseCargoSearchVersion(output, packageName string) (string, error) {
+	for _, line := range strings.Split(output, "\n") {
+		name, rest, ok := strings.Cut(line, "=")
+		if !ok || strings.TrimSpace(name) != packageName {
+			continue
+		}
+		fields := strings.Fields(rest)
+		if len(fields) > 0 {
+			return strings.Trim(fields[0], `"`), nil
+		}
+	}
+	return "", fmt.Errorf("crates.io 中未找到 %s", packageName)
+}
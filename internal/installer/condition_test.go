@@ -0,0 +1,90 @@
+package installer
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/bbq191/dotfiles-go/internal/config"
+	"github.com/bbq191/dotfiles-go/internal/platform"
+	"github.com/sirupsen/logrus"
+)
+
+// TestManifestPackageNames_When 测试 when 条件按 WSL、发行版和架构过滤包和分类
+func TestManifestPackageNames_When(t *testing.T) {
+	manifest := `{
+		"categories": {
+			"essential": {"priority": 1, "packages": {
+				"git":    {"managers": {"pacman": "git"}},
+				"wslu":   {"managers": {"yay": "wslu"}, "when": {"wsl": true}},
+				"nvidia": {"managers": {"pacman": "nvidia-utils"}, "when": {"wsl": false, "arch": ["amd64"]}}
+			}},
+			"debian_only": {"priority": 2, "when": {"distro": "ubuntu"}, "packages": {
+				"apt-file": {"managers": {"apt": "apt-file"}}
+			}},
+			"not_arch": {"priority": 3, "when": {"distro": "!arch"}, "packages": {
+				"snapd": {"managers": {"apt": "snapd"}}
+			}}
+		}
+	}`
+	var packages config.PackagesConfig
+	if err := json.Unmarshal([]byte(manifest), &packages); err != nil {
+		t.Fatal(err)
+	}
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+	inst := NewInstaller(logger)
+	inst.SetPackagesConfig(&packages)
+
+	arch := &platform.PlatformInfo{OS: "linux", Architecture: "amd64", Linux: &platform.LinuxInfo{Distribution: "arch"}}
+	wsl := &platform.PlatformInfo{
+		OS: "linux", Architecture: "amd64",
+		WSL:   &platform.WSLInfo{IsWSL: true, Version: "2"},
+		Linux: &platform.LinuxInfo{Distribution: "ubuntu"},
+	}
+
+	cases := []struct {
+		info *platform.PlatformInfo
+		want string
+	}{
+		{arch, "git,nvidia"},
+		{wsl, "git,wslu,apt-file,snapd"},
+		{nil, "git,nvidia,wslu,apt-file,snapd"},
+	}
+	for _, tc := range cases {
+		inst.SetPlatformInfo(tc.info)
+		if got := strings.Join(inst.ManifestPackageNames(), ","); got != tc.want {
+			t.Errorf("期望 %s，实际 %s", tc.want, got)
+		}
+	}
+
+	inst.SetPlatformInfo(arch)
+	result, err := inst.InstallPackage(context.Background(), "wslu", InstallOptions{})
+	if err != nil || !result.Skipped {
+		t.Errorf("条件不满足的包应该被跳过: %+v, %v", result, err)
+	}
+	if status := inst.CheckPackageStatus("wslu"); !status.NotApplicable {
+		t.Error("状态检查应该标记条件不满足的包")
+	}
+}
+
+// TestConditionValidate 测试 validate 拒绝未知的条件键
+func TestConditionValidate(t *testing.T) {
+	var info config.PackageInfo
+	if err := json.Unmarshal([]byte(`{"when": {"os": "linux", "distribution": "arch"}}`), &info); err != nil {
+		t.Fatal(err)
+	}
+	err := info.When.Validate()
+	if err == nil || !strings.Contains(err.Error(), "distribution") {
+		t.Errorf("未知的条件键应该返回错误，实际: %v", err)
+	}
+
+	if err := json.Unmarshal([]byte(`{"when": {"powershell": "core", "os": ["windows", "linux"]}}`), &info); err != nil {
+		t.Fatal(err)
+	}
+	if err := info.When.Validate(); err != nil {
+		t.Errorf("合法的条件不应该返回错误: %v", err)
+	}
+}
@@ -0,0 +1,174 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// sudoRefreshInterval sudo 时间戳的刷新间隔（默认 timestamp_timeout 为 5 分钟）
+const sudoRefreshInterval = time.Minute
+
+// PrivilegedManager 安装时需要 root 权限的包管理器（可选能力）
+type PrivilegedManager interface {
+	PackageManager
+
+	// RequiresPrivileges 安装是否需要 sudo
+	RequiresPrivileges() bool
+}
+
+// Elevator 在运行开始时验证一次 sudo，并在后台刷新凭据直到 Stop
+type Elevator struct {
+	logger   *logrus.Logger
+	interval time.Duration
+	cancel   context.CancelFunc
+	done     chan struct{}
+	mu       sync.Mutex
+}
+
+// NewElevator 创建 sudo 提权助手
+func NewElevator(logger *logrus.Logger) *Elevator {
+	return &Elevator{
+		logger:   logger,
+		interval: sudoRefreshInterval,
+	}
+}
+
+// ElevationSkipReason 返回不需要（或无法）使用 sudo 的原因，需要 sudo 时返回空字符串
+func ElevationSkipReason() string {
+	if runtime.GOOS == "windows" {
+		return "Windows 不使用 sudo"
+	}
+	if os.Geteuid() == 0 {
+		if inContainer() {
+			return "容器中以 root 运行"
+		}
+		return "已以 root 运行"
+	}
+	if _, err := exec.LookPath("sudo"); err != nil {
+		if inContainer() {
+			return "容器中未安装 sudo"
+		}
+		return "未找到 sudo 命令"
+	}
+	return ""
+}
+
+// Start 验证 sudo 凭据（终端中提示输入一次密码），然后在后台定期刷新
+func (e *Elevator) Start(ctx context.Context, interactive bool) error {
+	if reason := ElevationSkipReason(); reason != "" {
+		e.logger.Debugf("跳过 sudo 提权: %s", reason)
+		return nil
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.cancel != nil {
+		return nil
+	}
+
+	// 非终端环境只能使用已缓存的凭据或免密 sudo
+	args := []string{"-n", "-v"}
+	if interactive {
+		args = []string{"-v"}
+	}
+	// 在自己的前台进程组中提示输入密码，验证完成后交还终端
+	cmd, restore := setForegroundProcessGroup(exec.CommandContext(ctx, "sudo", args...))
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	err := cmd.Run()
+	restore()
+	if err != nil {
+		return fmt.Errorf("sudo 验证失败: %w", err)
+	}
+	e.logger.Debug("sudo 验证通过，启动凭据刷新")
+
+	refreshCtx, cancel := context.WithCancel(ctx)
+	e.cancel = cancel
+	e.done = make(chan struct{})
+	go e.keepAlive(refreshCtx, e.done)
+	return nil
+}
+
+// keepAlive 定期以非交互方式刷新 sudo 时间戳
+func (e *Elevator) keepAlive(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if output, err := setProcessGroup(exec.CommandContext(ctx, "sudo", "-n", "-v")).CombinedOutput(); err != nil && ctx.Err() == nil {
+				e.logger.Warnf("刷新 sudo 凭据失败: %v: %s", err, strings.TrimSpace(string(output)))
+			}
+		}
+	}
+}
+
+// Stop 停止后台刷新并等待刷新协程退出，可重复调用
+func (e *Elevator) Stop() {
+	e.mu.Lock()
+	cancel, done := e.cancel, e.done
+	e.cancel, e.done = nil, nil
+	e.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+	e.logger.Debug("已停止 sudo 凭据刷新")
+}
+
+// RequiresElevation 检查安装这些包时是否会用到需要 sudo 的包管理器
+func (i *Installer) RequiresElevation(packages []string) bool {
+	for _, pkg := range packages {
+		for _, candidate := range i.SelectManagersFor(pkg) {
+			if privileged, ok := candidate.Manager.(PrivilegedManager); ok && privileged.RequiresPrivileges() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// privilegedCommand 以 root 权限在自己的进程组中运行命令，已是 root 时不使用 sudo
+func privilegedCommand(ctx context.Context, name string, args ...string) *exec.Cmd {
+	if os.Geteuid() == 0 {
+		return setProcessGroup(exec.CommandContext(ctx, name, args...))
+	}
+	return setProcessGroup(exec.CommandContext(ctx, "sudo", append([]string{name}, args...)...))
+}
+
+// inContainer 检测是否运行在容器中（Docker、Podman、LXC、Kubernetes）
+func inContainer() bool {
+	for _, marker := range []string{"/.dockerenv", "/run/.containerenv"} {
+		if _, err := os.Stat(marker); err == nil {
+			return true
+		}
+	}
+	if os.Getenv("container") != "" || os.Getenv("KUBERNETES_SERVICE_HOST") != "" {
+		return true
+	}
+
+	data, err := os.ReadFile("/proc/1/cgroup")
+	if err != nil {
+		return false
+	}
+	for _, keyword := range []string{"docker", "containerd", "kubepods", "lxc", "libpod"} {
+		if strings.Contains(string(data), keyword) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,67 @@
+package installer
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// mockPrivilegedManager 需要 sudo 的模拟包管理器
+type mockPrivilegedManager struct {
+	*MockPackageManager
+}
+
+func (m *mockPrivilegedManager) RequiresPrivileges() bool {
+	return true
+}
+
+// TestRequiresElevation 测试只有用到需要 sudo 的包管理器时才提权
+func TestRequiresElevation(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	inst := NewInstaller(logger)
+	inst.RegisterManager(NewMockPackageManager("plain", 1))
+	if inst.RequiresElevation([]string{"fzf"}) {
+		t.Error("没有需要 sudo 的包管理器时不应该提权")
+	}
+
+	inst.RegisterManager(&mockPrivilegedManager{NewMockPackageManager("system", 2)})
+	if !inst.RequiresElevation([]string{"fzf"}) {
+		t.Error("候选包管理器需要 sudo 时应该提权")
+	}
+}
+
+// TestElevator_StopWithoutStart 测试未启动或跳过提权时 Stop 可安全调用
+func TestElevator_StopWithoutStart(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	elevator := NewElevator(logger)
+	elevator.Stop()
+
+	if ElevationSkipReason() == "" {
+		t.Skip("当前环境需要 sudo 验证，跳过")
+	}
+	if err := elevator.Start(context.Background(), false); err != nil {
+		t.Errorf("跳过提权时不应该返回错误: %v", err)
+	}
+	elevator.Stop()
+	elevator.Stop()
+}
+
+// TestPrivilegedCommand 测试 root 运行时不使用 sudo
+func TestPrivilegedCommand(t *testing.T) {
+	cmd := privilegedCommand(context.Background(), "pacman", "-S", "fzf")
+	if os.Geteuid() == 0 {
+		if cmd.Args[0] != "pacman" {
+			t.Errorf("root 运行时不应该使用 sudo，实际: %v", cmd.Args)
+		}
+		return
+	}
+	if cmd.Args[0] != "sudo" || cmd.Args[1] != "pacman" {
+		t.Errorf("非 root 运行时应该使用 sudo，实际: %v", cmd.Args)
+	}
+}
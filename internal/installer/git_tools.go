@@ -0,0 +1,275 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/bbq191/dotfiles-go/internal/config"
+	"github.com/bbq191/dotfiles-go/internal/xdg"
+	"github.com/sirupsen/logrus"
+)
+
+// GitConfigChange git 配置的一项差异
+type GitConfigChange struct {
+	Key     string
+	Current []string // 当前值（未设置时为空）
+	Desired []string // 期望值（多值项如 include.path 保留全部值）
+	Source  string   // 期望值来源（conf/git/config 或 git_tools.<工具名>）
+}
+
+// GitApplyReport git 配置应用结果
+type GitApplyReport struct {
+	ConfigFile        string
+	Changes           []GitConfigChange
+	MissingExtensions []string // 未安装的 gh 扩展
+	Installed         []string // 本次安装的 gh 扩展
+	Errors            []error
+}
+
+// GitApplyOptions git 配置应用选项
+type GitApplyOptions struct {
+	ConfigFile string // 目标配置文件，默认 $XDG_CONFIG_HOME/git/config
+	BaseConfig string // 合并的基础配置文件（conf/git/config），不存在时跳过
+	Check      bool   // 只报告差异，不写入
+}
+
+// GitToolsApplier 将 git_tools 配置写入 git 配置并安装 gh 扩展
+type GitToolsApplier struct {
+	tools  map[string]config.GitTool
+	logger *logrus.Logger
+}
+
+// NewGitToolsApplier 创建 git 工具配置应用器
+func NewGitToolsApplier(tools map[string]config.GitTool, logger *logrus.Logger) *GitToolsApplier {
+	return &GitToolsApplier{
+		tools:  tools,
+		logger: logger,
+	}
+}
+
+// DefaultGitConfigFile 返回 $XDG_CONFIG_HOME/git/config
+func DefaultGitConfigFile(logger *logrus.Logger) (string, error) {
+	configHome, err := xdg.NewManager(logger, runtime.GOOS).GetXDGPath(xdg.ConfigHome)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configHome, "git", "config"), nil
+}
+
+// Apply 比较并应用 git 配置，安装缺失的 gh 扩展
+func (ga *GitToolsApplier) Apply(ctx context.Context, opts GitApplyOptions) (*GitApplyReport, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, fmt.Errorf("未找到 git 命令")
+	}
+
+	report := &GitApplyReport{ConfigFile: opts.ConfigFile}
+	desired, err := ga.desiredEntries(ctx, opts.BaseConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := readGitConfigFile(ctx, opts.ConfigFile)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range desired {
+		values := current[normalizeGitKey(entry.Key)]
+		if slices.Equal(values, entry.Desired) {
+			continue
+		}
+		entry.Current = values
+		report.Changes = append(report.Changes, entry)
+	}
+
+	if !opts.Check && len(report.Changes) > 0 {
+		if err := os.MkdirAll(filepath.Dir(opts.ConfigFile), 0755); err != nil {
+			return nil, fmt.Errorf("创建目录失败: %w", err)
+		}
+		for _, change := range report.Changes {
+			if err := writeGitConfigValues(ctx, opts.ConfigFile, change.Key, change.Desired); err != nil {
+				report.Errors = append(report.Errors, err)
+				continue
+			}
+			ga.logger.Infof("已写入 %s = %s", change.Key, strings.Join(change.Desired, ", "))
+		}
+	}
+
+	ga.applyExtensions(ctx, report, opts.Check)
+	return report, nil
+}
+
+// desiredEntries 合并 conf/git/config 和启用工具的 git_config（后者优先）
+func (ga *GitToolsApplier) desiredEntries(ctx context.Context, baseConfig string) ([]GitConfigChange, error) {
+	entries := make(map[string]GitConfigChange)
+
+	if baseConfig != "" {
+		if _, err := os.Stat(baseConfig); err == nil {
+			base, err := readGitConfigFile(ctx, baseConfig)
+			if err != nil {
+				return nil, err
+			}
+			for key, values := range base {
+				entries[key] = GitConfigChange{Key: key, Desired: values, Source: baseConfig}
+			}
+		} else {
+			ga.logger.Debugf("基础 git 配置 %s 不存在，跳过合并", baseConfig)
+		}
+	}
+
+	for _, name := range ga.enabledTools() {
+		for key, value := range ga.tools[name].GitConfig {
+			entries[normalizeGitKey(key)] = GitConfigChange{Key: key, Desired: []string{value}, Source: "git_tools." + name}
+		}
+	}
+
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	result := make([]GitConfigChange, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, entries[key])
+	}
+	return result, nil
+}
+
+// applyExtensions 检查并安装 gh 扩展
+func (ga *GitToolsApplier) applyExtensions(ctx context.Context, report *GitApplyReport, check bool) {
+	wanted := make([]string, 0)
+	for _, name := range ga.enabledTools() {
+		wanted = append(wanted, ga.tools[name].Extensions...)
+	}
+	if len(wanted) == 0 {
+		return
+	}
+
+	if _, err := exec.LookPath("gh"); err != nil {
+		report.Errors = append(report.Errors, fmt.Errorf("未找到 gh 命令，无法安装扩展: %s", strings.Join(wanted, ", ")))
+		return
+	}
+
+	output, err := exec.CommandContext(ctx, "gh", "extension", "list").Output()
+	if err != nil {
+		report.Errors = append(report.Errors, fmt.Errorf("gh extension list 执行失败: %w", err))
+		return
+	}
+	installed := parseGhExtensionList(string(output))
+
+	for _, extension := range wanted {
+		repo := ghExtensionRepo(extension)
+		if installed[extensionName(repo)] {
+			continue
+		}
+		report.MissingExtensions = append(report.MissingExtensions, repo)
+		if check {
+			continue
+		}
+
+		ga.logger.Infof("安装 gh 扩展: %s", repo)
+		cmd := setProcessGroup(exec.CommandContext(ctx, "gh", "extension", "install", repo))
+		if output, err := cmd.CombinedOutput(); err != nil {
+			report.Errors = append(report.Errors, fmt.Errorf("安装 gh 扩展 %s 失败: %v: %s", repo, err, strings.TrimSpace(string(output))))
+			continue
+		}
+		report.Installed = append(report.Installed, repo)
+	}
+}
+
+// enabledTools 返回启用的工具名称（已排序）
+func (ga *GitToolsApplier) enabledTools() []string {
+	names := make([]string, 0, len(ga.tools))
+	for name, tool := range ga.tools {
+		if tool.Enabled {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// readGitConfigFile 读取配置文件中的所有项（键经 normalizeGitKey 规范化），文件不存在时返回空
+func readGitConfigFile(ctx context.Context, path string) (map[string][]string, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return map[string][]string{}, nil
+	}
+
+	output, err := exec.CommandContext(ctx, "git", "config", "--file", path, "--list", "--null").Output()
+	if err != nil {
+		return nil, fmt.Errorf("读取 git 配置 %s 失败: %w", path, err)
+	}
+	return parseGitConfigList(string(output)), nil
+}
+
+// parseGitConfigList 解析 git config --list --null 输出（每项为 "键\n值\x00"）
+func parseGitConfigList(output string) map[string][]string {
+	entries := make(map[string][]string)
+	for _, item := range strings.Split(output, "\x00") {
+		if item == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(item, "\n")
+		key = normalizeGitKey(key)
+		entries[key] = append(entries[key], value)
+	}
+	return entries
+}
+
+// writeGitConfigValues 用 git config --file 写入配置项，多值项先替换再追加
+func writeGitConfigValues(ctx context.Context, file, key string, values []string) error {
+	for i, value := range values {
+		args := []string{"config", "--file", file, "--replace-all", key, value}
+		if i > 0 {
+			args = []string{"config", "--file", file, "--add", key, value}
+		}
+		if output, err := exec.CommandContext(ctx, "git", args...).CombinedOutput(); err != nil {
+			return fmt.Errorf("写入 %s 失败: %v: %s", key, err, strings.TrimSpace(string(output)))
+		}
+	}
+	return nil
+}
+
+// normalizeGitKey 规范化配置键：节名和变量名不区分大小写，子节名区分大小写
+func normalizeGitKey(key string) string {
+	first, last := strings.Index(key, "."), strings.LastIndex(key, ".")
+	if first < 0 {
+		return strings.ToLower(key)
+	}
+	return strings.ToLower(key[:first]) + key[first:last] + strings.ToLower(key[last:])
+}
+
+// parseGhExtensionList 解析 gh extension list 输出，返回已安装扩展的仓库名（如 gh-copilot）
+func parseGhExtensionList(output string) map[string]bool {
+	installed := make(map[string]bool)
+	for _, line := range strings.Split(output, "\n") {
+		for _, field := range strings.Fields(line) {
+			if strings.Contains(field, "/") {
+				installed[extensionName(field)] = true
+				break
+			}
+		}
+	}
+	return installed
+}
+
+// ghExtensionRepo 将扩展名补全为 OWNER/REPO（未指定所有者时使用 github）
+func ghExtensionRepo(extension string) string {
+	if strings.Contains(extension, "/") {
+		return extension
+	}
+	return "github/" + extension
+}
+
+// extensionName 返回扩展仓库名
+func extensionName(repo string) string {
+	return repo[strings.LastIndex(repo, "/")+1:]
+}
@@ -0,0 +1,87 @@
+package installer
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/bbq191/dotfiles-go/internal/config"
+	"github.com/sirupsen/logrus"
+)
+
+// TestGitToolsApplier_Apply 测试合并 conf/git/config 和 git_config 并写入目标文件
+func TestGitToolsApplier_Apply(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git 不可用，跳过测试")
+	}
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base")
+	target := filepath.Join(dir, "git", "config")
+	baseContent := "[core]\n\tpager = less\n[merge]\n\tconflictstyle = diff3\n"
+	if err := os.WriteFile(base, []byte(baseContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	applier := NewGitToolsApplier(map[string]config.GitTool{
+		"delta":    {Enabled: true, GitConfig: map[string]string{"core.pager": "delta", "diff.colorMoved": "default"}},
+		"disabled": {Enabled: false, GitConfig: map[string]string{"core.editor": "nano"}},
+	}, logger)
+	opts := GitApplyOptions{ConfigFile: target, BaseConfig: base, Check: true}
+
+	report, err := applier.Apply(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("检查失败: %v", err)
+	}
+	if len(report.Changes) != 3 {
+		t.Fatalf("期望 3 项差异，实际为 %d: %+v", len(report.Changes), report.Changes)
+	}
+	if _, err := os.Stat(target); !os.IsNotExist(err) {
+		t.Error("--check 模式不应该写入文件")
+	}
+
+	opts.Check = false
+	if _, err := applier.Apply(context.Background(), opts); err != nil {
+		t.Fatalf("应用失败: %v", err)
+	}
+
+	written, err := readGitConfigFile(context.Background(), target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if written["core.pager"][0] != "delta" {
+		t.Errorf("git_config 应该覆盖基础配置，实际 core.pager = %v", written["core.pager"])
+	}
+	if written["diff.colormoved"][0] != "default" || written["merge.conflictstyle"][0] != "diff3" {
+		t.Errorf("写入结果错误: %v", written)
+	}
+	if _, ok := written["core.editor"]; ok {
+		t.Error("未启用的工具不应该写入配置")
+	}
+
+	opts.Check = true
+	report, err = applier.Apply(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Changes) != 0 {
+		t.Errorf("应用后不应该再有差异，实际: %+v", report.Changes)
+	}
+}
+
+// TestParseGhExtensionList 测试 gh extension list 输出解析
+func TestParseGhExtensionList(t *testing.T) {
+	output := "gh copilot\tgithub/gh-copilot\tv1.0.5\ngh dash\tdlvhdr/gh-dash\tv4.7.0\n"
+	installed := parseGhExtensionList(output)
+	if !installed["gh-copilot"] || !installed["gh-dash"] {
+		t.Errorf("解析结果错误: %v", installed)
+	}
+	if ghExtensionRepo("gh-copilot") != "github/gh-copilot" {
+		t.Errorf("未指定所有者的扩展应该补全为 github/gh-copilot")
+	}
+}
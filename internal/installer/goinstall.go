@@ -0,0 +1,198 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// majorVersionSuffix 匹配 Go 模块路径中的主版本后缀（如 /v2）
+var majorVersionSuffix = regexp.MustCompile(`^v\d+$`)
+
+// GoInstallManager go install 包管理器实现
+//
+// 包名为完整的包路径，例如 "golang.org/x/tools/gopls"，可带 "@版本" 后缀，默认安装 @latest。
+type GoInstallManager struct {
+	toolEnvironment
+	logger *logrus.Logger
+}
+
+// NewGoInstallManager 创建Go Install管理器实例
+func NewGoInstallManager(logger *logrus.Logger) *GoInstallManager {
+	return &GoInstallManager{
+		logger: logger,
+	}
+}
+
+// Name 返回包管理器名称
+func (g *GoInstallManager) Name() string {
+	return "go"
+}
+
+// IsAvailable 检查go是否可用
+func (g *GoInstallManager) IsAvailable() bool {
+	_, err := exec.LookPath("go")
+	available := err == nil
+	g.logger.Debugf("Go 可用性检查: %v", available)
+	return available
+}
+
+// Install 安装Go程序
+func (g *GoInstallManager) Install(ctx context.Context, packageName string) error {
+	target := packageName
+	if !strings.Contains(target, "@") {
+		target += "@latest"
+	}
+	return g.runInstall(ctx, packageName, target)
+}
+
+// InstallVersion 安装指定版本的Go程序
+func (g *GoInstallManager) InstallVersion(ctx context.Context, packageName, version string) error {
+	if !strings.HasPrefix(version, "v") {
+		version = "v" + version
+	}
+	return g.runInstall(ctx, packageName, goPackagePath(packageName)+"@"+version)
+}
+
+// runInstall 执行 go install
+func (g *GoInstallManager) runInstall(ctx context.Context, packageName, target string) error {
+	g.logger.Infof("使用 go install 安装包: %s", target)
+
+	cmd := setProcessGroup(g.command(exec.CommandContext(ctx, "go", "install", target)))
+	g.logger.Debugf("执行命令: go install %s", target)
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		g.logger.Errorf("安装 %s 失败: %v", packageName, err)
+		g.logger.Debugf("命令输出: %s", string(output))
+		if notFound := notFoundIn(g.Name(), packageName, string(output),
+			"cannot find module providing package", "no matching versions", "not found: "); notFound != nil {
+			return notFound
+		}
+		return fmt.Errorf("go install 失败: %w", err)
+	}
+
+	g.logger.Infof("成功安装 %s", packageName)
+	return nil
+}
+
+// IsInstalled 检查程序是否已安装（二进制存在且由该包路径构建）
+func (g *GoInstallManager) IsInstalled(packageName string) bool {
+	info, err := g.buildInfo(packageName)
+	if err != nil {
+		g.logger.Debugf("包 %s 安装状态检查: %v", packageName, err)
+		return false
+	}
+	return info["path"] == goPackagePath(packageName)
+}
+
+// InstalledVersion 返回已安装的模块版本
+func (g *GoInstallManager) InstalledVersion(packageName string) (string, error) {
+	info, err := g.buildInfo(packageName)
+	if err != nil {
+		return "", err
+	}
+	version, ok := info["mod"]
+	if !ok {
+		return "", fmt.Errorf("无法读取 %s 的模块版本", packageName)
+	}
+	return strings.TrimPrefix(version, "v"), nil
+}
+
+// AvailableVersion go install 按包路径安装，无法在安装前可靠地查询模块最新版本
+func (g *GoInstallManager) AvailableVersion(packageName string) (string, error) {
+	return "", fmt.Errorf("go install 不支持查询 %s 的可用版本", packageName)
+}
+
+// Priority 返回优先级
+func (g *GoInstallManager) Priority() int {
+	return 11
+}
+
+// buildInfo 读取已安装二进制的构建信息（go version -m）
+func (g *GoInstallManager) buildInfo(packageName string) (map[string]string, error) {
+	binDir, err := g.binDir()
+	if err != nil {
+		return nil, err
+	}
+
+	binary := filepath.Join(binDir, goBinaryName(packageName))
+	if runtime.GOOS == "windows" {
+		binary += ".exe"
+	}
+	if _, err := os.Stat(binary); err != nil {
+		return nil, err
+	}
+
+	output, err := g.command(exec.Command("go", "version", "-m", binary)).Output()
+	if err != nil {
+		return nil, err
+	}
+	return parseGoBuildInfo(string(output)), nil
+}
+
+// binDir 返回 go install 的目标目录（GOBIN 或 GOPATH/bin）
+func (g *GoInstallManager) binDir() (string, error) {
+	if gobin := g.lookup("GOBIN"); gobin != "" {
+		return gobin, nil
+	}
+
+	output, err := g.command(exec.Command("go", "env", "GOBIN", "GOPATH")).Output()
+	if err != nil {
+		return "", fmt.Errorf("读取 go env 失败: %w", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(lines) > 0 && strings.TrimSpace(lines[0]) != "" {
+		return strings.TrimSpace(lines[0]), nil
+	}
+	if len(lines) > 1 {
+		gopath := filepath.SplitList(strings.TrimSpace(lines[1]))
+		if len(gopath) > 0 && gopath[0] != "" {
+			return filepath.Join(gopath[0], "bin"), nil
+		}
+	}
+	return "", fmt.Errorf("无法确定 go install 的安装目录")
+}
+
+// goPackagePath 去除包名中的 "@版本" 后缀
+func goPackagePath(packageName string) string {
+	path, _, _ := strings.Cut(packageName, "@")
+	return path
+}
+
+// goBinaryName 返回 go install 生成的二进制名（包路径最后一段，跳过 /vN 主版本后缀）
+func goBinaryName(packageName string) string {
+	segments := strings.Split(goPackagePath(packageName), "/")
+	name := segments[len(segments)-1]
+	if majorVersionSuffix.MatchString(name) && len(segments) > 1 {
+		name = segments[len(segments)-2]
+	}
+	return name
+}
+
+// parseGoBuildInfo 解析 go version -m 输出中的 path 和 mod 行
+func parseGoBuildInfo(output string) map[string]string {
+	info := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "path":
+			info["path"] = fields[1]
+		case "mod":
+			if len(fields) >= 3 {
+				info["mod"] = fields[2]
+			}
+		}
+	}
+	return info
+}
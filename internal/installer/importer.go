@@ -0,0 +1,352 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bbq191/dotfiles-go/internal/config"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultImportCategory 无法推断分类时使用的分类
+const DefaultImportCategory = "imported"
+
+// ImportedPackage 从本机包管理器读取到的已安装包
+type ImportedPackage struct {
+	Name        string   // 包管理器中的包名
+	Manager     string   // 来源包管理器
+	Description string   // 包管理器提供的描述
+	Groups      []string // 包组（pacman）
+}
+
+// PackageLister 可列出本机显式安装包的包管理器（可选能力）
+type PackageLister interface {
+	PackageManager
+
+	// ListExplicitPackages 列出显式安装的包
+	ListExplicitPackages(ctx context.Context) ([]ImportedPackage, error)
+}
+
+// ImportOptions 导入选项
+type ImportOptions struct {
+	Category  string                 // 指定新包的分类，为空时根据标签推断
+	Inherited *config.PackagesConfig // 合并 extends/include 后的清单，其中已映射的包不重复导入
+}
+
+// ImportChange 导入对包清单的一项修改
+type ImportChange struct {
+	Key      string // 清单中的包名
+	Category string
+	Manager  string
+	Added    bool // true 表示新增包，false 表示为已有包补充映射或描述
+}
+
+// ImportReport 导入结果
+type ImportReport struct {
+	Changes   []ImportChange
+	Unchanged int
+}
+
+// Added 返回新增包的数量
+func (r *ImportReport) Added() int {
+	count := 0
+	for _, change := range r.Changes {
+		if change.Added {
+			count++
+		}
+	}
+	return count
+}
+
+// PackageRefs 返回有修改的包，用于只写回这些包
+func (r *ImportReport) PackageRefs() []config.PackageRef {
+	refs := make([]config.PackageRef, 0, len(r.Changes))
+	for _, change := range r.Changes {
+		refs = append(refs, config.PackageRef{Category: change.Category, Key: change.Key})
+	}
+	return refs
+}
+
+// ListInstalledPackages 从所有可用且支持列出的包管理器读取显式安装的包
+func (i *Installer) ListInstalledPackages(ctx context.Context, managerNames []string) ([]ImportedPackage, error) {
+	wanted := make(map[string]bool)
+	for _, name := range managerNames {
+		wanted[name] = true
+	}
+
+	var packages []ImportedPackage
+	for _, manager := range i.GetAvailableManagers() {
+		if len(wanted) > 0 && !wanted[manager.Name()] {
+			continue
+		}
+		lister, ok := manager.(PackageLister)
+		if !ok {
+			i.logger.Debugf("包管理器 %s 不支持列出已安装包", manager.Name())
+			continue
+		}
+
+		listed, err := lister.ListExplicitPackages(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("读取 %s 已安装包失败: %w", manager.Name(), err)
+		}
+		i.logger.Infof("从 %s 读取到 %d 个显式安装的包", manager.Name(), len(listed))
+		packages = append(packages, listed...)
+	}
+
+	return packages, nil
+}
+
+// ManifestImporter 将已安装包合并到包清单
+type ManifestImporter struct {
+	manifest *config.PackagesConfig
+	logger   *logrus.Logger
+}
+
+// NewManifestImporter 创建清单导入器，manifest 为 nil 时从空清单开始
+func NewManifestImporter(manifest *config.PackagesConfig, logger *logrus.Logger) *ManifestImporter {
+	if manifest == nil {
+		manifest = &config.PackagesConfig{Version: config.CurrentConfigVersion}
+	}
+	if manifest.Categories == nil {
+		manifest.Categories = make(map[string]config.Category)
+	}
+	return &ManifestImporter{
+		manifest: manifest,
+		logger:   logger,
+	}
+}
+
+// Manifest 返回合并后的包清单
+func (mi *ManifestImporter) Manifest() *config.PackagesConfig {
+	return mi.manifest
+}
+
+// Merge 合并已安装包
+//
+// 已在清单中的包（按管理器映射或包名匹配）只补充缺失的映射和描述；opts.Inherited 中已有该管理器映射的包不处理，
+// 也不按包名匹配到本文件的同名包，避免把继承来的映射写回本文件。新包放入 opts.Category，未指定时根据已有标签推断分类，推断失败时放入 DefaultImportCategory。
+func (mi *ManifestImporter) Merge(packages []ImportedPackage, opts ImportOptions) *ImportReport {
+	report := &ImportReport{}
+	index := mi.buildIndex()
+	tags := mi.buildTagIndex()
+	inherited := buildManagerIndex(opts.Inherited)
+
+	sorted := append([]ImportedPackage(nil), packages...)
+	sort.Slice(sorted, func(a, b int) bool {
+		if sorted[a].Manager != sorted[b].Manager {
+			return sorted[a].Manager < sorted[b].Manager
+		}
+		return sorted[a].Name < sorted[b].Name
+	})
+
+	for _, pkg := range sorted {
+		categoryName, key, found := mi.findMapped(index, pkg)
+		if !found {
+			if _, ok := inherited[pkg.Manager+"/"+pkg.Name]; ok {
+				report.Unchanged++
+				continue
+			}
+			categoryName, key, found = mi.findByName(pkg)
+		}
+		if found {
+			if mi.updateExisting(categoryName, key, pkg) {
+				report.Changes = append(report.Changes, ImportChange{Key: key, Category: categoryName, Manager: pkg.Manager})
+			} else {
+				report.Unchanged++
+			}
+			index[pkg.Manager+"/"+pkg.Name] = categoryName + "/" + key
+			continue
+		}
+
+		categoryName, pkgTags := opts.Category, []string(nil)
+		if categoryName == "" {
+			categoryName, pkgTags = inferCategory(tags, pkg)
+		}
+		key = mi.uniqueKey(manifestKeyFor(pkg), pkg)
+
+		mi.addPackage(categoryName, key, config.PackageInfo{
+			Description: pkg.Description,
+			Tags:        pkgTags,
+			Managers:    map[string]string{pkg.Manager: pkg.Name},
+		})
+		index[pkg.Manager+"/"+pkg.Name] = categoryName + "/" + key
+		report.Changes = append(report.Changes, ImportChange{Key: key, Category: categoryName, Manager: pkg.Manager, Added: true})
+	}
+
+	return report
+}
+
+// buildIndex 建立 "管理器/包名" -> "分类/清单包名" 索引
+func (mi *ManifestImporter) buildIndex() map[string]string {
+	return buildManagerIndex(mi.manifest)
+}
+
+// buildManagerIndex 建立清单的 "管理器/包名" -> "分类/清单包名" 索引
+func buildManagerIndex(manifest *config.PackagesConfig) map[string]string {
+	index := make(map[string]string)
+	if manifest == nil {
+		return index
+	}
+	for categoryName, category := range manifest.Categories {
+		for key, info := range category.Packages {
+			for manager, mapped := range info.Managers {
+				index[manager+"/"+mapped] = categoryName + "/" + key
+			}
+		}
+	}
+	return index
+}
+
+// findMapped 按管理器映射查找清单中已存在的包
+func (mi *ManifestImporter) findMapped(index map[string]string, pkg ImportedPackage) (string, string, bool) {
+	if location, ok := index[pkg.Manager+"/"+pkg.Name]; ok {
+		parts := strings.SplitN(location, "/", 2)
+		return parts[0], parts[1], true
+	}
+	return "", "", false
+}
+
+// findByName 查找包名相同但缺少该管理器映射的包（例如 pacman 包在清单中只写了 yay 映射）
+func (mi *ManifestImporter) findByName(pkg ImportedPackage) (string, string, bool) {
+	key := manifestKeyFor(pkg)
+	for categoryName, category := range mi.manifest.Categories {
+		if info, ok := category.Packages[key]; ok {
+			if _, mapped := info.Managers[pkg.Manager]; !mapped {
+				return categoryName, key, true
+			}
+		}
+	}
+	return "", "", false
+}
+
+// updateExisting 为已有包补充映射和描述，返回是否有修改
+func (mi *ManifestImporter) updateExisting(categoryName, key string, pkg ImportedPackage) bool {
+	category := mi.manifest.Categories[categoryName]
+	info := category.Packages[key]
+	changed := false
+
+	if _, ok := info.Managers[pkg.Manager]; !ok {
+		if info.Managers == nil {
+			info.Managers = make(map[string]string)
+		}
+		info.Managers[pkg.Manager] = pkg.Name
+		changed = true
+	}
+	if info.Description == "" && pkg.Description != "" {
+		info.Description = pkg.Description
+		changed = true
+	}
+
+	category.Packages[key] = info
+	mi.manifest.Categories[categoryName] = category
+	return changed
+}
+
+// addPackage 向分类添加包，分类不存在时创建
+func (mi *ManifestImporter) addPackage(categoryName, key string, info config.PackageInfo) {
+	category, exists := mi.manifest.Categories[categoryName]
+	if !exists {
+		category = config.Category{
+			Description: "Packages imported from this machine",
+			Priority:    mi.nextPriority(),
+		}
+		mi.logger.Infof("创建新分类: %s (优先级: %d)", categoryName, category.Priority)
+	}
+	if category.Packages == nil {
+		category.Packages = make(map[string]config.PackageInfo)
+	}
+	category.Packages[key] = info
+	mi.manifest.Categories[categoryName] = category
+}
+
+// nextPriority 返回比现有分类更低的优先级
+func (mi *ManifestImporter) nextPriority() int {
+	max := 0
+	for _, category := range mi.manifest.Categories {
+		if category.Priority > max {
+			max = category.Priority
+		}
+	}
+	return max + 1
+}
+
+// uniqueKey 确保清单包名不与其他包冲突
+func (mi *ManifestImporter) uniqueKey(key string, pkg ImportedPackage) string {
+	for _, category := range mi.manifest.Categories {
+		if _, exists := category.Packages[key]; exists {
+			return strings.ToLower(pkg.Manager + "-" + pkg.Name)
+		}
+	}
+	return key
+}
+
+// buildTagIndex 统计每个标签在各分类中出现的次数
+func (mi *ManifestImporter) buildTagIndex() map[string]map[string]int {
+	tags := make(map[string]map[string]int)
+	for categoryName, category := range mi.manifest.Categories {
+		for _, info := range category.Packages {
+			for _, tag := range info.Tags {
+				tag = strings.ToLower(tag)
+				if tags[tag] == nil {
+					tags[tag] = make(map[string]int)
+				}
+				tags[tag][categoryName]++
+			}
+		}
+	}
+	return tags
+}
+
+// inferCategory 根据包名、包组和描述中出现的已有标签推断分类
+func inferCategory(tags map[string]map[string]int, pkg ImportedPackage) (string, []string) {
+	words := make(map[string]bool)
+	addWords := func(text string) {
+		for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+			return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-')
+		}) {
+			words[word] = true
+		}
+	}
+	addWords(pkg.Name)
+	addWords(pkg.Description)
+	for _, group := range pkg.Groups {
+		addWords(group)
+	}
+
+	scores := make(map[string]int)
+	var matched []string
+	for tag, categories := range tags {
+		if !words[tag] {
+			continue
+		}
+		matched = append(matched, tag)
+		for categoryName, count := range categories {
+			scores[categoryName] += count
+		}
+	}
+	if len(scores) == 0 {
+		return DefaultImportCategory, nil
+	}
+
+	best := ""
+	for categoryName, score := range scores {
+		if best == "" || score > scores[best] || score == scores[best] && categoryName < best {
+			best = categoryName
+		}
+	}
+	sort.Strings(matched)
+	return best, matched
+}
+
+// manifestKeyFor 生成包在清单中的名称（winget ID 取最后一段并转为小写）
+func manifestKeyFor(pkg ImportedPackage) string {
+	if pkg.Manager == "winget" {
+		if idx := strings.LastIndex(pkg.Name, "."); idx >= 0 && idx < len(pkg.Name)-1 {
+			return strings.ToLower(pkg.Name[idx+1:])
+		}
+		return strings.ToLower(pkg.Name)
+	}
+	return pkg.Name
+}
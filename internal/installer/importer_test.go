@@ -0,0 +1,188 @@
+package installer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bbq191/dotfiles-go/internal/config"
+	"github.com/sirupsen/logrus"
+)
+
+func newImportTestManifest() *config.PackagesConfig {
+	return &config.PackagesConfig{
+		Categories: map[string]config.Category{
+			"essential": {
+				Priority: 1,
+				Packages: map[string]config.PackageInfo{
+					"git":  {Tags: []string{"vcs", "git"}, Managers: map[string]string{"yay": "git"}},
+					"wget": {Description: "Network utility", Tags: []string{"network"}, Managers: map[string]string{"pacman": "wget"}},
+				},
+			},
+			"shell_enhancement": {
+				Priority: 8,
+				Packages: map[string]config.PackageInfo{
+					"zsh": {Tags: []string{"shell", "zsh"}, Managers: map[string]string{"pacman": "zsh"}},
+				},
+			},
+		},
+	}
+}
+
+// TestManifestImporter_Merge 测试合并已安装包
+func TestManifestImporter_Merge(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	importer := NewManifestImporter(newImportTestManifest(), logger)
+	report := importer.Merge([]ImportedPackage{
+		{Name: "git", Manager: "pacman", Description: "the fast distributed version control system"},
+		{Name: "wget", Manager: "pacman", Description: "Network utility to retrieve files"},
+		{Name: "fish", Manager: "pacman", Description: "Smart and user friendly command line shell"},
+		{Name: "libfoo", Manager: "yay", Description: "Some library"},
+	}, ImportOptions{})
+
+	if report.Added() != 2 || report.Unchanged != 1 {
+		t.Errorf("期望新增 2 个、未变化 1 个，实际新增 %d、未变化 %d", report.Added(), report.Unchanged)
+	}
+
+	manifest := importer.Manifest()
+	git := manifest.Categories["essential"].Packages["git"]
+	if git.Managers["pacman"] != "git" {
+		t.Error("已有包应该补充 pacman 映射")
+	}
+	if git.Description == "" {
+		t.Error("已有包缺少描述时应该使用包管理器的描述")
+	}
+
+	fish, ok := manifest.Categories["shell_enhancement"].Packages["fish"]
+	if !ok {
+		t.Fatal("fish 应该根据 shell 标签放入 shell_enhancement 分类")
+	}
+	if len(fish.Tags) != 1 || fish.Tags[0] != "shell" {
+		t.Errorf("期望推断出标签 [shell]，实际为 %v", fish.Tags)
+	}
+
+	imported, ok := manifest.Categories[DefaultImportCategory]
+	if !ok || imported.Packages["libfoo"].Managers["yay"] != "libfoo" {
+		t.Error("无法推断分类的包应该放入默认分类")
+	}
+	if imported.Priority != 9 {
+		t.Errorf("新分类优先级应该排在最后，实际为 %d", imported.Priority)
+	}
+}
+
+// TestManifestImporter_Extends 测试导入到 extends 清单时，继承来的映射不写回本文件
+func TestManifestImporter_Extends(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	dir := t.TempDir()
+	writeFile := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	writeFile("linux.json", `{
+  "categories": {
+    "essential": {"priority": 1, "packages": {"git": {"managers": {"pacman": "git"}}}}
+  }
+}`)
+	// arch.json 中精简的 git 只补充标签，没有 pacman 映射
+	archPath := writeFile("arch.json", `{
+  "extends": "linux.json",
+  "categories": {
+    "essential": {"priority": 1, "packages": {"git": {"tags": ["vcs"]}}}
+  }
+}`)
+
+	manifest, err := config.LoadPackagesFile(archPath)
+	if err != nil {
+		t.Fatalf("读取 arch.json 失败: %v", err)
+	}
+	inherited, _, err := config.LoadComposedPackagesFile(archPath)
+	if err != nil {
+		t.Fatalf("合并 arch.json 失败: %v", err)
+	}
+
+	importer := NewManifestImporter(manifest, logger)
+	report := importer.Merge([]ImportedPackage{
+		{Name: "git", Manager: "pacman"},
+		{Name: "git", Manager: "yay"},
+	}, ImportOptions{Inherited: inherited})
+
+	git := importer.Manifest().Categories["essential"].Packages["git"]
+	if _, ok := git.Managers["pacman"]; ok {
+		t.Error("linux.json 中已有的 pacman 映射不应该写回 arch.json")
+	}
+	if git.Managers["yay"] != "git" {
+		t.Errorf("未继承的 yay 映射应该补充到 arch.json 的同名包，实际: %v", git.Managers)
+	}
+	if report.Unchanged != 1 || report.Added() != 0 {
+		t.Errorf("期望 1 个未变化、没有新增，实际未变化 %d、新增 %d", report.Unchanged, report.Added())
+	}
+}
+
+// TestManifestImporter_ExplicitCategory 测试指定分类和 winget 包名
+func TestManifestImporter_ExplicitCategory(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	importer := NewManifestImporter(nil, logger)
+	importer.Merge([]ImportedPackage{
+		{Name: "Microsoft.WindowsTerminal", Manager: "winget", Description: "The new Windows Terminal"},
+	}, ImportOptions{Category: "work"})
+
+	info, ok := importer.Manifest().Categories["work"].Packages["windowsterminal"]
+	if !ok {
+		t.Fatal("winget 包应该以 ID 最后一段作为清单包名放入指定分类")
+	}
+	if info.Managers["winget"] != "Microsoft.WindowsTerminal" {
+		t.Errorf("winget 映射错误: %v", info.Managers)
+	}
+}
+
+// TestParseLocalPackageInfo 测试 pacman -Qi 输出解析
+func TestParseLocalPackageInfo(t *testing.T) {
+	output := `Name            : base-devel
+Version         : 1-1
+Description     : Basic tools to build Arch Linux packages
+Groups          : None
+
+Name            : xorg-xauth
+Version         : 1.1.3-1
+Description     : X.Org authorization settings program
+Groups          : xorg xorg-apps
+`
+	packages := parseLocalPackageInfo(output)
+	if packages["base-devel"].Description != "Basic tools to build Arch Linux packages" {
+		t.Errorf("描述解析错误: %q", packages["base-devel"].Description)
+	}
+	if len(packages["base-devel"].Groups) != 0 {
+		t.Error("Groups 为 None 时不应该记录包组")
+	}
+	if groups := packages["xorg-xauth"].Groups; len(groups) != 2 || groups[1] != "xorg-apps" {
+		t.Errorf("包组解析错误: %v", groups)
+	}
+}
+
+// TestParseWingetExport 测试 winget export 文件解析
+func TestParseWingetExport(t *testing.T) {
+	data := []byte(`{
+  "Sources": [
+    {"Packages": [{"PackageIdentifier": "Git.Git"}, {"PackageIdentifier": "Microsoft.PowerShell"}],
+     "SourceDetails": {"Name": "winget"}},
+    {"Packages": [{"PackageIdentifier": "9NBLGGH4NNS1"}],
+     "SourceDetails": {"Name": "msstore"}}
+  ]
+}`)
+	ids, err := parseWingetExport(data)
+	if err != nil {
+		t.Fatalf("解析应该成功: %v", err)
+	}
+	if len(ids) != 2 || ids[0] != "Git.Git" {
+		t.Errorf("期望只导入 winget 源中的 2 个包，实际为 %v", ids)
+	}
+}
@@ -0,0 +1,154 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// InstalledSnapshotter 能一次性列出所有已安装包的包管理器（可选能力）
+//
+// 安装器在一次运行中缓存快照，用于替代逐包调用 IsInstalled。
+type InstalledSnapshotter interface {
+	PackageManager
+
+	// InstalledSnapshot 返回当前已安装的包集合
+	InstalledSnapshot(ctx context.Context) (*InstalledSet, error)
+}
+
+// InstalledSet 已安装包的集合
+type InstalledSet struct {
+	names      map[string]bool
+	prefixes   []string // 输出中被截断的包名（去掉末尾 "…"），按前缀匹配
+	ignoreCase bool
+}
+
+// NewInstalledSet 创建已安装包集合，ignoreCase 为 true 时包名不区分大小写（如 winget ID）
+func NewInstalledSet(names []string, ignoreCase bool) *InstalledSet {
+	set := &InstalledSet{names: make(map[string]bool, len(names)), ignoreCase: ignoreCase}
+	for _, name := range names {
+		set.add(name)
+	}
+	return set
+}
+
+// add 添加包名，以 "…" 结尾的截断包名按前缀记录
+func (s *InstalledSet) add(name string) {
+	name = s.normalize(name)
+	if name == "" {
+		return
+	}
+	if prefix, truncated := strings.CutSuffix(name, "…"); truncated {
+		s.prefixes = append(s.prefixes, prefix)
+		return
+	}
+	s.names[name] = true
+}
+
+// Contains 检查包是否已安装
+func (s *InstalledSet) Contains(packageName string) bool {
+	packageName = s.normalize(packageName)
+	if s.names[packageName] {
+		return true
+	}
+	for _, prefix := range s.prefixes {
+		if strings.HasPrefix(packageName, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Len 返回集合中的包数量
+func (s *InstalledSet) Len() int {
+	return len(s.names) + len(s.prefixes)
+}
+
+func (s *InstalledSet) normalize(name string) string {
+	name = strings.TrimSpace(name)
+	if s.ignoreCase {
+		return strings.ToLower(name)
+	}
+	return name
+}
+
+// isInstalled 检查包是否已安装，优先使用本次运行缓存的快照，不支持或获取失败时回退到 IsInstalled
+func (i *Installer) isInstalled(manager PackageManager, packageName string) bool {
+	snapshotter, ok := manager.(InstalledSnapshotter)
+	if !ok {
+		return manager.IsInstalled(packageName)
+	}
+
+	i.installedMu.Lock()
+	set, cached := i.installed[manager.Name()]
+	if !cached {
+		var err error
+		set, err = snapshotter.InstalledSnapshot(context.Background())
+		if err != nil {
+			i.logger.Debugf("获取 %s 已安装包列表失败，逐包检查: %v", manager.Name(), err)
+		} else {
+			i.logger.Debugf("缓存 %s 已安装包列表: %d 个包", manager.Name(), set.Len())
+		}
+		if i.installed == nil {
+			i.installed = make(map[string]*InstalledSet)
+		}
+		// 失败时缓存 nil，本次运行不再重复尝试
+		i.installed[manager.Name()] = set
+	}
+	i.installedMu.Unlock()
+
+	if set == nil {
+		return manager.IsInstalled(packageName)
+	}
+	return set.Contains(packageName)
+}
+
+// IsPackageInstalled 检查清单中的包是否已通过任一候选包管理器安装
+func (i *Installer) IsPackageInstalled(packageName string) bool {
+	for _, candidate := range i.SelectManagersFor(packageName) {
+		if i.isInstalled(candidate.Manager, candidate.PackageName) {
+			return true
+		}
+	}
+	return false
+}
+
+// InvalidateInstalledCache 清除已安装包缓存，未指定管理器时清除全部
+func (i *Installer) InvalidateInstalledCache(managerNames ...string) {
+	i.installedMu.Lock()
+	defer i.installedMu.Unlock()
+
+	if len(managerNames) == 0 {
+		i.installed = nil
+		return
+	}
+	for _, name := range managerNames {
+		delete(i.installed, name)
+	}
+}
+
+// sharedInstalledDatabases 共用同一个已安装包数据库的包管理器
+// yay 通过 pacman 安装官方仓库的包和依赖，任一方安装后两者的快照都会过期
+var sharedInstalledDatabases = map[string][]string{
+	"pacman": {"pacman", "yay"},
+	"yay":    {"yay", "pacman"},
+}
+
+// invalidateAfterInstall 清除一次安装尝试（包括失败的尝试）可能改变的快照：该管理器及共用数据库的管理器
+func (i *Installer) invalidateAfterInstall(manager PackageManager) {
+	if names, ok := sharedInstalledDatabases[manager.Name()]; ok {
+		i.InvalidateInstalledCache(names...)
+		return
+	}
+	i.InvalidateInstalledCache(manager.Name())
+}
+
+// queryInstalledNames 执行每行输出一个包名的查询命令（如 pacman -Qq）
+func queryInstalledNames(ctx context.Context, name string, args ...string) (*InstalledSet, error) {
+	output, err := exec.CommandContext(ctx, name, args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("%s %s 执行失败: %w", name, strings.Join(args, " "), err)
+	}
+	return NewInstalledSet(strings.Fields(string(output)), false), nil
+}
@@ -0,0 +1,116 @@
+package installer
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// mockSnapshotManager 支持已安装快照的模拟包管理器，记录快照调用次数
+type mockSnapshotManager struct {
+	*MockPackageManager
+	snapshots   int
+	snapshotErr error
+}
+
+func (m *mockSnapshotManager) InstalledSnapshot(ctx context.Context) (*InstalledSet, error) {
+	m.snapshots++
+	if m.snapshotErr != nil {
+		return nil, m.snapshotErr
+	}
+	names := make([]string, 0, len(m.installedPkgs))
+	for name, installed := range m.installedPkgs {
+		if installed {
+			names = append(names, name)
+		}
+	}
+	return NewInstalledSet(names, false), nil
+}
+
+func newMockSnapshotManager() *mockSnapshotManager {
+	return &mockSnapshotManager{MockPackageManager: NewMockPackageManager("snap", 1)}
+}
+
+// TestInstalledCache_SingleSnapshot 测试多次状态检查只获取一次快照，安装后缓存失效
+func TestInstalledCache_SingleSnapshot(t *testing.T) {
+	manager := newMockSnapshotManager()
+	inst := newTestInstaller(nil, manager)
+	manager.SetInstalled("git", true)
+
+	for _, pkg := range []string{"git", "fzf", "neovim"} {
+		inst.CheckPackageStatus(pkg)
+	}
+	if manager.snapshots != 1 {
+		t.Errorf("期望只获取 1 次快照，实际 %d 次", manager.snapshots)
+	}
+	if !inst.IsPackageInstalled("git") || inst.IsPackageInstalled("fzf") {
+		t.Error("快照中的安装状态错误")
+	}
+
+	if _, err := inst.InstallPackage(context.Background(), "fzf", InstallOptions{}); err != nil {
+		t.Fatalf("安装失败: %v", err)
+	}
+	if !inst.IsPackageInstalled("fzf") {
+		t.Error("安装后缓存应该失效并重新获取快照")
+	}
+	if manager.snapshots != 2 {
+		t.Errorf("安装后应该重新获取一次快照，实际共 %d 次", manager.snapshots)
+	}
+}
+
+// TestInstalledCache_Fallback 测试快照失败时回退到 IsInstalled
+func TestInstalledCache_Fallback(t *testing.T) {
+	manager := newMockSnapshotManager()
+	inst := newTestInstaller(nil, manager)
+	manager.snapshotErr = errors.New("list failed")
+	manager.SetInstalled("git", true)
+
+	if !inst.IsPackageInstalled("git") || inst.IsPackageInstalled("fzf") {
+		t.Error("快照失败时应该逐包检查")
+	}
+	if manager.snapshots != 1 {
+		t.Errorf("快照失败后本次运行不应该重复尝试，实际 %d 次", manager.snapshots)
+	}
+}
+
+// TestInstalledCache_InvalidateInstallingManager 测试安装后只清除该管理器的快照
+func TestInstalledCache_InvalidateInstallingManager(t *testing.T) {
+	manager := newMockSnapshotManager()
+	other := &mockSnapshotManager{MockPackageManager: NewMockPackageManager("other", 2)}
+	inst := newTestInstaller(nil, manager, other)
+	other.SetInstalled("git", true)
+
+	inst.CheckPackageStatus("git")
+	if _, err := inst.InstallPackage(context.Background(), "fzf", InstallOptions{}); err != nil {
+		t.Fatalf("安装失败: %v", err)
+	}
+	inst.CheckPackageStatus("git")
+	inst.CheckPackageStatus("fzf")
+
+	if manager.snapshots != 2 {
+		t.Errorf("安装使用的管理器应该重新获取快照，实际共 %d 次", manager.snapshots)
+	}
+	if other.snapshots != 1 {
+		t.Errorf("其他管理器的快照不应该失效，实际共 %d 次", other.snapshots)
+	}
+}
+
+// TestParseWingetListIDs 测试 winget list 表格解析（包括截断的 ID）
+func TestParseWingetListIDs(t *testing.T) {
+	output := "\r   - \r" +
+		"Name                 Id                          Version   Available Source\r\n" +
+		"---------------------------------------------------------------------------\r\n" +
+		"Git                  Git.Git                     2.44.0              winget\r\n" +
+		"Microsoft Visual St… Microsoft.VisualStudioCode… 1.88.0    1.88.1    winget\r\n"
+
+	set := NewInstalledSet(parseWingetListIDs(output), true)
+	if !set.Contains("git.git") {
+		t.Errorf("应该解析出 Git.Git 且不区分大小写")
+	}
+	if !set.Contains("Microsoft.VisualStudioCode") {
+		t.Errorf("截断的 ID 应该按前缀匹配")
+	}
+	if set.Contains("Neovim.Neovim") {
+		t.Errorf("未安装的包不应该匹配")
+	}
+}
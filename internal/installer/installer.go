@@ -2,12 +2,17 @@ package installer
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sort"
 	"time"
+
+	"github.com/bbq191/dotfiles-go/internal/config"
 )
 
 // InstallPackage 安装单个包 - MVP核心功能
+//
+// 按 SelectManagersFor 的顺序尝试候选管理器，管理器中找不到该包时自动尝试下一个。
 func (i *Installer) InstallPackage(ctx context.Context, packageName string, opts InstallOptions) (*InstallResult, error) {
 	startTime := time.Now()
 	
@@ -16,37 +21,116 @@ func (i *Installer) InstallPackage(ctx context.Context, packageName string, opts
 		Success:     false,
 	}
 	
-	// 选择包管理器
-	manager := i.SelectManager()
-	if manager == nil {
+	// 包清单中的 when 条件不满足当前平台时跳过
+	if !i.PackageApplies(packageName) {
+		i.logger.Warnf("包 %s 的 when 条件不满足当前平台，跳过安装", packageName)
+		result.Success = true
+		result.Skipped = true
+		result.Duration = time.Since(startTime).Seconds()
+		return result, nil
+	}
+	
+	// 选择候选包管理器
+	candidates := i.SelectManagersFor(packageName)
+	if len(candidates) == 0 {
 		err := fmt.Errorf("没有找到可用的包管理器")
+		if i.FindPackageInfo(packageName) != nil {
+			err = fmt.Errorf("没有可用的包管理器能安装包 %s", packageName)
+		}
 		i.logger.Error(err)
 		result.Error = err
 		return result, err
 	}
 	
-	result.Manager = manager.Name()
-	i.logger.Infof("选择包管理器: %s 安装包: %s", manager.Name(), packageName)
+	// 读取包清单中的版本约束
+	constraint, err := i.versionConstraintFor(packageName)
+	if err != nil {
+		result.Manager = candidates[0].Manager.Name()
+		result.Error = err
+		return result, err
+	}
 	
-	// 检查是否需要跳过已安装的包
-	if !opts.Force && manager.IsInstalled(packageName) {
-		i.logger.Infof("包 %s 已安装，跳过安装", packageName)
-		result.Success = true
-		result.Skipped = true
-		result.Duration = time.Since(startTime).Seconds()
-		return result, nil
+	// 安装超时（post_install 在整批安装完成后执行，见 runPostInstallHooks）
+	installTimeout, _, err := i.packageTimeouts(packageName, opts)
+	if err != nil {
+		result.Manager = candidates[0].Manager.Name()
+		result.Error = err
+		return result, err
+	}
+	
+	// 检查是否需要跳过已安装的包（任一候选管理器中已安装即跳过）
+	if !opts.Force {
+		for _, candidate := range candidates {
+			if !i.isInstalled(candidate.Manager, candidate.PackageName) {
+				continue
+			}
+			i.logger.Infof("包 %s 已通过 %s 安装，跳过安装", packageName, candidate.Manager.Name())
+			result.Manager = candidate.Manager.Name()
+			if constraint != nil {
+				result.Version, result.VersionViolation = i.checkInstalledVersion(candidate.Manager, candidate.PackageName, constraint)
+				if result.VersionViolation != "" {
+					i.logger.Warnf("包 %s 违反版本约束: %s", packageName, result.VersionViolation)
+				}
+			}
+			result.Success = true
+			result.Skipped = true
+			result.Duration = time.Since(startTime).Seconds()
+			return result, nil
+		}
 	}
 	
 	// 执行安装
 	if opts.DryRun {
-		i.logger.Infof("[DRY RUN] 将使用 %s 安装 %s", manager.Name(), packageName)
+		first := candidates[0]
+		result.Manager = first.Manager.Name()
+		if constraint != nil {
+			i.logger.Infof("[DRY RUN] 将使用 %s 安装 %s (版本约束: %s)", first.Manager.Name(), first.PackageName, constraint)
+		} else {
+			i.logger.Infof("[DRY RUN] 将使用 %s 安装 %s", first.Manager.Name(), first.PackageName)
+		}
 		result.Success = true
 		result.Duration = time.Since(startTime).Seconds()
 		return result, nil
 	}
 	
-	// 实际安装
-	err := manager.Install(ctx, packageName)
+	// 实际安装，找不到包时回退到下一个候选管理器
+	var installed ManagerCandidate
+	for idx, candidate := range candidates {
+		manager := candidate.Manager
+		result.Manager = manager.Name()
+		i.logger.Infof("选择包管理器: %s 安装包: %s", manager.Name(), candidate.PackageName)
+		
+		attemptStart := time.Now()
+		attemptCtx, cancel := withTimeout(ctx, installTimeout)
+		if constraint != nil {
+			err = i.installWithConstraint(attemptCtx, manager, candidate.PackageName, constraint)
+		} else {
+			err = manager.Install(attemptCtx, candidate.PackageName)
+		}
+		err = timeoutError(attemptCtx, err, fmt.Sprintf("使用 %s 安装 %s ", manager.Name(), candidate.PackageName), installTimeout)
+		cancel()
+		// 只清除这次尝试可能改变的快照，其他管理器的快照在整批安装中继续使用
+		i.invalidateAfterInstall(manager)
+		result.Attempts = append(result.Attempts, InstallAttempt{
+			Manager:     manager.Name(),
+			PackageName: candidate.PackageName,
+			Error:       err,
+			Duration:    time.Since(attemptStart).Seconds(),
+		})
+		
+		if err == nil {
+			installed = candidate
+			break
+		}
+		if IsTimeout(err) {
+			result.TimedOut = true
+			break
+		}
+		if !isNotFoundError(err) || idx == len(candidates)-1 {
+			break
+		}
+		i.logger.Warnf("%s 中未找到包 %s，尝试下一个包管理器 %s", manager.Name(), candidate.PackageName, candidates[idx+1].Manager.Name())
+	}
 	result.Duration = time.Since(startTime).Seconds()
 	
 	if err != nil {
@@ -58,13 +142,27 @@ func (i *Installer) InstallPackage(ctx context.Context, packageName string, opts
 	result.Success = true
 	i.logger.Infof("成功安装包 %s，耗时: %.2f秒", packageName, result.Duration)
 	
+	// 安装后复查版本约束
+	if constraint != nil {
+		result.Version, result.VersionViolation = i.checkInstalledVersion(installed.Manager, installed.PackageName, constraint)
+		if result.VersionViolation != "" {
+			i.logger.Warnf("包 %s 安装后仍违反版本约束: %s", packageName, result.VersionViolation)
+		}
+	}
+	
 	return result, nil
 }
 
 // InstallPackages 安装多个包 - 支持进度显示
+//
+// 单个包超时计入超时结果；整批超时后剩余的包记为超时且不再安装。
+// opts.Stop 关闭后不再开始新的包，已有结果照常汇总返回；取消 ctx 则立即终止。
 func (i *Installer) InstallPackages(ctx context.Context, packages []string, opts InstallOptions) ([]*InstallResult, error) {
 	results := make([]*InstallResult, 0, len(packages))
 	
+	// 在显示进度之前逐个审查AUR包
+	i.ReviewAURPackages(ctx, packages, opts)
+	
 	// 创建进度管理器
 	progressMgr := NewProgressManager(packages, i.logger, opts.Quiet)
 	
@@ -74,13 +172,38 @@ func (i *Installer) InstallPackages(ctx context.Context, packages []string, opts
 		defer progressMgr.Close()
 	}
 	
+	// post_install 不受整批安装时限约束
+	hookCtx := ctx
+	batchTimeout := i.batchTimeout(opts)
+	ctx, cancel := withTimeout(ctx, batchTimeout)
+	defer cancel()
+	
 	i.logger.Infof("开始批量安装 %d 个包", len(packages))
 	
-	for _, pkg := range packages {
+install:
+	for idx, pkg := range packages {
 		select {
 		case <-ctx.Done():
-			i.logger.Warn("安装被取消")
-			return results, ctx.Err()
+			if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				i.logger.Warn("安装被取消")
+				return results, ctx.Err()
+			}
+			
+			i.logger.Errorf("批量安装超过时限 %s，剩余 %d 个包未安装", batchTimeout, len(packages)-idx)
+			for _, remaining := range packages[idx:] {
+				result := batchTimeoutResult(remaining)
+				results = append(results, result)
+				progressMgr.AddResult(result)
+				progressMgr.SendEvent(ProgressEvent{
+					Type:        ProgressTimeout,
+					PackageName: remaining,
+					Error:       result.Error,
+				})
+			}
+			break install
+		case <-opts.Stop:
+			i.logger.Warnf("安装已中断，剩余 %d 个包未安装", len(packages)-idx)
+			break install
 		default:
 			// 发送开始安装事件
 			progressMgr.SendEvent(ProgressEvent{
@@ -97,16 +220,20 @@ func (i *Installer) InstallPackages(ctx context.Context, packages []string, opts
 			
 			// 发送相应的进度事件
 			if err != nil {
+				eventType := ProgressFail
+				if result.TimedOut {
+					eventType = ProgressTimeout
+				}
 				progressMgr.SendEvent(ProgressEvent{
-					Type:        ProgressFail,
+					Type:        eventType,
 					PackageName: pkg,
 					Manager:     result.Manager,
 					Error:       err,
 				})
 				
 				if !opts.Force {
-					i.logger.Errorf("安装包 %s 失败，停止批量安装", pkg)
-					break
+					i.logger.Errorf("安装包 %s 失败，停止批量安装（使用 --force 继续安装其余的包）", pkg)
+					break install
 				}
 			} else if result.Success {
 				if result.Skipped {
@@ -128,6 +255,9 @@ func (i *Installer) InstallPackages(ctx context.Context, packages []string, opts
 		}
 	}
 	
+	// 整批安装完成后执行 post_install 命令
+	i.runPostInstallHooks(hookCtx, results, opts)
+	
 	// 显示总结（除非是quiet模式）
 	if !opts.Quiet {
 		// 等待进度显示完成
@@ -138,19 +268,107 @@ func (i *Installer) InstallPackages(ctx context.Context, packages []string, opts
 	// 统计结果
 	successful := 0
 	failed := 0
+	timedOut := 0
 	for _, result := range results {
-		if result.Success {
+		switch {
+		case result.Success:
 			successful++
-		} else {
+		case result.TimedOut:
+			timedOut++
+		default:
 			failed++
 		}
 	}
 	
-	i.logger.Infof("批量安装完成 - 成功: %d, 失败: %d", successful, failed)
+	i.logger.Infof("批量安装完成 - 成功: %d, 失败: %d, 超时: %d", successful, failed, timedOut)
 	
 	return results, nil
 }
 
+// runPostInstallHooks 整批安装完成后依次执行新装包的 post_install 命令
+//
+// 命令可能需要终端交互（如 chsh 询问密码），因此不在安装过程中（可能是并行的 worker 中）执行，
+// 而是在所有包安装完成后串行执行并连接终端。只有指定 --run-hooks 时才执行，否则只提示有多少包的命令未执行。
+func (i *Installer) runPostInstallHooks(ctx context.Context, results []*InstallResult, opts InstallOptions) {
+	if opts.DryRun {
+		return
+	}
+	
+	pending := 0
+	for _, result := range results {
+		if !result.Success || result.Skipped {
+			continue
+		}
+		info := i.FindPackageInfo(result.PackageName)
+		if info == nil || len(info.PostInstall) == 0 {
+			continue
+		}
+		if !opts.RunHooks {
+			pending++
+			continue
+		}
+		
+		_, postInstallTimeout, err := i.packageTimeouts(result.PackageName, opts)
+		if err != nil {
+			result.PostInstallErrors = append(result.PostInstallErrors, err)
+			continue
+		}
+		i.logger.Infof("执行包 %s 的 %d 条 post_install 命令", result.PackageName, len(info.PostInstall))
+		result.PostInstallErrors = i.runPostInstall(ctx, result.PackageName, info.PostInstall, postInstallTimeout)
+	}
+	
+	if pending > 0 {
+		i.logger.Warnf("%d 个新装的包有 post_install 命令未执行（使用 --run-hooks 执行）", pending)
+	}
+}
+
+// ReviewAURPackages 在开始安装之前逐个审查将由 yay 从AUR安装的包
+//
+// 审查提示需要独占终端，因此在进度显示和并行安装开始之前串行进行；批准记录会保存，
+// 拒绝的 PKGBUILD 在本次运行中也不再询问，安装时 yay 的审查直接沿用结果。
+func (i *Installer) ReviewAURPackages(ctx context.Context, packages []string, opts InstallOptions) {
+	if opts.DryRun {
+		return
+	}
+	for _, pkg := range packages {
+		if !i.PackageApplies(pkg) {
+			continue
+		}
+		for _, candidate := range i.SelectManagersFor(pkg) {
+			if !opts.Force && i.isInstalled(candidate.Manager, candidate.PackageName) {
+				break
+			}
+			yay, ok := candidate.Manager.(*YayManager)
+			if !ok {
+				continue
+			}
+			// 失败的审查在安装时返回同样的错误，这里只提示
+			if err := yay.ReviewIfAUR(ctx, candidate.PackageName); err != nil {
+				i.logger.Warnf("AUR包 %s 未通过审查: %v", candidate.PackageName, err)
+			}
+			break
+		}
+	}
+}
+
+// ConfigureAURReview 为已注册的Yay管理器启用PKGBUILD审查
+func (i *Installer) ConfigureAURReview(reviewConfig *config.AURReviewConfig, approver ReviewApprover) error {
+	storeDir, err := DefaultReviewStoreDir(i.logger)
+	if err != nil {
+		return fmt.Errorf("获取AUR审查记录目录失败: %w", err)
+	}
+	
+	reviewer := NewPKGBUILDReviewer(NewAURClient(""), NewReviewStore(storeDir), reviewConfig, approver, i.logger)
+	for _, manager := range i.managers {
+		if yay, ok := manager.(*YayManager); ok {
+			yay.SetReviewer(reviewer)
+			i.logger.Debugf("已为 %s 启用PKGBUILD审查，记录目录: %s", yay.Name(), storeDir)
+		}
+	}
+	
+	return nil
+}
+
 // InitializeManagers 初始化并注册所有包管理器
 func (i *Installer) InitializeManagers() {
 	i.logger.Info("初始化包管理器")
@@ -167,6 +385,13 @@ func (i *Installer) InitializeManagers() {
 	winget := NewWingetManager(i.logger)
 	i.RegisterManager(winget)
 	
+	// 注册语言生态包管理器 - 只安装清单中显式映射的包
+	i.RegisterManager(NewCargoManager(i.logger))
+	i.RegisterManager(NewGoInstallManager(i.logger))
+	i.RegisterManager(NewNpmManager(i.logger))
+	i.RegisterManager(NewPipxManager(i.logger))
+	i.RegisterManager(NewUvToolManager(i.logger))
+	
 	// 排序管理器（按优先级）
 	sort.Slice(i.managers, func(a, b int) bool {
 		return i.managers[a].Priority() < i.managers[b].Priority()
@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 	
+	"github.com/bbq191/dotfiles-go/internal/config"
 	"github.com/sirupsen/logrus"
 )
 
@@ -65,6 +66,26 @@ func (m *MockPackageManager) SetAvailable(available bool) {
 	m.available = available
 }
 
+// newTestInstaller 创建日志静默的测试安装器并注册 managers
+// packages 不为 nil 时放入 tools 分类作为包配置，需要完整清单的测试自行调用 SetPackagesConfig
+func newTestInstaller(packages map[string]config.PackageInfo, managers ...PackageManager) *Installer {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+	
+	inst := NewInstaller(logger)
+	for _, manager := range managers {
+		inst.RegisterManager(manager)
+	}
+	if packages != nil {
+		inst.SetPackagesConfig(&config.PackagesConfig{
+			Categories: map[string]config.Category{
+				"tools": {Priority: 1, Packages: packages},
+			},
+		})
+	}
+	return inst
+}
+
 // TestNewInstaller 测试安装器创建
 func TestNewInstaller(t *testing.T) {
 	logger := logrus.New()
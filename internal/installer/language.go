@@ -0,0 +1,114 @@
+package installer
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/bbq191/dotfiles-go/internal/config"
+)
+
+// MappedOnlyManager 只安装包清单中显式映射的包的包管理器（可选能力）
+//
+// 语言生态的包名与系统包名不同（例如 cargo 的 du-dust），
+// 未在 managers 中映射的包不会交给这些管理器，避免安装同名的无关包。
+type MappedOnlyManager interface {
+	PackageManager
+
+	// RequiresMapping 是否要求包清单中有映射
+	RequiresMapping() bool
+}
+
+// EnvironmentAware 可接收额外环境变量的包管理器（可选能力）
+type EnvironmentAware interface {
+	PackageManager
+
+	// SetEnvironment 设置执行命令时追加的环境变量
+	SetEnvironment(env map[string]string)
+}
+
+// toolEnvironment 语言包管理器共用的环境变量
+type toolEnvironment struct {
+	env map[string]string
+}
+
+// SetEnvironment 设置执行命令时追加的环境变量
+func (t *toolEnvironment) SetEnvironment(env map[string]string) {
+	t.env = env
+}
+
+// RequiresMapping 语言包管理器只安装显式映射的包
+func (t *toolEnvironment) RequiresMapping() bool {
+	return true
+}
+
+// command 创建带有额外环境变量的命令
+func (t *toolEnvironment) command(cmd *exec.Cmd) *exec.Cmd {
+	if len(t.env) == 0 {
+		return cmd
+	}
+
+	keys := make([]string, 0, len(t.env))
+	for key := range t.env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	cmd.Env = os.Environ()
+	for _, key := range keys {
+		cmd.Env = append(cmd.Env, key+"="+t.env[key])
+	}
+	return cmd
+}
+
+// lookup 返回环境变量值，优先使用追加的环境变量
+func (t *toolEnvironment) lookup(key string) string {
+	if value, ok := t.env[key]; ok {
+		return value
+	}
+	return os.Getenv(key)
+}
+
+// DevelopmentEnvironmentVars 将 development_environments 展开为环境变量
+//
+// 平台特定的值按 shell 名称选取（如 zsh、bash、powershell），
+// 同一变量在多个环境中出现时按环境名排序，后者覆盖前者。
+func DevelopmentEnvironmentVars(envs map[string]map[string]config.PathValue, shell string) map[string]string {
+	names := make([]string, 0, len(envs))
+	for name := range envs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	vars := make(map[string]string)
+	for _, name := range names {
+		for key, value := range envs[name] {
+			if resolved := value.Get(shell); resolved != "" {
+				vars[key] = resolved
+			}
+		}
+	}
+	return vars
+}
+
+// SetToolEnvironment 为支持的包管理器设置额外的环境变量
+func (i *Installer) SetToolEnvironment(env map[string]string) {
+	for _, manager := range i.managers {
+		if aware, ok := manager.(EnvironmentAware); ok {
+			aware.SetEnvironment(env)
+			i.logger.Debugf("已为 %s 设置 %d 个环境变量", manager.Name(), len(env))
+		}
+	}
+}
+
+// notFoundIn 检查命令输出是否包含任一"未找到包"提示，是则返回 ErrPackageNotFound
+func notFoundIn(managerName, packageName, output string, markers ...string) error {
+	for _, marker := range markers {
+		if strings.Contains(output, marker) {
+			return fmt.Errorf("%s: %w: %s", managerName, ErrPackageNotFound, packageName)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package installer
+
+import (
+	"testing"
+
+	"github.com/bbq191/dotfiles-go/internal/config"
+)
+
+// mockLanguageManager 只安装显式映射包的模拟包管理器
+type mockLanguageManager struct {
+	*MockPackageManager
+	toolEnvironment
+}
+
+// TestSelectManagersFor_LanguageManagerRequiresMapping 测试语言包管理器只用于显式映射的包
+func TestSelectManagersFor_LanguageManagerRequiresMapping(t *testing.T) {
+	pacman := NewMockPackageManager("pacman", 1)
+	cargo := &mockLanguageManager{MockPackageManager: NewMockPackageManager("cargo", 10)}
+	inst := newTestInstaller(map[string]config.PackageInfo{
+		"dust": {Managers: map[string]string{"pacman": "dust", "cargo": "du-dust"}},
+	}, pacman, cargo)
+
+	candidates := inst.SelectManagersFor("dust")
+	if len(candidates) != 2 || candidates[1].PackageName != "du-dust" {
+		t.Errorf("期望 pacman → cargo(du-dust)，实际: %+v", candidates)
+	}
+
+	for _, candidate := range inst.SelectManagersFor("unknown-package") {
+		if candidate.Manager.Name() == "cargo" {
+			t.Error("未映射的包不应该交给 cargo")
+		}
+	}
+}
+
+// TestDevelopmentEnvironmentVars 测试开发环境变量展开
+func TestDevelopmentEnvironmentVars(t *testing.T) {
+	envs := map[string]map[string]config.PathValue{
+		"node": {"NPM_CONFIG_CACHE": {Default: "/home/user/.cache/npm"}},
+		"pipx": {"PIPX_HOME": {Platform: map[string]string{"zsh": "/home/user/.local/share/pipx", "powershell": `C:\pipx`}}},
+	}
+
+	vars := DevelopmentEnvironmentVars(envs, "zsh")
+	if vars["NPM_CONFIG_CACHE"] != "/home/user/.cache/npm" {
+		t.Errorf("NPM_CONFIG_CACHE 错误: %q", vars["NPM_CONFIG_CACHE"])
+	}
+	if vars["PIPX_HOME"] != "/home/user/.local/share/pipx" {
+		t.Errorf("PIPX_HOME 应该按 shell 选取，实际: %q", vars["PIPX_HOME"])
+	}
+}
+
+// TestParseCargoInstallList 测试 cargo install --list 输出解析
+func TestParseCargoInstallList(t *testing.T) {
+	output := "du-dust v1.1.1:\n    dust\nprocs v0.14.6:\n    procs\n"
+	crates := parseCargoInstallList(output)
+	if crates["du-dust"] != "1.1.1" || crates["procs"] != "0.14.6" {
+		t.Errorf("解析结果错误: %v", crates)
+	}
+	if _, ok := crates["dust"]; ok {
+		t.Error("二进制名不应该作为 crate 记录")
+	}
+}
+
+// TestGoBinaryName 测试 go install 二进制名推断
+func TestGoBinaryName(t *testing.T) {
+	tests := map[string]string{
+		"golang.org/x/tools/gopls@latest":      "gopls",
+		"github.com/muesli/duf":                "duf",
+		"github.com/golangci/golangci-lint/v2": "golangci-lint",
+		"mvdan.cc/gofumpt@v0.7.0":              "gofumpt",
+	}
+	for pkg, expected := range tests {
+		if name := goBinaryName(pkg); name != expected {
+			t.Errorf("%s 的二进制名期望为 %s，实际为 %s", pkg, expected, name)
+		}
+	}
+}
+
+// TestParseLanguageManagerVersions 测试 npm、pipx、uv 的已安装版本解析
+func TestParseLanguageManagerVersions(t *testing.T) {
+	npmOutput := []byte(`{"dependencies": {"typescript-language-server": {"version": "4.3.3"}}}`)
+	if version, err := parseNpmListVersion(npmOutput, "typescript-language-server"); err != nil || version != "4.3.3" {
+		t.Errorf("npm 版本解析错误: %q, %v", version, err)
+	}
+	if _, err := parseNpmListVersion([]byte(`{}`), "pnpm"); err == nil {
+		t.Error("未安装的 npm 包应该返回错误")
+	}
+
+	pipxOutput := []byte(`{"venvs": {"poetry": {"metadata": {"main_package": {"package_version": "1.8.3"}}}}}`)
+	if version, err := parsePipxListVersion(pipxOutput, "poetry"); err != nil || version != "1.8.3" {
+		t.Errorf("pipx 版本解析错误: %q, %v", version, err)
+	}
+
+	uvOutput := "ruff v0.6.9\n- ruff\nruff-lsp v0.0.57\n- ruff-lsp\n"
+	if version, err := parseUvToolListVersion(uvOutput, "ruff-lsp"); err != nil || version != "0.0.57" {
+		t.Errorf("uv 版本解析错误: %q, %v", version, err)
+	}
+}
@@ -0,0 +1,114 @@
+package installer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// NpmManager npm 全局包管理器实现（npm i -g）
+type NpmManager struct {
+	toolEnvironment
+	logger *logrus.Logger
+}
+
+// NewNpmManager 创建Npm管理器实例
+func NewNpmManager(logger *logrus.Logger) *NpmManager {
+	return &NpmManager{
+		logger: logger,
+	}
+}
+
+// Name 返回包管理器名称
+func (n *NpmManager) Name() string {
+	return "npm"
+}
+
+// IsAvailable 检查npm是否可用
+func (n *NpmManager) IsAvailable() bool {
+	_, err := exec.LookPath("npm")
+	available := err == nil
+	n.logger.Debugf("Npm 可用性检查: %v", available)
+	return available
+}
+
+// Install 全局安装npm包
+func (n *NpmManager) Install(ctx context.Context, packageName string) error {
+	return n.runInstall(ctx, packageName, packageName)
+}
+
+// InstallVersion 全局安装指定版本的npm包
+func (n *NpmManager) InstallVersion(ctx context.Context, packageName, version string) error {
+	return n.runInstall(ctx, packageName, packageName+"@"+version)
+}
+
+// runInstall 执行 npm install -g
+func (n *NpmManager) runInstall(ctx context.Context, packageName, target string) error {
+	n.logger.Infof("使用 npm 全局安装包: %s", target)
+
+	args := []string{"install", "--global", "--no-fund", "--no-audit", target}
+	cmd := setProcessGroup(n.command(exec.CommandContext(ctx, "npm", args...)))
+	n.logger.Debugf("执行命令: npm %s", strings.Join(args, " "))
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		n.logger.Errorf("安装 %s 失败: %v", packageName, err)
+		n.logger.Debugf("命令输出: %s", string(output))
+		if notFound := notFoundIn(n.Name(), packageName, string(output), "E404", "404 Not Found"); notFound != nil {
+			return notFound
+		}
+		return fmt.Errorf("npm install -g 失败: %w", err)
+	}
+
+	n.logger.Infof("成功安装 %s", packageName)
+	return nil
+}
+
+// IsInstalled 检查全局包是否已安装
+func (n *NpmManager) IsInstalled(packageName string) bool {
+	_, err := n.InstalledVersion(packageName)
+	return err == nil
+}
+
+// InstalledVersion 返回全局安装的版本
+func (n *NpmManager) InstalledVersion(packageName string) (string, error) {
+	// 包未安装时 npm ls 返回非零退出码，但仍输出有效 JSON
+	output, _ := n.command(exec.Command("npm", "ls", "--global", "--depth=0", "--json", packageName)).Output()
+	return parseNpmListVersion(output, packageName)
+}
+
+// AvailableVersion 返回 registry 中的最新版本
+func (n *NpmManager) AvailableVersion(packageName string) (string, error) {
+	output, err := n.command(exec.Command("npm", "view", packageName, "version")).Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// Priority 返回优先级
+func (n *NpmManager) Priority() int {
+	return 12
+}
+
+// parseNpmListVersion 解析 npm ls --json 输出中指定包的版本
+func parseNpmListVersion(output []byte, packageName string) (string, error) {
+	var list struct {
+		Dependencies map[string]struct {
+			Version string `json:"version"`
+		} `json:"dependencies"`
+	}
+	if err := json.Unmarshal(output, &list); err != nil {
+		return "", fmt.Errorf("解析 npm ls 输出失败: %w", err)
+	}
+
+	dep, ok := list.Dependencies[packageName]
+	if !ok || dep.Version == "" {
+		return "", fmt.Errorf("npm 全局包 %s 未安装", packageName)
+	}
+	return dep.Version, nil
+}
@@ -2,6 +2,7 @@ package installer
 
 import (
 	"context"
+	"fmt"
 	"os/exec"
 	"strings"
 	
@@ -33,6 +34,11 @@ func (p *PacmanManager) IsAvailable() bool {
 	return available
 }
 
+// RequiresPrivileges pacman 安装需要 root 权限
+func (p *PacmanManager) RequiresPrivileges() bool {
+	return true
+}
+
 // Install 安装包
 func (p *PacmanManager) Install(ctx context.Context, packageName string) error {
 	p.logger.Infof("使用 Pacman 安装包: %s", packageName)
@@ -45,9 +51,9 @@ func (p *PacmanManager) Install(ctx context.Context, packageName string) error {
 	
 	// 构建安装命令
 	args := []string{"-S", "--noconfirm", packageName}
-	cmd := exec.CommandContext(ctx, "sudo", append([]string{"pacman"}, args...)...)
+	cmd := privilegedCommand(ctx, "pacman", args...)
 	
-	p.logger.Debugf("执行命令: sudo pacman %s", strings.Join(args, " "))
+	p.logger.Debugf("执行命令: %s", strings.Join(cmd.Args, " "))
 	
 	// 设置命令输出
 	output, err := cmd.CombinedOutput()
@@ -55,6 +61,9 @@ func (p *PacmanManager) Install(ctx context.Context, packageName string) error {
 	if err != nil {
 		p.logger.Errorf("安装 %s 失败: %v", packageName, err)
 		p.logger.Debugf("命令输出: %s", string(output))
+		if strings.Contains(string(output), "target not found") {
+			return fmt.Errorf("pacman: %w: %s", ErrPackageNotFound, packageName)
+		}
 		return err
 	}
 	
@@ -75,6 +84,29 @@ func (p *PacmanManager) IsInstalled(packageName string) bool {
 	return installed
 }
 
+// InstalledSnapshot 通过 pacman -Qq 一次性列出所有已安装的包
+func (p *PacmanManager) InstalledSnapshot(ctx context.Context) (*InstalledSet, error) {
+	return queryInstalledNames(ctx, "pacman", "-Qq")
+}
+
+// InstalledVersion 返回已安装的版本
+func (p *PacmanManager) InstalledVersion(packageName string) (string, error) {
+	output, err := exec.Command("pacman", "-Q", packageName).Output()
+	if err != nil {
+		return "", err
+	}
+	return parseQueryVersion(string(output))
+}
+
+// AvailableVersion 返回同步仓库中的版本
+func (p *PacmanManager) AvailableVersion(packageName string) (string, error) {
+	info, err := p.GetPackageInfo(packageName)
+	if err != nil {
+		return "", err
+	}
+	return info["Version"], nil
+}
+
 // Priority 返回优先级
 func (p *PacmanManager) Priority() int {
 	return 1 // Pacman 为官方包管理器，优先级较高
@@ -105,4 +137,83 @@ func (p *PacmanManager) GetPackageInfo(packageName string) (map[string]string, e
 	}
 	
 	return info, nil
-}
\ No newline at end of file
+}
+
+// parseQueryVersion 解析 "包名 版本" 格式的查询输出（pacman -Q / yay -Q）
+func parseQueryVersion(output string) (string, error) {
+	fields := strings.Fields(output)
+	if len(fields) < 2 {
+		return "", fmt.Errorf("无法解析版本信息: %q", strings.TrimSpace(output))
+	}
+	return fields[1], nil
+}
+
+// ListExplicitPackages 列出显式安装的官方仓库包（pacman -Qqen，AUR 包由 yay 列出）
+func (p *PacmanManager) ListExplicitPackages(ctx context.Context) ([]ImportedPackage, error) {
+	output, err := exec.CommandContext(ctx, "pacman", "-Qqen").Output()
+	if err != nil {
+		return nil, fmt.Errorf("pacman -Qqen 执行失败: %w", err)
+	}
+	
+	return listLocalPackages(ctx, "pacman", strings.Fields(string(output)))
+}
+
+// listLocalPackages 通过 pacman -Qi 补充本地包的描述和包组
+func listLocalPackages(ctx context.Context, managerName string, names []string) ([]ImportedPackage, error) {
+	packages := make([]ImportedPackage, 0, len(names))
+	if len(names) == 0 {
+		return packages, nil
+	}
+	
+	output, err := exec.CommandContext(ctx, "pacman", append([]string{"-Qi"}, names...)...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("pacman -Qi 执行失败: %w", err)
+	}
+	
+	details := parseLocalPackageInfo(string(output))
+	for _, name := range names {
+		pkg := details[name]
+		pkg.Name = name
+		pkg.Manager = managerName
+		packages = append(packages, pkg)
+	}
+	return packages, nil
+}
+
+// parseLocalPackageInfo 解析 pacman -Qi 输出（多个包以空行分隔）
+func parseLocalPackageInfo(output string) map[string]ImportedPackage {
+	packages := make(map[string]ImportedPackage)
+	var current ImportedPackage
+	
+	flush := func() {
+		if current.Name != "" {
+			packages[current.Name] = current
+		}
+		current = ImportedPackage{}
+	}
+	
+	for _, line := range strings.Split(output, "\n") {
+		if strings.TrimSpace(line) == "" {
+			flush()
+			continue
+		}
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		key, value := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+		switch key {
+		case "Name":
+			current.Name = value
+		case "Description":
+			current.Description = value
+		case "Groups":
+			if value != "None" {
+				current.Groups = strings.Fields(value)
+			}
+		}
+	}
+	flush()
+	
+	return packages
+}
@@ -2,6 +2,7 @@ package installer
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"runtime"
 	"sync"
@@ -40,13 +41,16 @@ func NewParallelInstaller(installer *Installer, maxWorkers int) *ParallelInstall
 // InstallPackagesParallel 并行安装多个包
 func (pi *ParallelInstaller) InstallPackagesParallel(ctx context.Context, packages []string, opts InstallOptions) ([]*InstallResult, error) {
 	// 检查包管理器是否支持并行安装
-	if !pi.supportsParallel() {
-		pi.logger.Warn("当前包管理器不支持并行安装，回退到串行模式")
+	if supported, managerName := pi.supportsParallel(packages); !supported {
+		pi.logger.Warnf("包管理器 %s 不支持并行安装，回退到串行模式", managerName)
 		return pi.installer.InstallPackages(ctx, packages, opts)
 	}
 
 	pi.logger.Infof("启动并行安装模式：%d 个工作协程，安装 %d 个包", pi.maxWorkers, len(packages))
 	
+	// 在显示进度和启动工作协程之前逐个审查AUR包
+	pi.installer.ReviewAURPackages(ctx, packages, opts)
+	
 	// 创建进度管理器
 	pi.progressMgr = NewProgressManager(packages, pi.logger, opts.Quiet)
 	
@@ -56,8 +60,12 @@ func (pi *ParallelInstaller) InstallPackagesParallel(ctx context.Context, packag
 		defer pi.progressMgr.Close()
 	}
 	
+	// 整批安装超时
+	batchCtx, cancel := withTimeout(ctx, pi.installer.batchTimeout(opts))
+	defer cancel()
+	
 	// 创建错误组进行并发控制
-	g, ctx := errgroup.WithContext(ctx)
+	g, groupCtx := errgroup.WithContext(batchCtx)
 	
 	// 创建任务通道
 	packageChan := make(chan string, len(packages))
@@ -72,7 +80,7 @@ func (pi *ParallelInstaller) InstallPackagesParallel(ctx context.Context, packag
 	for i := 0; i < pi.maxWorkers; i++ {
 		workerID := i
 		g.Go(func() error {
-			return pi.worker(ctx, workerID, packageChan, opts)
+			return pi.worker(groupCtx, workerID, packageChan, opts)
 		})
 	}
 	
@@ -82,6 +90,19 @@ func (pi *ParallelInstaller) InstallPackagesParallel(ctx context.Context, packag
 		// 继续处理，不要因为部分失败而终止
 	}
 	
+	// 整批超时后，未开始安装的包记为超时
+	if errors.Is(batchCtx.Err(), context.DeadlineExceeded) {
+		pi.recordBatchTimeout(packages)
+	}
+	
+	pi.resultsMutex.Lock()
+	results := make([]*InstallResult, len(pi.results))
+	copy(results, pi.results)
+	pi.resultsMutex.Unlock()
+	
+	// 所有 worker 完成后串行执行 post_install 命令（不受整批安装时限约束）
+	pi.installer.runPostInstallHooks(ctx, results, opts)
+	
 	// 显示总结（除非是quiet模式）
 	if !opts.Quiet {
 		time.Sleep(100 * time.Millisecond)
@@ -89,22 +110,22 @@ func (pi *ParallelInstaller) InstallPackagesParallel(ctx context.Context, packag
 	}
 	
 	// 统计结果
-	pi.resultsMutex.Lock()
-	results := make([]*InstallResult, len(pi.results))
-	copy(results, pi.results)
-	pi.resultsMutex.Unlock()
 	
 	successful := 0
 	failed := 0
+	timedOut := 0
 	for _, result := range results {
-		if result.Success {
+		switch {
+		case result.Success:
 			successful++
-		} else {
+		case result.TimedOut:
+			timedOut++
+		default:
 			failed++
 		}
 	}
 	
-	pi.logger.Infof("并行安装完成 - 成功: %d, 失败: %d", successful, failed)
+	pi.logger.Infof("并行安装完成 - 成功: %d, 失败: %d, 超时: %d", successful, failed, timedOut)
 	
 	return results, nil
 }
@@ -115,9 +136,18 @@ func (pi *ParallelInstaller) worker(ctx context.Context, workerID int, packageCh
 	defer pi.logger.Debugf("Worker %d 退出", workerID)
 	
 	for {
+		// 中断后不再领取新的包
+		select {
+		case <-opts.Stop:
+			return nil
+		default:
+		}
+		
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
+		case <-opts.Stop:
+			return nil
 		case pkg, ok := <-packageChan:
 			if !ok {
 				// 通道已关闭，无更多任务
@@ -171,8 +201,12 @@ func (pi *ParallelInstaller) installPackageWithProgress(ctx context.Context, pkg
 	// 发送相应的进度事件
 	if pi.progressMgr != nil {
 		if err != nil {
+			eventType := ProgressFail
+			if result.TimedOut {
+				eventType = ProgressTimeout
+			}
 			pi.progressMgr.SendEvent(ProgressEvent{
-				Type:        ProgressFail,
+				Type:        eventType,
 				PackageName: pkg,
 				Manager:     result.Manager,
 				Error:       err,
@@ -200,21 +234,58 @@ func (pi *ParallelInstaller) installPackageWithProgress(ctx context.Context, pkg
 	return err
 }
 
-// supportsParallel 检查当前包管理器是否支持并行安装
-func (pi *ParallelInstaller) supportsParallel() bool {
-	availableManagers := pi.installer.GetAvailableManagers()
-	if len(availableManagers) == 0 {
-		return false
+// recordBatchTimeout 为没有结果的包记录批量超时结果
+func (pi *ParallelInstaller) recordBatchTimeout(packages []string) {
+	pi.resultsMutex.Lock()
+	defer pi.resultsMutex.Unlock()
+	
+	done := make(map[string]bool, len(pi.results))
+	for _, result := range pi.results {
+		done[result.PackageName] = true
 	}
 	
-	// 获取最高优先级的管理器
-	manager := pi.installer.SelectManager()
-	if manager == nil {
-		return false
+	for _, pkg := range packages {
+		if done[pkg] {
+			continue
+		}
+		result := batchTimeoutResult(pkg)
+		pi.results = append(pi.results, result)
+		if pi.progressMgr != nil {
+			pi.progressMgr.AddResult(result)
+			pi.progressMgr.SendEvent(ProgressEvent{
+				Type:        ProgressTimeout,
+				PackageName: pkg,
+				Error:       result.Error,
+			})
+		}
+	}
+}
+
+// supportsParallel 检查这批包实际选中的包管理器是否都支持并行安装
+// 不支持时同时返回该管理器的名称（没有选中任何管理器时为"未知"）
+func (pi *ParallelInstaller) supportsParallel(packages []string) (bool, string) {
+	selected := 0
+	for _, pkg := range packages {
+		candidates := pi.installer.SelectManagersFor(pkg)
+		if len(candidates) == 0 {
+			continue
+		}
+		selected++
+		
+		if manager := candidates[0].Manager; !managerSupportsParallel(manager.Name()) {
+			return false, manager.Name()
+		}
 	}
 	
-	// 检查包管理器是否支持并行
-	switch manager.Name() {
+	if selected == 0 {
+		return false, "未知"
+	}
+	return true, ""
+}
+
+// managerSupportsParallel 检查包管理器是否支持并行安装
+func managerSupportsParallel(name string) bool {
+	switch name {
 	case "pacman":
 		// Pacman 不支持真正的并行安装（会有锁冲突）
 		return false
@@ -262,12 +333,7 @@ func (pi *ParallelInstaller) CheckParallelCapability(packages []string) *Paralle
 	}
 	
 	// 检查包管理器支持
-	if !pi.supportsParallel() {
-		manager := pi.installer.SelectManager()
-		managerName := "未知"
-		if manager != nil {
-			managerName = manager.Name()
-		}
+	if supported, managerName := pi.supportsParallel(packages); !supported {
 		capability.Reason = fmt.Sprintf("包管理器 %s 不支持并行安装", managerName)
 		return capability
 	}
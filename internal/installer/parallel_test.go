@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/bbq191/dotfiles-go/internal/config"
 	"github.com/sirupsen/logrus"
 )
 
@@ -149,7 +150,7 @@ func TestParallelInstaller_SupportsParallel(t *testing.T) {
 		mockManager := NewMockPackageManager(tt.managerName, 1)
 		installer.managers = []PackageManager{mockManager} // 重置管理器列表
 		
-		result := parallelInst.supportsParallel()
+		result, _ := parallelInst.supportsParallel([]string{"pkg1", "pkg2"})
 		if result != tt.expected {
 			t.Errorf("管理器 %s 的并行支持检查结果错误，期望 %v，实际 %v", 
 				tt.managerName, tt.expected, result)
@@ -157,6 +158,26 @@ func TestParallelInstaller_SupportsParallel(t *testing.T) {
 	}
 }
 
+// TestParallelInstaller_SupportsParallelSelected 测试按这批包实际选中的管理器判断并行支持
+func TestParallelInstaller_SupportsParallelSelected(t *testing.T) {
+	winget := NewMockPackageManager("winget", 1)
+	pacman := NewMockPackageManager("pacman", 2)
+	installer := newTestInstaller(map[string]config.PackageInfo{
+		"git":  {Managers: map[string]string{"winget": "Git.Git", "pacman": "git"}},
+		"htop": {Managers: map[string]string{"pacman": "htop"}},
+	}, winget, pacman)
+	parallelInst := NewParallelInstaller(installer, 4)
+	
+	if supported, _ := parallelInst.supportsParallel([]string{"git", "curl"}); !supported {
+		t.Error("全部由 winget 安装的包应该支持并行安装")
+	}
+	
+	supported, managerName := parallelInst.supportsParallel([]string{"git", "htop"})
+	if supported || managerName != "pacman" {
+		t.Errorf("htop 只能由 pacman 安装，期望不支持并行并返回 pacman，实际 %v, %q", supported, managerName)
+	}
+}
+
 // TestParallelInstaller_Fallback 测试并行安装回退机制
 func TestParallelInstaller_Fallback(t *testing.T) {
 	logger := logrus.New()
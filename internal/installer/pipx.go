@@ -0,0 +1,119 @@
+package installer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// PipxManager pipx 包管理器实现
+type PipxManager struct {
+	toolEnvironment
+	logger *logrus.Logger
+}
+
+// NewPipxManager 创建Pipx管理器实例
+func NewPipxManager(logger *logrus.Logger) *PipxManager {
+	return &PipxManager{
+		logger: logger,
+	}
+}
+
+// Name 返回包管理器名称
+func (p *PipxManager) Name() string {
+	return "pipx"
+}
+
+// IsAvailable 检查pipx是否可用
+func (p *PipxManager) IsAvailable() bool {
+	_, err := exec.LookPath("pipx")
+	available := err == nil
+	p.logger.Debugf("Pipx 可用性检查: %v", available)
+	return available
+}
+
+// Install 安装Python应用
+func (p *PipxManager) Install(ctx context.Context, packageName string) error {
+	return p.runInstall(ctx, packageName, packageName)
+}
+
+// InstallVersion 安装指定版本的Python应用
+func (p *PipxManager) InstallVersion(ctx context.Context, packageName, version string) error {
+	return p.runInstall(ctx, packageName, packageName+"=="+version)
+}
+
+// runInstall 执行 pipx install
+func (p *PipxManager) runInstall(ctx context.Context, packageName, target string) error {
+	p.logger.Infof("使用 pipx 安装包: %s", target)
+
+	cmd := setProcessGroup(p.command(exec.CommandContext(ctx, "pipx", "install", target)))
+	p.logger.Debugf("执行命令: pipx install %s", target)
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		p.logger.Errorf("安装 %s 失败: %v", packageName, err)
+		p.logger.Debugf("命令输出: %s", string(output))
+		if notFound := notFoundIn(p.Name(), packageName, string(output),
+			"No matching distribution found", "Could not find a version"); notFound != nil {
+			return notFound
+		}
+		return fmt.Errorf("pipx install 失败: %w", err)
+	}
+
+	p.logger.Infof("成功安装 %s", packageName)
+	return nil
+}
+
+// IsInstalled 检查应用是否已安装
+func (p *PipxManager) IsInstalled(packageName string) bool {
+	_, err := p.InstalledVersion(packageName)
+	return err == nil
+}
+
+// InstalledVersion 返回已安装的版本
+func (p *PipxManager) InstalledVersion(packageName string) (string, error) {
+	output, err := p.command(exec.Command("pipx", "list", "--json")).Output()
+	if err != nil {
+		return "", err
+	}
+	return parsePipxListVersion(output, packageName)
+}
+
+// AvailableVersion pipx 没有查询 PyPI 最新版本的命令
+func (p *PipxManager) AvailableVersion(packageName string) (string, error) {
+	return "", fmt.Errorf("pipx 不支持查询 %s 的可用版本", packageName)
+}
+
+// Priority 返回优先级
+func (p *PipxManager) Priority() int {
+	return 13
+}
+
+// parsePipxListVersion 解析 pipx list --json 输出中指定包的版本
+func parsePipxListVersion(output []byte, packageName string) (string, error) {
+	var list struct {
+		Venvs map[string]struct {
+			Metadata struct {
+				MainPackage struct {
+					PackageVersion string `json:"package_version"`
+				} `json:"main_package"`
+			} `json:"metadata"`
+		} `json:"venvs"`
+	}
+	if err := json.Unmarshal(output, &list); err != nil {
+		return "", fmt.Errorf("解析 pipx list 输出失败: %w", err)
+	}
+
+	venv, ok := list.Venvs[strings.ToLower(packageName)]
+	if !ok {
+		venv, ok = list.Venvs[packageName]
+	}
+	if !ok {
+		return "", fmt.Errorf("pipx 包 %s 未安装", packageName)
+	}
+	return venv.Metadata.MainPackage.PackageVersion, nil
+}
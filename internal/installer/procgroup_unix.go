@@ -0,0 +1,73 @@
+//go:build !windows
+
+package installer
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// processGroupKillDelay 终止进程组时从 SIGTERM 到 SIGKILL 的等待时间
+const processGroupKillDelay = 5 * time.Second
+
+// setProcessGroup 让安装子进程在自己的进程组中运行
+//
+// 终端的 Ctrl-C 只发送给前台进程组，因此第一次中断不会直接终止 pacman、yay、makepkg 等子进程，
+// 由安装器决定是否等待它们完成。上下文取消时（第二次中断或超时）向整个进程组发送 SIGTERM，
+// 包括包管理器启动的子进程（如 yay 调用的 makepkg 和 sudo pacman），宽限时间后仍未退出则发送 SIGKILL。
+//
+// cmd 必须由 exec.CommandContext 创建；子进程不在前台进程组，不能从终端读取输入。
+func setProcessGroup(cmd *exec.Cmd) *exec.Cmd {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+	cmd.Cancel = func() error {
+		pgid := cmd.Process.Pid
+		if err := syscall.Kill(-pgid, syscall.SIGTERM); err != nil {
+			if errors.Is(err, syscall.ESRCH) {
+				return os.ErrProcessDone
+			}
+			return err
+		}
+		time.AfterFunc(processGroupKillDelay, func() {
+			_ = syscall.Kill(-pgid, syscall.SIGKILL)
+		})
+		return nil
+	}
+	// 进程组被终止后，仍占用输出管道的进程不再阻塞 Wait
+	cmd.WaitDelay = processGroupKillDelay + time.Second
+	return cmd
+}
+
+// setForegroundProcessGroup 让连接终端的交互式命令（post_install）在自己的进程组中运行并成为终端的前台进程组
+//
+// 命令可以从终端读取输入（如 chsh 询问密码），Ctrl-C 只发送给该命令；返回的函数在命令退出后
+// 把终端交还给当前进程组。标准输入不是终端或当前进程不在前台时与 setProcessGroup 相同。
+func setForegroundProcessGroup(cmd *exec.Cmd) (*exec.Cmd, func()) {
+	setProcessGroup(cmd)
+
+	fd := os.Stdin.Fd()
+	var foreground int32
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, syscall.TIOCGPGRP, uintptr(unsafe.Pointer(&foreground))); errno != 0 {
+		return cmd, func() {}
+	}
+	if int(foreground) != syscall.Getpgrp() {
+		return cmd, func() {}
+	}
+
+	cmd.Stdin = os.Stdin
+	cmd.SysProcAttr.Foreground = true
+	cmd.SysProcAttr.Ctty = int(fd)
+	return cmd, func() {
+		// 后台进程组设置前台进程组会收到 SIGTTOU，设置期间忽略
+		signal.Ignore(syscall.SIGTTOU)
+		defer signal.Reset(syscall.SIGTTOU)
+		syscall.Syscall(syscall.SYS_IOCTL, fd, syscall.TIOCSPGRP, uintptr(unsafe.Pointer(&foreground)))
+	}
+}
@@ -0,0 +1,29 @@
+//go:build !windows
+
+package installer
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+// TestSetProcessGroup 测试子进程在自己的进程组中运行，取消时终止整个进程组（包括孙进程）
+func TestSetProcessGroup(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	// 孙进程 sleep 继承输出管道，只终止 sh 时 Wait 要等到 WaitDelay 才返回
+	cmd := setProcessGroup(exec.CommandContext(ctx, "sh", "-c", "sleep 30 & wait"))
+	if !cmd.SysProcAttr.Setpgid {
+		t.Fatal("子进程应该在自己的进程组中运行")
+	}
+
+	time.AfterFunc(100*time.Millisecond, cancel)
+	start := time.Now()
+	if _, err := cmd.CombinedOutput(); err == nil {
+		t.Error("取消后命令应该返回错误")
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("取消后应该立即终止整个进程组，实际耗时 %s", elapsed)
+	}
+}
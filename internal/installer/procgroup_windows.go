@@ -0,0 +1,13 @@
+package installer
+
+import "os/exec"
+
+// setProcessGroup Windows 上没有进程组信号语义，控制台中断仍直接发送给子进程
+func setProcessGroup(cmd *exec.Cmd) *exec.Cmd {
+	return cmd
+}
+
+// setForegroundProcessGroup Windows 上不需要交还终端
+func setForegroundProcessGroup(cmd *exec.Cmd) (*exec.Cmd, func()) {
+	return cmd, func() {}
+}
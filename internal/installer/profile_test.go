@@ -0,0 +1,76 @@
+package installer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bbq191/dotfiles-go/internal/config"
+)
+
+// profileTestCategories profile 测试使用的包分类
+var profileTestCategories = map[string]config.Category{
+	"essential": {Priority: 1, Packages: map[string]config.PackageInfo{
+		"git":  {Tags: []string{"vcs"}},
+		"curl": {},
+	}},
+	"modern_tools": {Priority: 2, Packages: map[string]config.PackageInfo{
+		"bat":     {Tags: []string{"rust"}},
+		"ripgrep": {Tags: []string{"rust", "search"}},
+		"fzf":     {Tags: []string{"search"}},
+	}},
+	"multimedia": {Priority: 3, Packages: map[string]config.PackageInfo{
+		"ffmpeg": {},
+	}},
+}
+
+// TestProfilePackageNames 测试 profile 的分类、标签、增删和继承
+func TestProfilePackageNames(t *testing.T) {
+	inst := newTestInstaller(nil)
+	inst.SetPackagesConfig(&config.PackagesConfig{
+		Categories: profileTestCategories,
+		Profiles: map[string]config.Profile{
+			"minimal": {Categories: []string{"essential"}, Exclude: []string{"curl"}},
+			"work":    {Extends: "minimal", Tags: []string{"search"}, Packages: []string{"ffmpeg"}},
+			"full":    {Extends: "work", Categories: []string{"modern_tools"}, Exclude: []string{"ffmpeg"}},
+		},
+	})
+
+	cases := map[string]string{
+		"minimal": "git",
+		"work":    "git,fzf,ripgrep,ffmpeg",
+		"full":    "git,bat,fzf,ripgrep",
+	}
+	for profile, want := range cases {
+		names, err := inst.ProfilePackageNames(profile)
+		if err != nil {
+			t.Fatalf("解析 profile %s 失败: %v", profile, err)
+		}
+		if got := strings.Join(names, ","); got != want {
+			t.Errorf("profile %s 期望 %s，实际 %s", profile, want, got)
+		}
+	}
+
+	if _, err := inst.ProfilePackageNames("server"); err == nil {
+		t.Error("未定义的 profile 应该返回错误")
+	}
+}
+
+// TestProfilePackageNames_Invalid 测试循环继承和无效引用
+func TestProfilePackageNames_Invalid(t *testing.T) {
+	inst := newTestInstaller(nil)
+	inst.SetPackagesConfig(&config.PackagesConfig{
+		Categories: profileTestCategories,
+		Profiles: map[string]config.Profile{
+			"a":       {Extends: "b"},
+			"b":       {Extends: "a"},
+			"missing": {Categories: []string{"games"}},
+			"typo":    {Packages: []string{"ripgrap"}},
+		},
+	})
+
+	for _, profile := range []string{"a", "missing", "typo"} {
+		if _, err := inst.ProfilePackageNames(profile); err == nil {
+			t.Errorf("profile %s 应该返回错误", profile)
+		}
+	}
+}
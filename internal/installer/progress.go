@@ -2,6 +2,7 @@ package installer
 
 import (
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -28,6 +29,7 @@ const (
 	ProgressSuccess                        // 安装成功
 	ProgressFail                           // 安装失败
 	ProgressSkip                           // 跳过安装
+	ProgressTimeout                        // 安装超时
 )
 
 // ProgressManager 进度管理器
@@ -137,6 +139,13 @@ func (pm *ProgressManager) handleEvent(event ProgressEvent) {
 			pm.progressBar.Add(1)
 		}
 		
+	case ProgressTimeout:
+		pm.updatePackageStatus(event.PackageName, "⏱️", "超时", "red")
+		pm.completedPkgs++
+		if pm.progressBar != nil {
+			pm.progressBar.Add(1)
+		}
+		
 	case ProgressSkip:
 		pm.updatePackageStatus(event.PackageName, "⏭️", "已跳过", "blue")
 		pm.completedPkgs++
@@ -192,9 +201,12 @@ func (pm *ProgressManager) GetSummary() *InstallSummary {
 	
 	for _, result := range pm.results {
 		summary.Results = append(summary.Results, result)
-		if result.Success {
+		switch {
+		case result.Success:
 			summary.Successful++
-		} else {
+		case result.TimedOut:
+			summary.TimedOut++
+		default:
 			summary.Failed++
 		}
 	}
@@ -208,6 +220,7 @@ type InstallSummary struct {
 	Successful    int
 	Failed        int
 	Skipped       int
+	TimedOut      int
 	Results       []*InstallResult
 	TotalDuration float64
 }
@@ -242,6 +255,8 @@ func (pm *ProgressManager) PrintSummaryTable() {
 		status := "❌ 失败"
 		if result.Success {
 			status = "✅ 成功"
+		} else if result.TimedOut {
+			status = "⏱️  超时"
 		}
 		
 		totalTime += result.Duration
@@ -255,8 +270,45 @@ func (pm *ProgressManager) PrintSummaryTable() {
 	}
 	
 	fmt.Printf("└─────────────────────┴──────────────┴────────────┴──────────┘\n")
-	fmt.Printf("总计: 成功 %d, 失败 %d, 总耗时: %.2f秒\n", 
-		summary.Successful, summary.Failed, totalTime)
+	fmt.Printf("总计: 成功 %d, 失败 %d, 超时 %d, 总耗时: %.2f秒\n", 
+		summary.Successful, summary.Failed, summary.TimedOut, totalTime)
+	
+	// 列出版本约束违规
+	for _, result := range summary.Results {
+		if result.VersionViolation != "" {
+			fmt.Printf("⚠️  %s: %s\n", result.PackageName, result.VersionViolation)
+		}
+	}
+	
+	// 列出超时的包
+	for _, result := range summary.Results {
+		if result.TimedOut {
+			fmt.Printf("⏱️  %s: %v\n", result.PackageName, result.Error)
+		}
+	}
+	
+	// 列出失败或超时的 post_install 命令
+	for _, result := range summary.Results {
+		for _, err := range result.PostInstallErrors {
+			fmt.Printf("⚠️  %s: %v\n", result.PackageName, err)
+		}
+	}
+	
+	// 列出回退到其他包管理器的安装尝试
+	for _, result := range summary.Results {
+		if len(result.Attempts) <= 1 {
+			continue
+		}
+		steps := make([]string, 0, len(result.Attempts))
+		for _, attempt := range result.Attempts {
+			mark := "✅"
+			if attempt.Error != nil {
+				mark = "❌"
+			}
+			steps = append(steps, fmt.Sprintf("%s %s", attempt.Manager, mark))
+		}
+		fmt.Printf("↪️  %s: %s\n", result.PackageName, strings.Join(steps, " → "))
+	}
 }
 
 // truncateString 截断字符串到指定长度
@@ -0,0 +1,81 @@
+package installer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/bbq191/dotfiles-go/internal/xdg"
+	"github.com/sirupsen/logrus"
+)
+
+// ResumeState 中断的批量安装中尚未完成的包，供 install --resume 继续安装
+type ResumeState struct {
+	Packages    []string  `json:"packages"`    // 未完成的包（未开始、失败或超时），保持原有顺序
+	Completed   []string  `json:"completed"`   // 已完成的包
+	Interrupted time.Time `json:"interrupted"` // 中断时间
+}
+
+// DefaultResumeFile 返回 $XDG_STATE_HOME/dotfiles/install-resume.json
+func DefaultResumeFile(logger *logrus.Logger) (string, error) {
+	stateHome, err := xdg.NewManager(logger, runtime.GOOS).GetXDGPath(xdg.StateHome)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(stateHome, "dotfiles", "install-resume.json"), nil
+}
+
+// NewResumeState 根据安装结果计算未完成的包，没有结果的包视为未开始
+func NewResumeState(packages []string, results []*InstallResult) *ResumeState {
+	succeeded := make(map[string]bool, len(results))
+	for _, result := range results {
+		if result != nil && result.Success {
+			succeeded[result.PackageName] = true
+		}
+	}
+
+	state := &ResumeState{Interrupted: time.Now()}
+	for _, pkg := range packages {
+		if succeeded[pkg] {
+			state.Completed = append(state.Completed, pkg)
+		} else {
+			state.Packages = append(state.Packages, pkg)
+		}
+	}
+	return state
+}
+
+// LoadResumeState 读取续装文件
+func LoadResumeState(path string) (*ResumeState, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("没有可继续的安装（%s 不存在）", path)
+		}
+		return nil, fmt.Errorf("读取续装文件失败: %w", err)
+	}
+
+	var state ResumeState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("解析续装文件 %s 失败: %w", path, err)
+	}
+	return &state, nil
+}
+
+// Save 写入续装文件
+func (rs *ResumeState) Save(path string) error {
+	data, err := json.MarshalIndent(rs, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化续装状态失败: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("创建目录失败: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("写入续装文件失败: %w", err)
+	}
+	return nil
+}
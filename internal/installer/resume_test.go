@@ -0,0 +1,59 @@
+package installer
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestInstallPackages_Stop 测试中断后不再开始新的包，正在安装的包继续完成
+func TestInstallPackages_Stop(t *testing.T) {
+	inst := newTestInstaller(nil, newSlowPackageManager(map[string]time.Duration{"pkg1": 50 * time.Millisecond}))
+
+	stop := make(chan struct{})
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		close(stop)
+	}()
+
+	packages := []string{"pkg1", "pkg2", "pkg3"}
+	results, err := inst.InstallPackages(context.Background(), packages, InstallOptions{Quiet: true, Stop: stop})
+	if err != nil {
+		t.Fatalf("中断不应该返回错误: %v", err)
+	}
+	if len(results) != 1 || !results[0].Success {
+		t.Fatalf("期望只完成 pkg1，实际: %+v", results)
+	}
+
+	state := NewResumeState(packages, results)
+	if strings.Join(state.Packages, ",") != "pkg2,pkg3" || strings.Join(state.Completed, ",") != "pkg1" {
+		t.Errorf("续装状态错误: %+v", state)
+	}
+}
+
+// TestResumeState_SaveLoad 测试续装文件读写
+func TestResumeState_SaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dotfiles", "install-resume.json")
+
+	if _, err := LoadResumeState(path); err == nil {
+		t.Error("续装文件不存在时应该返回错误")
+	}
+
+	results := []*InstallResult{
+		{PackageName: "fzf", Success: true},
+		{PackageName: "neovim", TimedOut: true},
+	}
+	if err := NewResumeState([]string{"fzf", "neovim", "ripgrep"}, results).Save(path); err != nil {
+		t.Fatalf("保存失败: %v", err)
+	}
+
+	state, err := LoadResumeState(path)
+	if err != nil {
+		t.Fatalf("读取失败: %v", err)
+	}
+	if strings.Join(state.Packages, ",") != "neovim,ripgrep" {
+		t.Errorf("超时和未开始的包都应该继续安装，实际: %v", state.Packages)
+	}
+}
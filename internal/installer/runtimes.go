@@ -0,0 +1,505 @@
+package installer
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/bbq191/dotfiles-go/internal/config"
+	"github.com/sirupsen/logrus"
+)
+
+// runtimeVersionRegex 匹配版本管理器输出中的版本号
+var runtimeVersionRegex = regexp.MustCompile(`^v?\d+(\.\d+)*([.-][0-9A-Za-z.-]+)?$`)
+
+// safeShellArgRegex 不需要加引号的命令参数（两种 shell 中都没有特殊含义）
+var safeShellArgRegex = regexp.MustCompile(`^[0-9A-Za-z_./:=+-]+$`)
+
+// runtimeSpec 版本管理器管理的运行时及其命令
+type runtimeSpec struct {
+	Runtime      string                        // 运行时名称
+	ToolNames    []string                      // .tool-versions 中使用的名称
+	VersionFiles []string                      // 项目中的版本文件
+	Init         string                        // 执行命令前需要加载的脚本（如 sdkman 的 shell 函数）
+	Install      func(version string) []string // 安装指定版本的命令参数，按执行的 shell 加引号
+	List         string                        // 列出已安装版本的命令（非 POSIXOnly 时须同时适用于两种 shell）
+	POSIXOnly    bool                          // 只能在 POSIX shell 中使用，Windows 上跳过
+}
+
+// runtimeSpecs 已知的版本管理器（与 zsh_integration.json 的 version_managers 键对应）
+var runtimeSpecs = map[string]runtimeSpec{
+	"fnm": {
+		Runtime:      "node",
+		ToolNames:    []string{"nodejs", "node"},
+		VersionFiles: []string{".nvmrc", ".node-version"},
+		Install: func(version string) []string {
+			if version == "lts/*" || version == "lts" {
+				return []string{"fnm", "install", "--lts"}
+			}
+			return []string{"fnm", "install", version}
+		},
+		List: "fnm list",
+	},
+	"pyenv": {
+		Runtime:      "python",
+		ToolNames:    []string{"python"},
+		VersionFiles: []string{".python-version"},
+		Install:      func(version string) []string { return []string{"pyenv", "install", "--skip-existing", version} },
+		List:         "pyenv versions --bare",
+	},
+	"sdkman": {
+		Runtime:      "java",
+		ToolNames:    []string{"java"},
+		VersionFiles: []string{".sdkmanrc"},
+		Init:         `[ -s "$SDKMAN_DIR/bin/sdkman-init.sh" ] && . "$SDKMAN_DIR/bin/sdkman-init.sh"`,
+		Install:      func(version string) []string { return []string{"sdk", "install", "java", version} },
+		List:         `ls -1 "$SDKMAN_DIR/candidates/java"`,
+		POSIXOnly:    true,
+	},
+	"g": {
+		Runtime:      "go",
+		ToolNames:    []string{"golang", "go"},
+		VersionFiles: []string{".go-version"},
+		Install:      func(version string) []string { return []string{"g", "install", strings.TrimPrefix(version, "go")} },
+		List:         "g ls",
+	},
+}
+
+// RuntimeRequest 项目版本文件中请求的运行时版本
+type RuntimeRequest struct {
+	Runtime string
+	Version string
+	Source  string // 版本来源文件
+}
+
+// RuntimeReport 单个版本管理器的同步结果
+type RuntimeReport struct {
+	Manager   string
+	Runtime   string
+	Requested []RuntimeRequest
+	Installed []string // 已安装的版本
+	Errors    []error
+}
+
+// RuntimeSyncOptions 运行时同步选项
+type RuntimeSyncOptions struct {
+	ProjectDir      string // 查找版本文件的目录（向上查找到根目录）
+	SkipPostInstall bool   // 不执行 post_install
+	DryRun          bool   // 仅显示将要执行的命令
+}
+
+// RuntimeSyncer 版本管理器同步器
+type RuntimeSyncer struct {
+	managers map[string]config.VersionManager
+	logger   *logrus.Logger
+}
+
+// NewRuntimeSyncer 创建版本管理器同步器
+func NewRuntimeSyncer(managers map[string]config.VersionManager, logger *logrus.Logger) *RuntimeSyncer {
+	return &RuntimeSyncer{
+		managers: managers,
+		logger:   logger,
+	}
+}
+
+// EnabledManagers 返回启用且已知的版本管理器名称（已排序）
+func (rs *RuntimeSyncer) EnabledManagers() []string {
+	names := make([]string, 0, len(rs.managers))
+	for name, vm := range rs.managers {
+		if !vm.Enabled {
+			continue
+		}
+		spec, known := runtimeSpecs[name]
+		if !known {
+			rs.logger.Debugf("未知的版本管理器 %s，跳过", name)
+			continue
+		}
+		if spec.POSIXOnly && runtime.GOOS == "windows" {
+			rs.logger.Debugf("版本管理器 %s 需要 POSIX shell，Windows 上跳过", name)
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Sync 执行 post_install 并安装项目版本文件请求的运行时版本
+func (rs *RuntimeSyncer) Sync(ctx context.Context, opts RuntimeSyncOptions) ([]*RuntimeReport, error) {
+	requests, err := FindRuntimeRequests(opts.ProjectDir)
+	if err != nil {
+		return nil, err
+	}
+
+	reports := make([]*RuntimeReport, 0)
+	for _, name := range rs.EnabledManagers() {
+		spec := runtimeSpecs[name]
+		vm := rs.managers[name]
+		report := &RuntimeReport{Manager: name, Runtime: spec.Runtime}
+		env := versionManagerEnv(vm)
+
+		if !opts.SkipPostInstall {
+			for _, command := range vm.PostInstall {
+				if err := rs.run(ctx, spec, env, command, opts.DryRun); err != nil {
+					report.Errors = append(report.Errors, fmt.Errorf("post_install %q 失败: %w", command, err))
+				}
+			}
+		}
+
+		for _, request := range requests {
+			if request.Runtime != spec.Runtime {
+				continue
+			}
+			report.Requested = append(report.Requested, request)
+			rs.logger.Infof("%s 请求 %s %s", request.Source, request.Runtime, request.Version)
+			if err := rs.run(ctx, spec, env, shellCommand(runtime.GOOS, spec.Install(request.Version)), opts.DryRun); err != nil {
+				report.Errors = append(report.Errors, fmt.Errorf("安装 %s %s 失败: %w", spec.Runtime, request.Version, err))
+			}
+		}
+
+		if !opts.DryRun {
+			report.Installed = rs.installedVersions(ctx, spec, env)
+		}
+		reports = append(reports, report)
+	}
+
+	return reports, nil
+}
+
+// Status 报告每个启用的版本管理器已安装的版本
+func (rs *RuntimeSyncer) Status(ctx context.Context) []*RuntimeReport {
+	reports := make([]*RuntimeReport, 0)
+	for _, name := range rs.EnabledManagers() {
+		spec := runtimeSpecs[name]
+		reports = append(reports, &RuntimeReport{
+			Manager:   name,
+			Runtime:   spec.Runtime,
+			Installed: rs.installedVersions(ctx, spec, versionManagerEnv(rs.managers[name])),
+		})
+	}
+	return reports
+}
+
+// run 在应用了 env_vars 和 path_additions 的 shell 中执行命令
+func (rs *RuntimeSyncer) run(ctx context.Context, spec runtimeSpec, env []string, command string, dryRun bool) error {
+	if dryRun {
+		rs.logger.Infof("[DRY RUN] 将执行: %s", command)
+		return nil
+	}
+
+	rs.logger.Infof("执行: %s", command)
+	cmd := runtimeShell(ctx, spec, command)
+	cmd.Env = env
+	output, err := cmd.CombinedOutput()
+	if len(output) > 0 {
+		rs.logger.Debugf("命令输出:\n%s", string(output))
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(lastLine(string(output))))
+	}
+	return nil
+}
+
+// installedVersions 列出已安装的版本
+func (rs *RuntimeSyncer) installedVersions(ctx context.Context, spec runtimeSpec, env []string) []string {
+	cmd := runtimeShell(ctx, spec, spec.List)
+	cmd.Env = env
+	output, err := cmd.Output()
+	if err != nil {
+		rs.logger.Debugf("列出 %s 版本失败: %v", spec.Runtime, err)
+		return nil
+	}
+	return parseRuntimeVersions(string(output))
+}
+
+// runtimeShell 创建执行命令的 shell（Windows 使用 PowerShell，不加载 Init）
+// 子进程的标准输入为空设备，交互式提示（如 sdk install 询问是否设为默认版本）会直接使用默认答案
+func runtimeShell(ctx context.Context, spec runtimeSpec, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return setProcessGroup(exec.CommandContext(ctx, "powershell", "-NoProfile", "-Command", command))
+	}
+	if spec.Init != "" {
+		command = spec.Init + "\n" + command
+	}
+	shell := "bash"
+	if _, err := exec.LookPath(shell); err != nil {
+		shell = "sh"
+	}
+	return setProcessGroup(exec.CommandContext(ctx, shell, "-c", command))
+}
+
+// versionManagerEnv 构建应用了 env_vars 和 path_additions 的环境变量列表
+func versionManagerEnv(vm config.VersionManager) []string {
+	vars := make(map[string]string)
+	lookup := func(key string) (string, bool) {
+		if value, ok := vars[key]; ok {
+			return value, true
+		}
+		return os.LookupEnv(key)
+	}
+	// 展开失败（如 ${VAR:?信息} 中的变量未设置）时保留原值，由 shell 报告错误
+	expand := func(value string) string {
+		if expanded, err := config.Expand(value, lookup); err == nil {
+			return expanded
+		}
+		return value
+	}
+
+	keys := make([]string, 0, len(vm.EnvVars))
+	for key := range vm.EnvVars {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if value, ok := envVarString(vm.EnvVars[key]); ok {
+			vars[key] = expand(value)
+		}
+	}
+
+	paths := make([]string, 0, len(vm.PathAdditions))
+	for _, path := range vm.PathAdditions {
+		paths = append(paths, expand(path))
+	}
+	if len(paths) > 0 {
+		vars["PATH"] = strings.Join(append(paths, os.Getenv("PATH")), string(os.PathListSeparator))
+	}
+
+	env := os.Environ()
+	for key, value := range vars {
+		env = append(env, key+"="+value)
+	}
+	return env
+}
+
+// envVarString 取出 env_vars 中的字符串值（平台特定的对象按当前 shell 取值）
+func envVarString(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case config.PathValue:
+		return v.Get(currentShell()), true
+	case map[string]interface{}:
+		for _, key := range []string{currentShell(), "default"} {
+			if s, ok := v[key].(string); ok {
+				return s, true
+			}
+		}
+	}
+	return "", false
+}
+
+// currentShell 返回当前平台使用的 shell 名称
+func currentShell() string {
+	if runtime.GOOS == "windows" {
+		return "powershell"
+	}
+	return "zsh"
+}
+
+// FindRuntimeRequests 从目录开始向上查找版本文件，每种运行时使用最近的文件
+func FindRuntimeRequests(dir string) ([]RuntimeRequest, error) {
+	if dir == "" {
+		return nil, nil
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := make(map[string]bool)
+	requests := make([]RuntimeRequest, 0)
+	for {
+		dirRequests, err := readRuntimeRequests(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, request := range dirRequests {
+			if !resolved[request.Runtime] {
+				requests = append(requests, request)
+			}
+		}
+		for _, request := range dirRequests {
+			resolved[request.Runtime] = true
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	return requests, nil
+}
+
+// readRuntimeRequests 读取单个目录中的版本文件
+func readRuntimeRequests(dir string) ([]RuntimeRequest, error) {
+	requests := make([]RuntimeRequest, 0)
+
+	toolVersions := filepath.Join(dir, ".tool-versions")
+	if lines, err := readVersionFile(toolVersions); err == nil {
+		requests = append(requests, parseToolVersions(lines, toolVersions)...)
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	managerNames := make([]string, 0, len(runtimeSpecs))
+	for name := range runtimeSpecs {
+		managerNames = append(managerNames, name)
+	}
+	sort.Strings(managerNames)
+
+	for _, name := range managerNames {
+		spec := runtimeSpecs[name]
+		for _, file := range spec.VersionFiles {
+			path := filepath.Join(dir, file)
+			lines, err := readVersionFile(path)
+			if os.IsNotExist(err) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			for _, version := range parseVersionFileLines(file, lines) {
+				requests = append(requests, RuntimeRequest{Runtime: spec.Runtime, Version: version, Source: path})
+			}
+		}
+	}
+
+	return requests, nil
+}
+
+// readVersionFile 读取版本文件的非空、非注释行
+func readVersionFile(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	lines := make([]string, 0)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if idx := strings.Index(line, "#"); idx >= 0 {
+			line = strings.TrimSpace(line[:idx])
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
+
+// parseToolVersions 解析 asdf 格式的 .tool-versions（"nodejs 20.11.0"，可列出多个版本）
+func parseToolVersions(lines []string, source string) []RuntimeRequest {
+	requests := make([]RuntimeRequest, 0)
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		runtimeName := runtimeForTool(fields[0])
+		if runtimeName == "" {
+			continue
+		}
+		for _, version := range fields[1:] {
+			if version == "system" {
+				continue
+			}
+			requests = append(requests, RuntimeRequest{Runtime: runtimeName, Version: version, Source: source})
+		}
+	}
+	return requests
+}
+
+// parseVersionFileLines 解析单运行时版本文件（.sdkmanrc 为 "java=21.0.2-tem" 格式）
+func parseVersionFileLines(file string, lines []string) []string {
+	versions := make([]string, 0)
+	for _, line := range lines {
+		if file == ".sdkmanrc" {
+			key, value, ok := strings.Cut(line, "=")
+			if !ok || strings.TrimSpace(key) != "java" {
+				continue
+			}
+			line = strings.TrimSpace(value)
+		}
+		for _, version := range strings.Fields(line) {
+			if version != "system" {
+				versions = append(versions, version)
+			}
+		}
+	}
+	return versions
+}
+
+// runtimeForTool 将 .tool-versions 中的工具名映射到运行时
+func runtimeForTool(tool string) string {
+	for _, spec := range runtimeSpecs {
+		for _, name := range spec.ToolNames {
+			if name == tool {
+				return spec.Runtime
+			}
+		}
+	}
+	return ""
+}
+
+// parseRuntimeVersions 从版本管理器的列表输出中提取版本号
+func parseRuntimeVersions(output string) []string {
+	versions := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(output, "\n") {
+		for _, field := range strings.Fields(line) {
+			field = strings.TrimPrefix(field, "go")
+			if field == "current" || !runtimeVersionRegex.MatchString(field) || seen[field] {
+				continue
+			}
+			seen[field] = true
+			versions = append(versions, field)
+		}
+	}
+	return versions
+}
+
+// shellCommand 按目标平台的 shell 拼接命令参数（Windows 为 PowerShell，其他为 POSIX shell）
+func shellCommand(goos string, args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if goos == "windows" {
+			quoted[i] = powershellQuote(arg)
+		} else {
+			quoted[i] = shellQuote(arg)
+		}
+	}
+	return strings.Join(quoted, " ")
+}
+
+// shellQuote 为 POSIX shell 命令参数加单引号（只含安全字符时不加）
+func shellQuote(s string) string {
+	if safeShellArgRegex.MatchString(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// powershellQuote 为 PowerShell 命令参数加单引号（只含安全字符时不加）
+func powershellQuote(s string) string {
+	if safeShellArgRegex.MatchString(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// lastLine 返回输出的最后一个非空行
+func lastLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	return lines[len(lines)-1]
+}
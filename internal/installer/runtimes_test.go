@@ -0,0 +1,107 @@
+package installer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bbq191/dotfiles-go/internal/config"
+)
+
+// TestFindRuntimeRequests 测试向上查找项目版本文件
+func TestFindRuntimeRequests(t *testing.T) {
+	root := t.TempDir()
+	project := filepath.Join(root, "project")
+	if err := os.MkdirAll(project, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]string{
+		filepath.Join(root, ".tool-versions"):     "nodejs 18.19.0\ngolang 1.22.1 # 父目录\n",
+		filepath.Join(project, ".nvmrc"):          "v20.11.0\n",
+		filepath.Join(project, ".python-version"): "3.11.7\n3.12.1\n",
+		filepath.Join(project, ".sdkmanrc"):       "# sdkman\njava=21.0.2-tem\n",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	requests, err := FindRuntimeRequests(project)
+	if err != nil {
+		t.Fatalf("查找版本文件失败: %v", err)
+	}
+
+	got := make(map[string][]string)
+	for _, request := range requests {
+		got[request.Runtime] = append(got[request.Runtime], request.Version)
+	}
+
+	if strings.Join(got["node"], ",") != "v20.11.0" {
+		t.Errorf("node 应该使用最近的 .nvmrc，实际: %v", got["node"])
+	}
+	if strings.Join(got["python"], ",") != "3.11.7,3.12.1" {
+		t.Errorf("python 版本错误: %v", got["python"])
+	}
+	if strings.Join(got["java"], ",") != "21.0.2-tem" {
+		t.Errorf("java 版本错误: %v", got["java"])
+	}
+	if strings.Join(got["go"], ",") != "1.22.1" {
+		t.Errorf("go 应该从父目录的 .tool-versions 读取，实际: %v", got["go"])
+	}
+}
+
+// TestVersionManagerEnv 测试 env_vars 和 path_additions 的展开
+func TestVersionManagerEnv(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", "/home/user/.local/share")
+
+	env := versionManagerEnv(config.VersionManager{
+		EnvVars:       map[string]interface{}{"PYENV_ROOT": "$XDG_DATA_HOME/pyenv"},
+		PathAdditions: []string{"$PYENV_ROOT/bin"},
+	})
+
+	vars := make(map[string]string)
+	for _, entry := range env {
+		if key, value, ok := strings.Cut(entry, "="); ok {
+			vars[key] = value
+		}
+	}
+	if vars["PYENV_ROOT"] != "/home/user/.local/share/pyenv" {
+		t.Errorf("PYENV_ROOT 展开错误: %q", vars["PYENV_ROOT"])
+	}
+	if !strings.HasPrefix(vars["PATH"], "/home/user/.local/share/pyenv/bin") {
+		t.Errorf("PATH 应该以 path_additions 开头，实际: %q", vars["PATH"])
+	}
+}
+
+// TestParseRuntimeVersions 测试版本管理器列表输出解析
+func TestParseRuntimeVersions(t *testing.T) {
+	output := "* v20.11.0 default\n* v18.19.0\n* system\n"
+	versions := parseRuntimeVersions(output)
+	if strings.Join(versions, ",") != "v20.11.0,v18.19.0" {
+		t.Errorf("fnm 版本解析错误: %v", versions)
+	}
+
+	versions = parseRuntimeVersions("  1.21.8\n* 1.22.1\n")
+	if strings.Join(versions, ",") != "1.21.8,1.22.1" {
+		t.Errorf("g 版本解析错误: %v", versions)
+	}
+}
+
+// TestShellCommand 测试按 shell 拼接安装命令
+func TestShellCommand(t *testing.T) {
+	args := runtimeSpecs["pyenv"].Install("3.12.2")
+	if got := shellCommand("linux", args); got != "pyenv install --skip-existing 3.12.2" {
+		t.Errorf("POSIX 命令错误: %q", got)
+	}
+
+	args = []string{"fnm", "install", "it's 20"}
+	if got := shellCommand("linux", args); got != `fnm install 'it'\''s 20'` {
+		t.Errorf("POSIX 引号错误: %q", got)
+	}
+	if got := shellCommand("windows", args); got != `fnm install 'it''s 20'` {
+		t.Errorf("PowerShell 引号错误: %q", got)
+	}
+}
@@ -0,0 +1,96 @@
+package installer
+
+import (
+	"errors"
+	"sort"
+)
+
+// ManagerCandidate 包的候选管理器及其在该管理器中的包名
+type ManagerCandidate struct {
+	Manager     PackageManager
+	PackageName string
+}
+
+// SelectManagersFor 按顺序返回可用于安装该包的候选管理器
+//
+// 选择顺序：
+//  1. 包清单中的 preferred_manager
+//  2. 在 managers 中有映射的其他管理器（按优先级）
+//  3. 其余可用管理器（按优先级，使用清单中的包名，跳过 MappedOnlyManager），
+//     映射的管理器都不可用或找不到该包时回退到这些管理器
+func (i *Installer) SelectManagersFor(packageName string) []ManagerCandidate {
+	available := i.GetAvailableManagers()
+	sortByPriority(available)
+
+	var mapping map[string]string
+	var preferred string
+	if info := i.FindPackageInfo(packageName); info != nil {
+		mapping, preferred = info.Managers, info.PreferredManager
+	}
+
+	candidates := make([]ManagerCandidate, 0, len(available))
+	selected := make(map[string]bool)
+	if preferred != "" {
+		for _, manager := range available {
+			if manager.Name() == preferred {
+				candidates = append(candidates, ManagerCandidate{
+					Manager:     manager,
+					PackageName: mappedPackageName(mapping, manager.Name(), packageName),
+				})
+				selected[manager.Name()] = true
+				break
+			}
+		}
+		if len(candidates) == 0 {
+			i.logger.Debugf("包 %s 的首选管理器 %s 不可用", packageName, preferred)
+		}
+	}
+
+	for _, manager := range available {
+		if mapped, ok := mapping[manager.Name()]; ok && !selected[manager.Name()] {
+			candidates = append(candidates, ManagerCandidate{Manager: manager, PackageName: mapped})
+			selected[manager.Name()] = true
+		}
+	}
+
+	for _, manager := range available {
+		if selected[manager.Name()] {
+			continue
+		}
+		if mappedOnly, ok := manager.(MappedOnlyManager); ok && mappedOnly.RequiresMapping() {
+			continue
+		}
+		candidates = append(candidates, ManagerCandidate{Manager: manager, PackageName: packageName})
+	}
+
+	return candidates
+}
+
+// SelectManagerFor 返回安装该包的首选管理器，没有可用管理器时返回 nil
+func (i *Installer) SelectManagerFor(packageName string) PackageManager {
+	candidates := i.SelectManagersFor(packageName)
+	if len(candidates) == 0 {
+		return nil
+	}
+	return candidates[0].Manager
+}
+
+// mappedPackageName 返回包在指定管理器中的包名，没有映射时使用清单中的包名
+func mappedPackageName(mapping map[string]string, managerName, packageName string) string {
+	if mapped, ok := mapping[managerName]; ok && mapped != "" {
+		return mapped
+	}
+	return packageName
+}
+
+// sortByPriority 按优先级排序管理器（数值越低越靠前，优先级相同时保持注册顺序）
+func sortByPriority(managers []PackageManager) {
+	sort.SliceStable(managers, func(a, b int) bool {
+		return managers[a].Priority() < managers[b].Priority()
+	})
+}
+
+// isNotFoundError 判断安装错误是否表示包管理器中找不到该包
+func isNotFoundError(err error) bool {
+	return errors.Is(err, ErrPackageNotFound)
+}
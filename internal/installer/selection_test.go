@@ -0,0 +1,130 @@
+package installer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/bbq191/dotfiles-go/internal/config"
+)
+
+// TestSelectManagersFor_PreferredManager 测试首选管理器排在最前
+func TestSelectManagersFor_PreferredManager(t *testing.T) {
+	pacman := NewMockPackageManager("pacman", 2)
+	yay := NewMockPackageManager("yay", 1)
+	inst := newTestInstaller(map[string]config.PackageInfo{
+		"git": {
+			Managers:         map[string]string{"pacman": "git", "yay": "git"},
+			PreferredManager: "pacman",
+		},
+	}, yay, pacman)
+
+	candidates := inst.SelectManagersFor("git")
+	if len(candidates) != 2 {
+		t.Fatalf("期望 2 个候选管理器，实际为 %d", len(candidates))
+	}
+	if candidates[0].Manager.Name() != "pacman" || candidates[1].Manager.Name() != "yay" {
+		t.Errorf("期望顺序 pacman → yay，实际为 %s → %s", candidates[0].Manager.Name(), candidates[1].Manager.Name())
+	}
+}
+
+// TestSelectManagersFor_MappedFirst 测试有映射的管理器排在前面，其余管理器按优先级使用清单中的包名
+func TestSelectManagersFor_MappedFirst(t *testing.T) {
+	pacman := NewMockPackageManager("pacman", 1)
+	winget := NewMockPackageManager("winget", 2)
+	inst := newTestInstaller(map[string]config.PackageInfo{
+		"git": {Managers: map[string]string{"winget": "Git.Git"}},
+	}, pacman, winget)
+
+	candidates := inst.SelectManagersFor("git")
+	if len(candidates) != 2 {
+		t.Fatalf("期望 2 个候选管理器，实际为 %d", len(candidates))
+	}
+	if candidates[0].Manager.Name() != "winget" || candidates[0].PackageName != "Git.Git" {
+		t.Errorf("期望先使用 winget 安装 Git.Git，实际为 %s 安装 %s", candidates[0].Manager.Name(), candidates[0].PackageName)
+	}
+	if candidates[1].Manager.Name() != "pacman" || candidates[1].PackageName != "git" {
+		t.Errorf("期望再回退到 pacman 安装 git，实际为 %s 安装 %s", candidates[1].Manager.Name(), candidates[1].PackageName)
+	}
+
+	// 不在清单中的包按优先级使用所有管理器
+	candidates = inst.SelectManagersFor("unknown-package")
+	if len(candidates) != 2 || candidates[0].Manager.Name() != "pacman" {
+		t.Errorf("清单外的包应该按优先级使用所有管理器，实际: %+v", candidates)
+	}
+}
+
+// TestInstallPackage_FallbackBeyondMapping 测试映射的管理器不可用或找不到包时回退到其余管理器（跳过只安装映射包的管理器）
+func TestInstallPackage_FallbackBeyondMapping(t *testing.T) {
+	pacman := NewMockPackageManager("pacman", 1)
+	yay := NewMockPackageManager("yay", 2)
+	yay.SetInstallError(fmt.Errorf("yay: %w: ripgrep-git", ErrPackageNotFound))
+	winget := NewMockPackageManager("winget", 3)
+	winget.SetAvailable(false)
+	cargo := &mockLanguageManager{MockPackageManager: NewMockPackageManager("cargo", 10)}
+	inst := newTestInstaller(map[string]config.PackageInfo{
+		"ripgrep": {Managers: map[string]string{"winget": "BurntSushi.ripgrep.MSVC", "yay": "ripgrep-git"}},
+	}, pacman, yay, winget, cargo)
+
+	candidates := inst.SelectManagersFor("ripgrep")
+	if len(candidates) != 2 || candidates[0].Manager.Name() != "yay" || candidates[1].Manager.Name() != "pacman" {
+		t.Fatalf("期望 yay(ripgrep-git) → pacman(ripgrep)，不包括不可用的 winget 和 cargo，实际: %+v", candidates)
+	}
+
+	result, err := inst.InstallPackage(context.Background(), "ripgrep", InstallOptions{})
+	if err != nil {
+		t.Fatalf("回退到 pacman 后应该安装成功: %v", err)
+	}
+	if result.Manager != "pacman" || !pacman.IsInstalled("ripgrep") {
+		t.Errorf("期望使用清单中的包名通过 pacman 安装，实际: %+v", result)
+	}
+}
+
+// TestInstallPackage_FallbackOnNotFound 测试找不到包时回退到下一个管理器
+func TestInstallPackage_FallbackOnNotFound(t *testing.T) {
+	pacman := NewMockPackageManager("pacman", 1)
+	pacman.SetInstallError(fmt.Errorf("pacman: %w: paru-bin", ErrPackageNotFound))
+	yay := NewMockPackageManager("yay", 2)
+	inst := newTestInstaller(map[string]config.PackageInfo{
+		"paru": {Managers: map[string]string{"pacman": "paru-bin", "yay": "paru-bin"}},
+	}, pacman, yay)
+
+	result, err := inst.InstallPackage(context.Background(), "paru", InstallOptions{})
+	if err != nil {
+		t.Fatalf("回退到 yay 后应该安装成功: %v", err)
+	}
+	if result.Manager != "yay" {
+		t.Errorf("期望最终使用 yay，实际为 %s", result.Manager)
+	}
+	if len(result.Attempts) != 2 {
+		t.Fatalf("期望记录 2 次尝试，实际为 %d", len(result.Attempts))
+	}
+	if result.Attempts[0].Error == nil || result.Attempts[1].Error != nil {
+		t.Errorf("期望第一次失败、第二次成功，实际: %+v", result.Attempts)
+	}
+	if !yay.IsInstalled("paru-bin") {
+		t.Error("应该使用映射后的包名 paru-bin 安装")
+	}
+}
+
+// TestInstallPackage_NoFallbackOnOtherError 测试其他错误不回退
+func TestInstallPackage_NoFallbackOnOtherError(t *testing.T) {
+	pacman := NewMockPackageManager("pacman", 1)
+	pacman.SetInstallError(errors.New("网络连接失败"))
+	yay := NewMockPackageManager("yay", 2)
+	inst := newTestInstaller(map[string]config.PackageInfo{
+		"git": {Managers: map[string]string{"pacman": "git", "yay": "git"}},
+	}, pacman, yay)
+
+	result, err := inst.InstallPackage(context.Background(), "git", InstallOptions{})
+	if err == nil {
+		t.Fatal("非“未找到”错误应该直接失败")
+	}
+	if len(result.Attempts) != 1 {
+		t.Errorf("期望只尝试 1 次，实际为 %d", len(result.Attempts))
+	}
+	if yay.IsInstalled("git") {
+		t.Error("不应该回退到 yay 安装")
+	}
+}
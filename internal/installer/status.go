@@ -0,0 +1,138 @@
+package installer
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/bbq191/dotfiles-go/internal/config"
+)
+
+// PackageStatus 清单中单个包的状态
+type PackageStatus struct {
+	Name             string
+	Category         string
+	Manager          string
+	Installed        bool
+	Version          string // 已安装版本
+	Available        string // 未安装时包管理器中的可用版本
+	Constraint       string
+	VersionViolation string // 已安装版本不满足约束，或未安装且可用版本不满足约束（无法安装）
+	NotApplicable    bool // 包清单中的 when 条件不满足当前平台
+	Error            error
+}
+
+// CheckPackageStatus 检查包的安装状态和版本约束
+func (i *Installer) CheckPackageStatus(packageName string) *PackageStatus {
+	status := &PackageStatus{Name: packageName}
+	if info := i.FindPackageInfo(packageName); info != nil {
+		status.Constraint = info.Version
+	}
+	status.Category = i.findPackageCategory(packageName)
+	status.NotApplicable = !i.PackageApplies(packageName)
+
+	candidates := i.SelectManagersFor(packageName)
+	if len(candidates) == 0 {
+		return status
+	}
+	status.Manager = candidates[0].Manager.Name()
+
+	constraint, err := i.versionConstraintFor(packageName)
+	if err != nil {
+		status.Error = err
+		return status
+	}
+
+	for _, candidate := range candidates {
+		if !i.isInstalled(candidate.Manager, candidate.PackageName) {
+			continue
+		}
+		status.Installed = true
+		status.Manager = candidate.Manager.Name()
+		status.Version, status.VersionViolation = i.checkInstalledVersion(candidate.Manager, candidate.PackageName, constraint)
+		break
+	}
+	if !status.Installed && constraint != nil {
+		status.Available, status.VersionViolation = i.checkAvailableVersion(candidates[0].Manager, candidates[0].PackageName, constraint)
+	}
+
+	return status
+}
+
+// CheckManifestStatus 检查清单中所有包的状态（按分类优先级和包名排序）
+func (i *Installer) CheckManifestStatus() []*PackageStatus {
+	statuses := make([]*PackageStatus, 0)
+	for _, name := range i.ManifestPackageNames() {
+		statuses = append(statuses, i.CheckPackageStatus(name))
+	}
+	return statuses
+}
+
+// ManifestPackageNames 返回清单中当前平台适用的所有包名（按分类优先级和包名排序）
+func (i *Installer) ManifestPackageNames() []string {
+	if i.packages == nil {
+		return nil
+	}
+	return manifestPackageNames(i.packages.ForPlatform(i.platform))
+}
+
+// ProfilePackageNames 返回 profile 选中的包名（排序同 ManifestPackageNames）
+func (i *Installer) ProfilePackageNames(profile string) ([]string, error) {
+	if i.packages == nil {
+		return nil, fmt.Errorf("包配置未加载，无法使用 profile %s", profile)
+	}
+	filtered, err := i.packages.ForProfile(profile)
+	if err != nil {
+		return nil, err
+	}
+	return manifestPackageNames(filtered.ForPlatform(i.platform)), nil
+}
+
+// manifestPackageNames 按分类优先级和包名排序返回清单中的包名
+func manifestPackageNames(packages *config.PackagesConfig) []string {
+	if packages == nil {
+		return nil
+	}
+
+	categories := make([]string, 0, len(packages.Categories))
+	for name := range packages.Categories {
+		categories = append(categories, name)
+	}
+	sort.Slice(categories, func(a, b int) bool {
+		ca, cb := packages.Categories[categories[a]], packages.Categories[categories[b]]
+		if ca.Priority != cb.Priority {
+			return ca.Priority < cb.Priority
+		}
+		return categories[a] < categories[b]
+	})
+
+	seen := make(map[string]bool)
+	names := make([]string, 0)
+	for _, categoryName := range categories {
+		packageNames := make([]string, 0, len(packages.Categories[categoryName].Packages))
+		for name := range packages.Categories[categoryName].Packages {
+			packageNames = append(packageNames, name)
+		}
+		sort.Strings(packageNames)
+
+		for _, name := range packageNames {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// findPackageCategory 查找包所在的分类
+func (i *Installer) findPackageCategory(packageName string) string {
+	if i.packages == nil {
+		return ""
+	}
+	for name, category := range i.packages.Categories {
+		if _, exists := category.Packages[packageName]; exists {
+			return name
+		}
+	}
+	return ""
+}
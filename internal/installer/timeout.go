@@ -0,0 +1,161 @@
+package installer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/bbq191/dotfiles-go/internal/config"
+)
+
+const (
+	// DefaultInstallTimeout 单个包的默认安装超时
+	DefaultInstallTimeout = 30 * time.Minute
+	// DefaultPostInstallTimeout 单个 post_install 命令的默认超时
+	DefaultPostInstallTimeout = 5 * time.Minute
+)
+
+// ErrTimeout 安装或 post_install 命令超时
+var ErrTimeout = errors.New("操作超时")
+
+// IsTimeout 判断错误是否由超时引起
+func IsTimeout(err error) bool {
+	return errors.Is(err, ErrTimeout)
+}
+
+// packageTimeouts 返回包的安装和 post_install 超时
+//
+// 优先级：命令行选项 > 包清单中的 timeout/post_install_timeout > 清单 timeouts 默认值 > 内置默认值。
+// 返回 0 表示不限制。
+func (i *Installer) packageTimeouts(packageName string, opts InstallOptions) (install, postInstall time.Duration, err error) {
+	install, postInstall = DefaultInstallTimeout, DefaultPostInstallTimeout
+
+	if defaults := i.timeoutDefaults(); defaults != nil {
+		if install, err = overrideTimeout(install, defaults.Install); err != nil {
+			return 0, 0, fmt.Errorf("timeouts.install: %w", err)
+		}
+		if postInstall, err = overrideTimeout(postInstall, defaults.PostInstall); err != nil {
+			return 0, 0, fmt.Errorf("timeouts.post_install: %w", err)
+		}
+	}
+
+	if info := i.FindPackageInfo(packageName); info != nil {
+		if install, err = overrideTimeout(install, info.Timeout); err != nil {
+			return 0, 0, fmt.Errorf("包 %s 的 timeout: %w", packageName, err)
+		}
+		if postInstall, err = overrideTimeout(postInstall, info.PostInstallTimeout); err != nil {
+			return 0, 0, fmt.Errorf("包 %s 的 post_install_timeout: %w", packageName, err)
+		}
+	}
+
+	if opts.InstallTimeout > 0 {
+		install = opts.InstallTimeout
+	}
+	if opts.PostInstallTimeout > 0 {
+		postInstall = opts.PostInstallTimeout
+	}
+	return install, postInstall, nil
+}
+
+// batchTimeout 返回整批安装的超时（命令行选项优先于清单 timeouts.batch），0 表示不限制
+func (i *Installer) batchTimeout(opts InstallOptions) time.Duration {
+	if opts.BatchTimeout > 0 {
+		return opts.BatchTimeout
+	}
+	if defaults := i.timeoutDefaults(); defaults != nil {
+		if timeout, err := config.ParseTimeout(defaults.Batch); err == nil {
+			return timeout
+		}
+		i.logger.Warnf("忽略无效的 timeouts.batch: %q", defaults.Batch)
+	}
+	return 0
+}
+
+// timeoutDefaults 返回包清单中的默认超时配置
+func (i *Installer) timeoutDefaults() *config.TimeoutConfig {
+	if i.packages == nil {
+		return nil
+	}
+	return i.packages.Timeouts
+}
+
+// overrideTimeout 配置值非空时覆盖当前超时
+func overrideTimeout(current time.Duration, raw string) (time.Duration, error) {
+	if strings.TrimSpace(raw) == "" {
+		return current, nil
+	}
+	return config.ParseTimeout(raw)
+}
+
+// withTimeout 创建带超时的上下文，timeout 为 0 时只支持取消
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// timeoutError 上下文已超时时将 err 包装为 ErrTimeout
+func timeoutError(ctx context.Context, err error, action string, timeout time.Duration) error {
+	if err == nil || !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return err
+	}
+	if timeout > 0 {
+		return fmt.Errorf("%s超时（%s）: %w", action, timeout, ErrTimeout)
+	}
+	return fmt.Errorf("%s超时（批量安装时限已到）: %w", action, ErrTimeout)
+}
+
+// batchTimeoutResult 批量安装超时后未开始安装的包的结果
+func batchTimeoutResult(packageName string) *InstallResult {
+	return &InstallResult{
+		PackageName: packageName,
+		TimedOut:    true,
+		Error:       fmt.Errorf("批量安装超时，未开始安装: %w", ErrTimeout),
+	}
+}
+
+// runPostInstall 依次执行包的 post_install 命令（连接终端），每条命令单独计时，失败不影响后续命令
+func (i *Installer) runPostInstall(ctx context.Context, packageName string, commands []string, timeout time.Duration) []error {
+	var errs []error
+	for _, command := range commands {
+		hookCtx, cancel := withTimeout(ctx, timeout)
+		i.logger.Debugf("执行 %s 的 post_install: %s", packageName, command)
+
+		cmd, restore := postInstallCommand(hookCtx, command)
+		err := cmd.Run()
+		restore()
+		if err != nil {
+			err = fmt.Errorf("post_install %q 失败: %w", command, err)
+		}
+		err = timeoutError(hookCtx, err, fmt.Sprintf("post_install %q ", command), timeout)
+		cancel()
+
+		if err != nil {
+			i.logger.Warnf("包 %s 的 %v", packageName, err)
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// postInstallCommand 使用平台默认 shell 执行 post_install 命令，命令在自己的前台进程组中运行，
+// 返回的函数在命令退出后交还终端
+func postInstallCommand(ctx context.Context, command string) (*exec.Cmd, func()) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "powershell", "-NoProfile", "-Command", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	// 命令可能需要交互（如 chsh 询问密码），连接终端
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return setForegroundProcessGroup(cmd)
+}
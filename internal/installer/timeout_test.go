@@ -0,0 +1,138 @@
+package installer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bbq191/dotfiles-go/internal/config"
+)
+
+// slowPackageManager 按包名延迟安装的模拟包管理器，延迟期间响应上下文取消
+type slowPackageManager struct {
+	*MockPackageManager
+	delays map[string]time.Duration
+}
+
+func (s *slowPackageManager) Install(ctx context.Context, packageName string) error {
+	select {
+	case <-time.After(s.delays[packageName]):
+		return s.MockPackageManager.Install(ctx, packageName)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func newSlowPackageManager(delays map[string]time.Duration) *slowPackageManager {
+	return &slowPackageManager{MockPackageManager: NewMockPackageManager("slow", 1), delays: delays}
+}
+
+// TestInstallPackages_InstallTimeout 测试单包超时计入超时结果，--force 时继续安装其余的包
+func TestInstallPackages_InstallTimeout(t *testing.T) {
+	inst := newTestInstaller(nil, newSlowPackageManager(map[string]time.Duration{"aur-build": time.Second}))
+	opts := InstallOptions{Force: true, Quiet: true, InstallTimeout: 20 * time.Millisecond}
+
+	results, err := inst.InstallPackages(context.Background(), []string{"aur-build", "fzf"}, opts)
+	if err != nil {
+		t.Fatalf("批量安装不应该返回错误: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("期望 2 个结果，实际为 %d", len(results))
+	}
+	if results[0].Success || !results[0].TimedOut || !IsTimeout(results[0].Error) {
+		t.Errorf("aur-build 应该超时，实际: %+v", results[0])
+	}
+	if !results[1].Success {
+		t.Errorf("--force 时超时后应该继续安装 fzf，实际: %+v", results[1])
+	}
+
+	// 不使用 --force 时超时后停止
+	inst = newTestInstaller(nil, newSlowPackageManager(map[string]time.Duration{"aur-build": time.Second}))
+	opts.Force = false
+	results, _ = inst.InstallPackages(context.Background(), []string{"aur-build", "fzf"}, opts)
+	if len(results) != 1 {
+		t.Errorf("未使用 --force 时超时后应该停止，实际结果数 %d", len(results))
+	}
+}
+
+// TestInstallPackages_BatchTimeout 测试整批超时后剩余的包记为超时
+func TestInstallPackages_BatchTimeout(t *testing.T) {
+	delays := map[string]time.Duration{"pkg1": 30 * time.Millisecond, "pkg2": 30 * time.Millisecond}
+	inst := newTestInstaller(nil, newSlowPackageManager(delays))
+	inst.SetPackagesConfig(&config.PackagesConfig{
+		Timeouts: &config.TimeoutConfig{Batch: "40ms"},
+	})
+
+	results, err := inst.InstallPackages(context.Background(), []string{"pkg1", "pkg2", "pkg3"}, InstallOptions{Force: true, Quiet: true})
+	if err != nil {
+		t.Fatalf("批量超时不应该返回错误: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("期望 3 个结果，实际为 %d", len(results))
+	}
+	if !results[0].Success {
+		t.Errorf("pkg1 应该在时限内完成: %+v", results[0])
+	}
+	for _, result := range results[1:] {
+		if !result.TimedOut {
+			t.Errorf("%s 应该记为超时: %+v", result.PackageName, result)
+		}
+	}
+}
+
+// TestPackageTimeouts 测试超时优先级：命令行 > 包配置 > 清单默认值 > 内置默认值
+func TestPackageTimeouts(t *testing.T) {
+	inst := newTestInstaller(nil, newSlowPackageManager(nil))
+	inst.SetPackagesConfig(&config.PackagesConfig{
+		Timeouts: &config.TimeoutConfig{Install: "10m"},
+		Categories: map[string]config.Category{
+			"dev": {Packages: map[string]config.PackageInfo{
+				"rust": {Managers: map[string]string{"slow": "rust"}, Timeout: "1h", PostInstallTimeout: "30s"},
+			}},
+		},
+	})
+
+	install, postInstall, err := inst.packageTimeouts("fzf", InstallOptions{})
+	if err != nil || install != 10*time.Minute || postInstall != DefaultPostInstallTimeout {
+		t.Errorf("fzf 应该使用清单默认值，实际: %s %s %v", install, postInstall, err)
+	}
+
+	install, postInstall, _ = inst.packageTimeouts("rust", InstallOptions{})
+	if install != time.Hour || postInstall != 30*time.Second {
+		t.Errorf("rust 应该使用包配置，实际: %s %s", install, postInstall)
+	}
+
+	install, _, _ = inst.packageTimeouts("rust", InstallOptions{InstallTimeout: 5 * time.Minute})
+	if install != 5*time.Minute {
+		t.Errorf("命令行参数应该优先，实际: %s", install)
+	}
+}
+
+// TestRunPostInstall_Timeout 测试 post_install 命令超时
+func TestRunPostInstall_Timeout(t *testing.T) {
+	inst := newTestInstaller(nil, newSlowPackageManager(nil))
+
+	errs := inst.runPostInstall(context.Background(), "test", []string{"sleep 5", "true"}, 50*time.Millisecond)
+	if len(errs) != 1 || !IsTimeout(errs[0]) {
+		t.Errorf("期望 1 个超时错误，实际: %v", errs)
+	}
+}
+
+// TestInstallPackages_RunHooks 测试 post_install 只在指定 RunHooks 时于整批安装完成后执行
+func TestInstallPackages_RunHooks(t *testing.T) {
+	packages := map[string]config.PackageInfo{
+		"zsh": {Managers: map[string]string{"slow": "zsh"}, PostInstall: []string{"exit 3"}},
+	}
+
+	inst := newTestInstaller(packages, newSlowPackageManager(nil))
+	results, _ := inst.InstallPackages(context.Background(), []string{"zsh"}, InstallOptions{Quiet: true})
+	if len(results) != 1 || !results[0].Success || len(results[0].PostInstallErrors) != 0 {
+		t.Errorf("未指定 RunHooks 时不应该执行 post_install，实际: %+v", results)
+	}
+
+	inst = newTestInstaller(packages, newSlowPackageManager(nil))
+	results, _ = inst.InstallPackages(context.Background(), []string{"zsh"}, InstallOptions{Quiet: true, RunHooks: true})
+	if len(results) != 1 || len(results[0].PostInstallErrors) != 1 {
+		t.Errorf("指定 RunHooks 时应该执行 post_install 并记录失败，实际: %+v", results)
+	}
+}
@@ -2,9 +2,18 @@ package installer
 
 import (
 	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/bbq191/dotfiles-go/internal/config"
+	"github.com/bbq191/dotfiles-go/internal/platform"
 	"github.com/sirupsen/logrus"
 )
 
+// ErrPackageNotFound 包管理器的仓库中找不到该包，安装器会尝试下一个包管理器
+var ErrPackageNotFound = errors.New("包管理器中未找到该包")
+
 // PackageManager 包管理器接口 - MVP设计
 type PackageManager interface {
 	// Name 返回包管理器名称
@@ -23,6 +32,25 @@ type PackageManager interface {
 	Priority() int
 }
 
+// VersionedPackageManager 可查询包版本的包管理器（可选能力）
+type VersionedPackageManager interface {
+	PackageManager
+	
+	// InstalledVersion 返回已安装的版本
+	InstalledVersion(packageName string) (string, error)
+	
+	// AvailableVersion 返回仓库中可安装的版本
+	AvailableVersion(packageName string) (string, error)
+}
+
+// VersionInstaller 支持安装指定版本的包管理器（可选能力）
+type VersionInstaller interface {
+	PackageManager
+	
+	// InstallVersion 安装指定版本的包
+	InstallVersion(ctx context.Context, packageName, version string) error
+}
+
 // InstallOptions 安装选项
 type InstallOptions struct {
 	Force      bool // 强制重新安装
@@ -31,6 +59,13 @@ type InstallOptions struct {
 	Quiet      bool // 静默模式，不显示进度条
 	Parallel   bool // 启用并行安装
 	MaxWorkers int  // 最大并行工作数
+	
+	InstallTimeout     time.Duration // 单个包的安装超时，0 表示使用包清单或默认值
+	PostInstallTimeout time.Duration // 单个 post_install 命令的超时，0 表示使用包清单或默认值
+	BatchTimeout       time.Duration // 整批安装的超时，0 表示使用包清单（未配置时不限制）
+	RunHooks           bool          // 整批安装完成后依次执行新装包的 post_install 命令（默认不执行）
+	
+	Stop <-chan struct{} // 关闭后不再开始新的包，正在安装的包继续完成（nil 表示不使用）
 }
 
 // InstallResult 安装结果
@@ -39,14 +74,35 @@ type InstallResult struct {
 	Manager     string
 	Success     bool
 	Skipped     bool    // 是否跳过安装（包已存在）
+	TimedOut    bool    // 是否因超时失败
 	Error       error
 	Duration    float64 // 安装耗时（秒）
+	
+	Version          string // 安装后（或已安装）的版本
+	VersionViolation string // 版本约束违规说明，为空表示满足约束
+	
+	Attempts []InstallAttempt // 按顺序记录的每次安装尝试
+	
+	PostInstallErrors []error // 失败或超时的 post_install 命令（不影响安装结果）
+}
+
+// InstallAttempt 使用单个包管理器的一次安装尝试
+type InstallAttempt struct {
+	Manager     string
+	PackageName string  // 该管理器中的实际包名
+	Error       error
+	Duration    float64 // 耗时（秒）
 }
 
 // Installer 安装器核心
 type Installer struct {
 	managers []PackageManager
+	packages *config.PackagesConfig // 包清单，用于查找包的版本约束等信息
+	platform *platform.PlatformInfo // 当前平台，用于计算包清单中的 when 条件（nil 时条件均视为满足）
 	logger   *logrus.Logger
+	
+	installed   map[string]*InstalledSet // 本次运行缓存的已安装包快照（按管理器名称）
+	installedMu sync.Mutex
 }
 
 // NewInstaller 创建新的安装器实例
@@ -63,6 +119,34 @@ func (i *Installer) RegisterManager(manager PackageManager) {
 	i.logger.Debugf("注册包管理器: %s (优先级: %d)", manager.Name(), manager.Priority())
 }
 
+// SetPackagesConfig 设置包清单
+func (i *Installer) SetPackagesConfig(packages *config.PackagesConfig) {
+	i.packages = packages
+}
+
+// SetPlatformInfo 设置当前平台信息，包清单中 when 条件不满足的包不再被安装或列出
+func (i *Installer) SetPlatformInfo(info *platform.PlatformInfo) {
+	i.platform = info
+}
+
+// PackageApplies 检查包的 when 条件在当前平台是否成立（不在清单中的包视为成立）
+func (i *Installer) PackageApplies(packageName string) bool {
+	return i.packages == nil || i.packages.PackageApplies(packageName, i.platform)
+}
+
+// FindPackageInfo 在包清单中查找包信息，未找到时返回 nil
+func (i *Installer) FindPackageInfo(packageName string) *config.PackageInfo {
+	if i.packages == nil {
+		return nil
+	}
+	for _, category := range i.packages.Categories {
+		if pkg, exists := category.Packages[packageName]; exists {
+			return &pkg
+		}
+	}
+	return nil
+}
+
 // GetAvailableManagers 获取可用的包管理器列表
 func (i *Installer) GetAvailableManagers() []PackageManager {
 	available := make([]PackageManager, 0)
@@ -74,7 +158,7 @@ func (i *Installer) GetAvailableManagers() []PackageManager {
 	return available
 }
 
-// SelectManager 为包选择最合适的管理器
+// SelectManager 选择全局优先级最高的管理器（按包选择请使用 SelectManagersFor）
 func (i *Installer) SelectManager() PackageManager {
 	available := i.GetAvailableManagers()
 	if len(available) == 0 {
@@ -0,0 +1,107 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// UvToolManager uv tool 包管理器实现
+type UvToolManager struct {
+	toolEnvironment
+	logger *logrus.Logger
+}
+
+// NewUvToolManager 创建uv tool管理器实例
+func NewUvToolManager(logger *logrus.Logger) *UvToolManager {
+	return &UvToolManager{
+		logger: logger,
+	}
+}
+
+// Name 返回包管理器名称
+func (u *UvToolManager) Name() string {
+	return "uv"
+}
+
+// IsAvailable 检查uv是否可用
+func (u *UvToolManager) IsAvailable() bool {
+	_, err := exec.LookPath("uv")
+	available := err == nil
+	u.logger.Debugf("uv 可用性检查: %v", available)
+	return available
+}
+
+// Install 安装Python工具
+func (u *UvToolManager) Install(ctx context.Context, packageName string) error {
+	return u.runInstall(ctx, packageName, packageName)
+}
+
+// InstallVersion 安装指定版本的Python工具
+func (u *UvToolManager) InstallVersion(ctx context.Context, packageName, version string) error {
+	return u.runInstall(ctx, packageName, packageName+"=="+version)
+}
+
+// runInstall 执行 uv tool install
+func (u *UvToolManager) runInstall(ctx context.Context, packageName, target string) error {
+	u.logger.Infof("使用 uv tool 安装包: %s", target)
+
+	cmd := setProcessGroup(u.command(exec.CommandContext(ctx, "uv", "tool", "install", target)))
+	u.logger.Debugf("执行命令: uv tool install %s", target)
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		u.logger.Errorf("安装 %s 失败: %v", packageName, err)
+		u.logger.Debugf("命令输出: %s", string(output))
+		if notFound := notFoundIn(u.Name(), packageName, string(output),
+			"not found in the package registry", "No solution found"); notFound != nil {
+			return notFound
+		}
+		return fmt.Errorf("uv tool install 失败: %w", err)
+	}
+
+	u.logger.Infof("成功安装 %s", packageName)
+	return nil
+}
+
+// IsInstalled 检查工具是否已安装
+func (u *UvToolManager) IsInstalled(packageName string) bool {
+	_, err := u.InstalledVersion(packageName)
+	return err == nil
+}
+
+// InstalledVersion 返回已安装的版本
+func (u *UvToolManager) InstalledVersion(packageName string) (string, error) {
+	output, err := u.command(exec.Command("uv", "tool", "list")).Output()
+	if err != nil {
+		return "", err
+	}
+	return parseUvToolListVersion(string(output), packageName)
+}
+
+// AvailableVersion uv tool 没有查询 PyPI 最新版本的命令
+func (u *UvToolManager) AvailableVersion(packageName string) (string, error) {
+	return "", fmt.Errorf("uv tool 不支持查询 %s 的可用版本", packageName)
+}
+
+// Priority 返回优先级
+func (u *UvToolManager) Priority() int {
+	return 14
+}
+
+// parseUvToolListVersion 解析 uv tool list 输出（"name v1.2.3"，以 "- " 开头的行为可执行文件）
+func parseUvToolListVersion(output, packageName string) (string, error) {
+	for _, line := range strings.Split(output, "\n") {
+		if strings.HasPrefix(line, "-") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && strings.EqualFold(fields[0], packageName) {
+			return strings.TrimPrefix(fields[1], "v"), nil
+		}
+	}
+	return "", fmt.Errorf("uv 工具 %s 未安装", packageName)
+}
@@ -0,0 +1,91 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bbq191/dotfiles-go/internal/config"
+)
+
+// versionConstraintFor 获取包清单中声明的版本约束，未声明时返回 nil
+func (i *Installer) versionConstraintFor(packageName string) (*config.VersionConstraint, error) {
+	info := i.FindPackageInfo(packageName)
+	if info == nil || info.Version == "" {
+		return nil, nil
+	}
+	return config.ParseVersionConstraint(info.Version)
+}
+
+// checkInstalledVersion 检查已安装版本是否满足约束，返回版本和违规说明
+func (i *Installer) checkInstalledVersion(manager PackageManager, packageName string, constraint *config.VersionConstraint) (string, string) {
+	versioned, ok := manager.(VersionedPackageManager)
+	if !ok {
+		i.logger.Debugf("包管理器 %s 不支持版本查询，跳过 %s 的版本检查", manager.Name(), packageName)
+		return "", ""
+	}
+
+	version, err := versioned.InstalledVersion(packageName)
+	if err != nil {
+		i.logger.Debugf("获取 %s 的已安装版本失败: %v", packageName, err)
+		return "", ""
+	}
+	if constraint == nil {
+		return version, ""
+	}
+
+	satisfied, err := constraint.Check(version)
+	if err != nil {
+		return version, err.Error()
+	}
+	if !satisfied {
+		return version, fmt.Sprintf("已安装版本 %s 不满足约束 %s", version, constraint)
+	}
+	return version, ""
+}
+
+// installWithConstraint 按版本约束安装包
+//
+// 支持指定版本的管理器（如 winget --version）直接安装精确版本；
+// 其他管理器先检查仓库中的可用版本，不满足约束时拒绝安装。
+func (i *Installer) installWithConstraint(ctx context.Context, manager PackageManager, packageName string, constraint *config.VersionConstraint) error {
+	if exact := constraint.ExactVersion(); exact != "" {
+		if versionInstaller, ok := manager.(VersionInstaller); ok {
+			i.logger.Infof("使用 %s 安装 %s 的指定版本 %s", manager.Name(), packageName, exact)
+			return versionInstaller.InstallVersion(ctx, packageName, exact)
+		}
+	}
+
+	if _, violation := i.checkAvailableVersion(manager, packageName, constraint); violation != "" {
+		return fmt.Errorf("%s", violation)
+	}
+
+	return manager.Install(ctx, packageName)
+}
+
+// checkAvailableVersion 检查包管理器中的可用版本是否满足约束，返回可用版本和违规说明
+//
+// 能直接安装精确版本的管理器（如 winget --version）不检查；无法获取或比较可用版本时只记录日志。
+func (i *Installer) checkAvailableVersion(manager PackageManager, packageName string, constraint *config.VersionConstraint) (string, string) {
+	if _, ok := manager.(VersionInstaller); ok && constraint.ExactVersion() != "" {
+		return "", ""
+	}
+	versioned, ok := manager.(VersionedPackageManager)
+	if !ok {
+		return "", ""
+	}
+
+	available, err := versioned.AvailableVersion(packageName)
+	if err != nil {
+		i.logger.Warnf("获取 %s 的可用版本失败，无法预先检查版本约束: %v", packageName, err)
+		return "", ""
+	}
+	satisfied, err := constraint.Check(available)
+	if err != nil {
+		i.logger.Warnf("%v", err)
+		return available, ""
+	}
+	if !satisfied {
+		return available, fmt.Sprintf("%s 中 %s 的可用版本 %s 不满足约束 %s", manager.Name(), packageName, available, constraint)
+	}
+	return available, ""
+}
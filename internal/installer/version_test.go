@@ -0,0 +1,190 @@
+package installer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bbq191/dotfiles-go/internal/config"
+)
+
+// MockVersionedManager 支持版本查询和指定版本安装的模拟包管理器
+type MockVersionedManager struct {
+	*MockPackageManager
+	installedVersions map[string]string
+	availableVersions map[string]string
+	requestedVersions map[string]string
+}
+
+func NewMockVersionedManager(name string, priority int) *MockVersionedManager {
+	return &MockVersionedManager{
+		MockPackageManager: NewMockPackageManager(name, priority),
+		installedVersions:  make(map[string]string),
+		availableVersions:  make(map[string]string),
+		requestedVersions:  make(map[string]string),
+	}
+}
+
+func (m *MockVersionedManager) Install(ctx context.Context, packageName string) error {
+	if err := m.MockPackageManager.Install(ctx, packageName); err != nil {
+		return err
+	}
+	m.installedVersions[packageName] = m.availableVersions[packageName]
+	return nil
+}
+
+func (m *MockVersionedManager) InstallVersion(ctx context.Context, packageName, version string) error {
+	m.requestedVersions[packageName] = version
+	if err := m.MockPackageManager.Install(ctx, packageName); err != nil {
+		return err
+	}
+	m.installedVersions[packageName] = version
+	return nil
+}
+
+func (m *MockVersionedManager) InstalledVersion(packageName string) (string, error) {
+	return m.installedVersions[packageName], nil
+}
+
+func (m *MockVersionedManager) AvailableVersion(packageName string) (string, error) {
+	return m.availableVersions[packageName], nil
+}
+
+// TestInstallPackage_VersionConstraintSatisfied 测试满足版本约束的安装
+func TestInstallPackage_VersionConstraintSatisfied(t *testing.T) {
+	manager := NewMockVersionedManager("test", 1)
+	manager.availableVersions["eza"] = "0.18.2-1"
+	inst := newTestInstaller(map[string]config.PackageInfo{
+		"eza": {Version: ">=0.18"},
+	}, manager)
+
+	result, err := inst.InstallPackage(context.Background(), "eza", InstallOptions{})
+	if err != nil {
+		t.Fatalf("满足约束的安装应该成功: %v", err)
+	}
+	if result.VersionViolation != "" {
+		t.Errorf("不应该报告版本违规，实际: %s", result.VersionViolation)
+	}
+	if result.Version != "0.18.2-1" {
+		t.Errorf("期望记录版本 0.18.2-1，实际为 %s", result.Version)
+	}
+}
+
+// TestInstallPackage_VersionConstraintUnavailable 测试仓库版本不满足约束时拒绝安装
+func TestInstallPackage_VersionConstraintUnavailable(t *testing.T) {
+	manager := NewMockVersionedManager("test", 1)
+	manager.availableVersions["eza"] = "0.17.0-1"
+	inst := newTestInstaller(map[string]config.PackageInfo{
+		"eza": {Version: ">=0.18"},
+	}, manager)
+
+	if _, err := inst.InstallPackage(context.Background(), "eza", InstallOptions{}); err == nil {
+		t.Error("可用版本不满足约束时应该返回错误")
+	}
+	if manager.IsInstalled("eza") {
+		t.Error("不满足约束时不应该安装包")
+	}
+}
+
+// TestInstallPackage_ExactVersion 测试支持指定版本的管理器按精确版本安装
+func TestInstallPackage_ExactVersion(t *testing.T) {
+	manager := NewMockVersionedManager("test", 1)
+	inst := newTestInstaller(map[string]config.PackageInfo{
+		"Git.Git": {Version: "2.45.1"},
+	}, manager)
+
+	if _, err := inst.InstallPackage(context.Background(), "Git.Git", InstallOptions{}); err != nil {
+		t.Fatalf("指定版本安装应该成功: %v", err)
+	}
+	if manager.requestedVersions["Git.Git"] != "2.45.1" {
+		t.Errorf("期望请求版本 2.45.1，实际为 %q", manager.requestedVersions["Git.Git"])
+	}
+}
+
+// TestInstallPackage_InstalledVersionViolation 测试已安装包违反约束时的报告
+func TestInstallPackage_InstalledVersionViolation(t *testing.T) {
+	manager := NewMockVersionedManager("test", 1)
+	manager.SetInstalled("neovim", true)
+	manager.installedVersions["neovim"] = "0.9.5-2"
+	inst := newTestInstaller(map[string]config.PackageInfo{
+		"neovim": {Version: "0.10.2-1"},
+	}, manager)
+
+	result, err := inst.InstallPackage(context.Background(), "neovim", InstallOptions{})
+	if err != nil {
+		t.Fatalf("已安装包应该跳过安装: %v", err)
+	}
+	if !result.Skipped {
+		t.Error("已安装包应该标记为跳过")
+	}
+	if result.VersionViolation == "" {
+		t.Error("已安装版本与精确约束不一致时应该报告违规")
+	}
+
+	status := inst.CheckPackageStatus("neovim")
+	if status.VersionViolation == "" || status.Version != "0.9.5-2" {
+		t.Errorf("状态检查应该报告违规版本 0.9.5-2，实际: %+v", status)
+	}
+}
+
+// TestCheckPackageStatus_Missing 测试未安装的包只比较可用版本，未安装本身不算违规
+func TestCheckPackageStatus_Missing(t *testing.T) {
+	manager := NewMockVersionedManager("test", 1)
+	manager.availableVersions["eza"] = "0.18.2-1"
+	manager.availableVersions["bat"] = "0.23.0-1"
+	inst := newTestInstaller(map[string]config.PackageInfo{
+		"eza": {Version: ">=0.18"},
+		"bat": {Version: ">=0.24"},
+	}, manager)
+
+	status := inst.CheckPackageStatus("eza")
+	if status.Installed || status.VersionViolation != "" || status.Available != "0.18.2-1" {
+		t.Errorf("可用版本满足约束的未安装包不应报告违规: %+v", status)
+	}
+	status = inst.CheckPackageStatus("bat")
+	if status.VersionViolation == "" || status.Available != "0.23.0-1" {
+		t.Errorf("可用版本不满足约束时应该报告违规: %+v", status)
+	}
+}
+
+// TestParseVersionConstraint_Prerelease 测试 pacman 精确版本与 semver 预发布版本的区分
+func TestParseVersionConstraint_Prerelease(t *testing.T) {
+	tests := []struct {
+		raw   string
+		exact bool
+		check string
+		want  bool
+	}{
+		{"1.2.3-1", true, "1.2.3-1", true},
+		{"1.2.3-1", true, "1.2.3-2", false},
+		{"=1.2.3-1", false, "1.2.3-1", true},
+		{">=1.2.3-rc.1", false, "1.2.3", true},
+		{"2:9.1.0-1", true, "2:9.1.0-1", true},
+	}
+	for _, tt := range tests {
+		constraint, err := config.ParseVersionConstraint(tt.raw)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.raw, err)
+		}
+		if constraint.IsExact() != tt.exact {
+			t.Errorf("%s: IsExact = %v，期望 %v", tt.raw, constraint.IsExact(), tt.exact)
+		}
+		if got, err := constraint.Check(tt.check); err != nil || got != tt.want {
+			t.Errorf("%s 检查 %s = %v (%v)，期望 %v", tt.raw, tt.check, got, err, tt.want)
+		}
+	}
+}
+
+// TestParseWingetListVersion 测试winget列表输出解析
+func TestParseWingetListVersion(t *testing.T) {
+	output := "Name      Id       Version Available Source\r\n" +
+		"---------------------------------------------\r\n" +
+		"Git       Git.Git  2.45.1  2.46.0    winget\r\n"
+
+	version, err := parseWingetListVersion(output, "Git.Git")
+	if err != nil {
+		t.Fatalf("解析应该成功: %v", err)
+	}
+	if version != "2.45.1" {
+		t.Errorf("期望版本 2.45.1，实际为 %s", version)
+	}
+}
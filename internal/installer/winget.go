@@ -2,7 +2,11 @@ package installer
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"runtime"
 	
@@ -46,9 +50,25 @@ func (w *WingetManager) Install(ctx context.Context, packageName string) error {
 	
 	// 检查是否已安装（winget暂不支持准确的已安装检查，直接尝试安装）
 	
-	// 构建安装命令
-	args := []string{"install", "--id", packageName, "--silent", "--accept-package-agreements", "--accept-source-agreements"}
-	cmd := exec.CommandContext(ctx, "winget", args...)
+	return w.runInstall(ctx, packageName, w.installArgs(packageName))
+}
+
+// InstallVersion 安装指定版本的包
+func (w *WingetManager) InstallVersion(ctx context.Context, packageName, version string) error {
+	w.logger.Infof("使用 Winget 安装包: %s (版本 %s)", packageName, version)
+	
+	args := append(w.installArgs(packageName), "--version", version)
+	return w.runInstall(ctx, packageName, args)
+}
+
+// installArgs 构建安装命令参数
+func (w *WingetManager) installArgs(packageName string) []string {
+	return []string{"install", "--id", packageName, "--silent", "--accept-package-agreements", "--accept-source-agreements"}
+}
+
+// runInstall 执行安装命令
+func (w *WingetManager) runInstall(ctx context.Context, packageName string, args []string) error {
+	cmd := setProcessGroup(exec.CommandContext(ctx, "winget", args...))
 	
 	w.logger.Debugf("执行命令: winget %s", strings.Join(args, " "))
 	
@@ -66,6 +86,9 @@ func (w *WingetManager) Install(ctx context.Context, packageName string) error {
 		
 		w.logger.Errorf("安装 %s 失败: %v", packageName, err)
 		w.logger.Debugf("命令输出: %s", outputStr)
+		if strings.Contains(outputStr, "No package found matching input criteria") {
+			return fmt.Errorf("winget: %w: %s", ErrPackageNotFound, packageName)
+		}
 		return err
 	}
 	
@@ -87,6 +110,40 @@ func (w *WingetManager) IsInstalled(packageName string) bool {
 	return installed
 }
 
+// InstalledSnapshot 解析一次 winget list 输出，列出所有已安装包的 ID
+func (w *WingetManager) InstalledSnapshot(ctx context.Context) (*InstalledSet, error) {
+	output, err := exec.CommandContext(ctx, "winget", "list", "--accept-source-agreements").Output()
+	if err != nil {
+		return nil, fmt.Errorf("winget list 执行失败: %w", err)
+	}
+	return NewInstalledSet(parseWingetListIDs(string(output)), true), nil
+}
+
+// InstalledVersion 返回已安装的版本
+func (w *WingetManager) InstalledVersion(packageName string) (string, error) {
+	output, err := exec.Command("winget", "list", "--id", packageName, "--exact", "--accept-source-agreements").Output()
+	if err != nil {
+		return "", err
+	}
+	return parseWingetListVersion(string(output), packageName)
+}
+
+// AvailableVersion 返回源中的最新版本
+func (w *WingetManager) AvailableVersion(packageName string) (string, error) {
+	output, err := exec.Command("winget", "show", "--id", packageName, "--exact", "--accept-source-agreements").Output()
+	if err != nil {
+		return "", err
+	}
+	
+	for _, line := range strings.Split(string(output), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "Version:") {
+			return strings.TrimSpace(strings.TrimPrefix(line, "Version:")), nil
+		}
+	}
+	return "", fmt.Errorf("winget show 输出中未找到 %s 的版本", packageName)
+}
+
 // Priority 返回优先级
 func (w *WingetManager) Priority() int {
 	return 2 // Winget 优先级稍低于系统原生包管理器
@@ -117,4 +174,133 @@ func (w *WingetManager) Search(query string) ([]string, error) {
 	}
 	
 	return results, nil
-}
\ No newline at end of file
+}
+
+// wingetExport winget export 生成的 JSON 结构
+type wingetExport struct {
+	Sources []struct {
+		Packages []struct {
+			PackageIdentifier string `json:"PackageIdentifier"`
+		} `json:"Packages"`
+		SourceDetails struct {
+			Name string `json:"Name"`
+		} `json:"SourceDetails"`
+	} `json:"Sources"`
+}
+
+// ListExplicitPackages 通过 winget export 列出已安装的包
+// 只解析一次导出文件，不再逐个调用 winget show；导出文件不含描述，导入的包没有描述
+func (w *WingetManager) ListExplicitPackages(ctx context.Context) ([]ImportedPackage, error) {
+	tmpDir, err := os.MkdirTemp("", "dotfiles-winget-export")
+	if err != nil {
+		return nil, fmt.Errorf("创建临时目录失败: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	
+	exportPath := filepath.Join(tmpDir, "packages.json")
+	cmd := exec.CommandContext(ctx, "winget", "export", "--output", exportPath, "--accept-source-agreements")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		w.logger.Debugf("winget export 输出: %s", string(output))
+		return nil, fmt.Errorf("winget export 执行失败: %w", err)
+	}
+	
+	data, err := os.ReadFile(exportPath)
+	if err != nil {
+		return nil, fmt.Errorf("读取 winget 导出文件失败: %w", err)
+	}
+	ids, err := parseWingetExport(data)
+	if err != nil {
+		return nil, err
+	}
+	
+	packages := make([]ImportedPackage, 0, len(ids))
+	for _, id := range ids {
+		packages = append(packages, ImportedPackage{
+			Name:    id,
+			Manager: w.Name(),
+		})
+	}
+	return packages, nil
+}
+
+// parseWingetExport 解析 winget export 文件，只保留 winget 源中的包
+func parseWingetExport(data []byte) ([]string, error) {
+	var export wingetExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, fmt.Errorf("解析 winget 导出文件失败: %w", err)
+	}
+	
+	ids := make([]string, 0)
+	for _, source := range export.Sources {
+		if source.SourceDetails.Name != "" && source.SourceDetails.Name != "winget" {
+			continue
+		}
+		for _, pkg := range source.Packages {
+			if pkg.PackageIdentifier != "" {
+				ids = append(ids, pkg.PackageIdentifier)
+			}
+		}
+	}
+	return ids, nil
+}
+
+// parseWingetListVersion 从 winget list 的表格输出中解析指定包的版本
+func parseWingetListVersion(output, packageName string) (string, error) {
+	lines := strings.Split(strings.ReplaceAll(output, "\r", ""), "\n")
+	
+	versionCol := -1
+	for _, line := range lines {
+		if versionCol < 0 {
+			// 表头中 "Version" 列的位置决定后续各行的版本列
+			if idx := strings.Index(line, "Version"); idx >= 0 && strings.Contains(line, "Id") {
+				versionCol = idx
+			}
+			continue
+		}
+		
+		if !strings.Contains(line, packageName) || len(line) <= versionCol {
+			continue
+		}
+		
+		fields := strings.Fields(line[versionCol:])
+		if len(fields) > 0 {
+			return fields[0], nil
+		}
+	}
+	
+	return "", fmt.Errorf("winget list 输出中未找到 %s 的版本", packageName)
+}
+
+// parseWingetListIDs 从 winget list 的表格输出中解析所有包的 ID（按表头 Id 列定位，可能被截断为 "…" 结尾）
+func parseWingetListIDs(output string) []string {
+	var ids []string
+	idCol, versionCol := -1, -1
+	
+	for _, line := range strings.Split(output, "\n") {
+		// 进度动画使用 \r 覆盖同一行，只保留最后一段
+		segments := strings.Split(strings.TrimRight(line, "\r"), "\r")
+		runes := []rune(segments[len(segments)-1])
+		
+		if idCol < 0 {
+			header := string(runes)
+			if strings.Contains(header, "Id") && strings.Contains(header, "Version") {
+				idCol = len([]rune(header[:strings.Index(header, "Id")]))
+				versionCol = len([]rune(header[:strings.Index(header, "Version")]))
+			}
+			continue
+		}
+		
+		if len(runes) <= idCol || strings.HasPrefix(strings.TrimSpace(string(runes)), "---") {
+			continue
+		}
+		end := versionCol
+		if end > len(runes) || end <= idCol {
+			end = len(runes)
+		}
+		if fields := strings.Fields(string(runes[idCol:end])); len(fields) > 0 {
+			ids = append(ids, fields[0])
+		}
+	}
+	
+	return ids
+}
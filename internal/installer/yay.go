@@ -13,7 +13,8 @@ import (
 
 // YayManager Yay AUR包管理器实现
 type YayManager struct {
-	logger *logrus.Logger
+	logger   *logrus.Logger
+	reviewer *PKGBUILDReviewer // AUR PKGBUILD 审查器，为空时不审查
 }
 
 // NewYayManager 创建Yay管理器实例
@@ -23,6 +24,11 @@ func NewYayManager(logger *logrus.Logger) *YayManager {
 	}
 }
 
+// SetReviewer 设置AUR PKGBUILD审查器
+func (y *YayManager) SetReviewer(reviewer *PKGBUILDReviewer) {
+	y.reviewer = reviewer
+}
+
 // Name 返回包管理器名称
 func (y *YayManager) Name() string {
 	return "yay"
@@ -69,10 +75,15 @@ func (y *YayManager) Install(ctx context.Context, packageName string) error {
 		return nil
 	}
 	
+	// 官方仓库以外的包来自AUR，安装前审查PKGBUILD（通常已在安装开始前审查过，见 Installer.ReviewAURPackages）
+	if err := y.ReviewIfAUR(ctx, packageName); err != nil {
+		return err
+	}
+	
 	// 构建安装命令
 	// yay -S --noconfirm --needed 包名
 	args := []string{"-S", "--noconfirm", "--needed", packageName}
-	cmd := exec.CommandContext(ctx, "yay", args...)
+	cmd := setProcessGroup(exec.CommandContext(ctx, "yay", args...))
 	
 	y.logger.Debugf("执行命令: yay %s", strings.Join(args, " "))
 	
@@ -94,6 +105,13 @@ func (y *YayManager) Install(ctx context.Context, packageName string) error {
 	if err != nil {
 		y.logger.Errorf("安装 %s 失败: %v", packageName, err)
 		
+		// 检查是否是官方仓库和AUR中都找不到该包
+		if strings.Contains(outputStr, "No AUR package found") ||
+		   strings.Contains(outputStr, "could not find all required packages") ||
+		   strings.Contains(outputStr, "target not found") {
+			return fmt.Errorf("yay: %w: %s", ErrPackageNotFound, packageName)
+		}
+		
 		// 检查是否是权限问题
 		if strings.Contains(outputStr, "sudo: a terminal is required") || 
 		   strings.Contains(outputStr, "sudo: a password is required") ||
@@ -135,6 +153,29 @@ func (y *YayManager) IsInstalled(packageName string) bool {
 	return installed
 }
 
+// InstalledSnapshot 通过 yay -Qq 一次性列出所有已安装的包（包括 AUR 包）
+func (y *YayManager) InstalledSnapshot(ctx context.Context) (*InstalledSet, error) {
+	return queryInstalledNames(ctx, "yay", "-Qq")
+}
+
+// InstalledVersion 返回已安装的版本
+func (y *YayManager) InstalledVersion(packageName string) (string, error) {
+	output, err := exec.Command("yay", "-Q", packageName).Output()
+	if err != nil {
+		return "", err
+	}
+	return parseQueryVersion(string(output))
+}
+
+// AvailableVersion 返回官方仓库或AUR中的版本
+func (y *YayManager) AvailableVersion(packageName string) (string, error) {
+	info, err := y.GetPackageInfo(packageName)
+	if err != nil {
+		return "", err
+	}
+	return info.Version, nil
+}
+
 // Priority 返回优先级（高于pacman，因为yay可以处理官方仓库+AUR）
 func (y *YayManager) Priority() int {
 	return 0 // 最高优先级，优先于pacman
@@ -185,21 +226,28 @@ func (y *YayManager) GetPackageInfo(packageName string) (*AURPackageInfo, error)
 func (y *YayManager) InstallFromAUR(ctx context.Context, packageName string, opts AURInstallOptions) error {
 	y.logger.Infof("从AUR安装包: %s", packageName)
 	
+	if opts.SkipReview {
+		y.logger.Warnf("已跳过 %s 的PKGBUILD审查，存在安全风险", packageName)
+	} else {
+		if y.reviewer == nil {
+			return fmt.Errorf("未配置PKGBUILD审查器，无法安全安装AUR包 %s", packageName)
+		}
+		if err := y.reviewer.Review(ctx, packageName); err != nil {
+			return err
+		}
+	}
+	
 	args := []string{"-S", "--aur"}
 	
 	if opts.NoConfirm {
 		args = append(args, "--noconfirm")
 	}
 	
-	if opts.SkipReview {
-		args = append(args, "--noconfirm") // 跳过PKGBUILD审查
-	} else {
-		y.logger.Warn("AUR包安装需要审查PKGBUILD，建议检查包源代码")
-	}
-	
+	// PKGBUILD 已在此处审查，不再由yay重复询问差异和编辑
+	args = append(args, "--answerdiff", "None", "--answeredit", "None")
 	args = append(args, packageName)
 	
-	cmd := exec.CommandContext(ctx, "yay", args...)
+	cmd := setProcessGroup(exec.CommandContext(ctx, "yay", args...))
 	y.logger.Debugf("执行AUR安装命令: yay %s", strings.Join(args, " "))
 	
 	output, err := cmd.CombinedOutput()
@@ -214,6 +262,27 @@ func (y *YayManager) InstallFromAUR(ctx context.Context, packageName string, opt
 	return nil
 }
 
+// ReviewIfAUR 包不在官方仓库而来自AUR时审查其PKGBUILD，未设置审查器时不审查
+func (y *YayManager) ReviewIfAUR(ctx context.Context, packageName string) error {
+	if y.reviewer == nil || y.isInSyncRepos(ctx, packageName) {
+		return nil
+	}
+	isAUR, err := y.reviewer.IsAURPackage(ctx, packageName)
+	if err != nil {
+		return fmt.Errorf("查询AUR包信息失败: %w", err)
+	}
+	if !isAUR {
+		return nil
+	}
+	return y.reviewer.Review(ctx, packageName)
+}
+
+// isInSyncRepos 检查包是否存在于官方同步仓库
+func (y *YayManager) isInSyncRepos(ctx context.Context, packageName string) bool {
+	cmd := exec.CommandContext(ctx, "pacman", "-Si", packageName)
+	return cmd.Run() == nil
+}
+
 // isArchLinux 检查是否在Arch Linux系统上
 func (y *YayManager) isArchLinux() bool {
 	// 检查 /etc/os-release
@@ -315,13 +384,22 @@ func (y *YayManager) checkPacmanLock() error {
 	return nil
 }
 
-// checkSudoPermissions 检查sudo权限
+// RequiresPrivileges yay 安装仓库包时通过 sudo 调用 pacman
+func (y *YayManager) RequiresPrivileges() bool {
+	return true
+}
+
+// checkSudoPermissions 检查sudo权限（凭据由 Elevator 在运行开始时验证并保持有效）
 func (y *YayManager) checkSudoPermissions() error {
+	if ElevationSkipReason() != "" {
+		return nil
+	}
+	
 	// 测试sudo无密码权限
 	cmd := exec.Command("sudo", "-n", "echo", "test")
 	if err := cmd.Run(); err != nil {
 		y.logger.Warnf("sudo权限检查失败: %v", err)
-		return fmt.Errorf("yay需要sudo权限但当前环境无法提供密码验证\n\n💡 解决方案:\n1. 在真正的终端中运行此命令（推荐）\n2. 配置sudo无密码: 在/etc/sudoers中添加 '%s ALL=(ALL) NOPASSWD: /usr/bin/pacman'\n3. 使用系统包管理器而非yay", os.Getenv("USER"))
+		return fmt.Errorf("yay需要sudo权限但当前环境无法提供密码验证\n\n💡 解决方案:\n1. 在真正的终端中运行此命令（推荐），安装开始时会提示输入一次sudo密码\n2. 配置sudo无密码: 在/etc/sudoers中添加 '%s ALL=(ALL) NOPASSWD: /usr/bin/pacman'\n3. 使用系统包管理器而非yay", os.Getenv("USER"))
 	}
 	
 	y.logger.Debugf("sudo权限检查通过")
@@ -353,4 +431,25 @@ type AURPackageInfo struct {
 type AURInstallOptions struct {
 	NoConfirm  bool // 不要求确认
 	SkipReview bool // 跳过PKGBUILD审查（有安全风险）
-}
\ No newline at end of file
+}
+
+// ListExplicitPackages 列出从AUR安装的外部包（yay -Qm）
+func (y *YayManager) ListExplicitPackages(ctx context.Context) ([]ImportedPackage, error) {
+	output, err := exec.CommandContext(ctx, "yay", "-Qm").Output()
+	if err != nil {
+		// 没有外部包时 yay 返回非零退出码
+		if len(strings.TrimSpace(string(output))) == 0 {
+			return []ImportedPackage{}, nil
+		}
+		return nil, fmt.Errorf("yay -Qm 执行失败: %w", err)
+	}
+	
+	var names []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 {
+			names = append(names, fields[0])
+		}
+	}
+	
+	return listLocalPackages(ctx, "yay", names)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/sirupsen/logrus"
@@ -219,7 +220,7 @@ func (p *PackageSelectionScenario) selectRecommendedPackages() error {
 	recommended := p.getRecommendedPackages()
 	
 	if len(recommended) == 0 {
-		fmt.Printf("%s 未找到推荐包，切换到分类选择模式\n", p.theme.Icons.Warning)
+		fmt.Printf("%s 没有未安装的推荐包，切换到分类选择模式\n", p.theme.Icons.Warning)
 		return p.selectByCategory()
 	}
 	
@@ -319,14 +320,17 @@ func (p *PackageSelectionScenario) selectPackagesInCategory(category string) err
 		if pkg.Optional {
 			option += " [可选]"
 		}
+		if p.isInstalled(name) {
+			option += " [已安装]"
+		}
 		packageOptions = append(packageOptions, option)
 		packageNames = append(packageNames, name)
 	}
 	
-	// 默认选择必需包
+	// 默认选择未安装的必需包
 	var defaultSelection []string
 	for i, name := range packageNames {
-		if pkg, exists := categoryInfo.Packages[name]; exists && !pkg.Optional {
+		if pkg, exists := categoryInfo.Packages[name]; exists && !pkg.Optional && !p.isInstalled(name) {
 			defaultSelection = append(defaultSelection, packageOptions[i])
 		}
 	}
@@ -377,6 +381,9 @@ func (p *PackageSelectionScenario) selectByPackage() error {
 		if pkg.Optional {
 			option += " [可选]"
 		}
+		if p.isInstalled(pkg.Name) {
+			option += " [已安装]"
+		}
 		packageOptions = append(packageOptions, option)
 	}
 	
@@ -437,6 +444,9 @@ func (p *PackageSelectionScenario) selectBySearch() error {
 		var resultOptions []string
 		for _, pkg := range results {
 			option := fmt.Sprintf("%s - %s", pkg.Name, pkg.Description)
+			if p.isInstalled(pkg.Name) {
+				option += " [已安装]"
+			}
 			resultOptions = append(resultOptions, option)
 		}
 		
@@ -547,6 +557,26 @@ func (p *PackageSelectionScenario) executeInstallation(ctx context.Context) erro
 		Verbose:    true,
 	}
 	
+	// 命令行传入的 --force 和超时选项
+	if force, ok := p.options["force"].(bool); ok {
+		options.Force = force
+	}
+	if timeout, ok := p.options["install_timeout"].(time.Duration); ok {
+		options.InstallTimeout = timeout
+	}
+	if timeout, ok := p.options["post_install_timeout"].(time.Duration); ok {
+		options.PostInstallTimeout = timeout
+	}
+	if runHooks, ok := p.options["run_hooks"].(bool); ok {
+		options.RunHooks = runHooks
+	}
+	if stop, ok := p.options["stop"].(<-chan struct{}); ok {
+		options.Stop = stop
+	}
+	if timeout, ok := p.options["batch_timeout"].(time.Duration); ok {
+		options.BatchTimeout = timeout
+	}
+	
 	results, err := p.installer.InstallPackages(ctx, p.selectedPackages, options)
 	if err != nil {
 		return err
@@ -598,8 +628,8 @@ func (p *PackageSelectionScenario) getRecommendedPackages() []string {
 	// 遍历所有分类，收集推荐包
 	for _, category := range p.packageConfig.Categories {
 		for name, pkg := range category.Packages {
-			// 推荐条件：不是可选包 且 优先级高的分类
-			if !pkg.Optional && category.Priority <= 3 {
+			// 推荐条件：不是可选包 且 优先级高的分类 且 尚未安装
+			if !pkg.Optional && category.Priority <= 3 && !p.isInstalled(name) {
 				recommended = append(recommended, name)
 			}
 		}
@@ -608,6 +638,13 @@ func (p *PackageSelectionScenario) getRecommendedPackages() []string {
 	return recommended
 }
 
+// isInstalled 检查包是否已安装
+//
+// 使用安装器的已安装包快照（每个包管理器只查询一次），不逐包调用包管理器
+func (p *PackageSelectionScenario) isInstalled(packageName string) bool {
+	return p.installer != nil && p.installer.IsPackageInstalled(packageName)
+}
+
 func (p *PackageSelectionScenario) findPackageInfo(packageName string) *config.PackageInfo {
 	for _, category := range p.packageConfig.Categories {
 		if pkg, exists := category.Packages[packageName]; exists {
@@ -0,0 +1,107 @@
+package secrets
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// redactedText 日志中替换密钥值的文本
+const redactedText = "******"
+
+// minRedactLength 短于该长度的值不脱敏（避免把日志中常见的短词替换掉）
+const minRedactLength = 4
+
+var (
+	redactMu     sync.RWMutex
+	redactValues = make(map[string]bool)
+	hookedLogger = make(map[*logrus.Logger]bool)
+)
+
+// redactHook 在日志输出前把已解密的密钥值替换为 ******
+type redactHook struct{}
+
+// InstallRedactor 为 logger 安装脱敏钩子（重复调用只安装一次）
+func InstallRedactor(logger *logrus.Logger) {
+	if logger == nil {
+		return
+	}
+	redactMu.Lock()
+	defer redactMu.Unlock()
+	if hookedLogger[logger] {
+		return
+	}
+	hookedLogger[logger] = true
+	logger.AddHook(redactHook{})
+}
+
+// Levels 所有级别
+func (redactHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire 替换消息和字段中的密钥值
+func (redactHook) Fire(entry *logrus.Entry) error {
+	entry.Message = Redact(entry.Message)
+	for key, value := range entry.Data {
+		switch v := value.(type) {
+		case string:
+			entry.Data[key] = Redact(v)
+		case error:
+			entry.Data[key] = Redact(v.Error())
+		case fmt.Stringer:
+			entry.Data[key] = Redact(v.String())
+		}
+	}
+	return nil
+}
+
+// Redact 将字符串中已解密的密钥值替换为 ******
+func Redact(s string) string {
+	redactMu.RLock()
+	defer redactMu.RUnlock()
+	if len(redactValues) == 0 {
+		return s
+	}
+
+	// 先替换较长的值，避免一个密钥是另一个密钥的子串时泄露剩余部分
+	values := make([]string, 0, len(redactValues))
+	for value := range redactValues {
+		if strings.Contains(s, value) {
+			values = append(values, value)
+		}
+	}
+	sort.Slice(values, func(i, j int) bool { return len(values[i]) > len(values[j]) })
+	for _, value := range values {
+		s = strings.ReplaceAll(s, value, redactedText)
+	}
+	return s
+}
+
+// registerSecret 注册需要脱敏的值
+func registerSecret(value string) {
+	if len(value) < minRedactLength {
+		return
+	}
+	redactMu.Lock()
+	redactValues[value] = true
+	redactMu.Unlock()
+}
+
+// registerValues 注册对象中的所有叶子值
+func registerValues(data map[string]interface{}) {
+	for _, value := range data {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			registerValues(v)
+		case string:
+			registerSecret(v)
+		case nil:
+		default:
+			registerSecret(fmt.Sprint(v))
+		}
+	}
+}
@@ -0,0 +1,506 @@
+package secrets
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	SecretsFile    = "secrets.age"        // 配置目录中的加密文件（ASCII armor 格式，可以提交到仓库）
+	RecipientsFile = "secrets.recipients" // 配置目录中的接收者公钥列表，每行一个
+)
+
+// ErrNotFound 密钥不存在
+var ErrNotFound = errors.New("密钥不存在")
+
+// ErrNoStore 加密文件不存在（尚未设置任何密钥）
+var ErrNoStore = errors.New("密钥文件不存在")
+
+// referencePattern 配置值中的密钥引用: {{ secret "valkey.password" }}
+var referencePattern = regexp.MustCompile(`\{\{\s*secret\s+"([^"]+)"\s*\}\}`)
+
+// Store age 加密的密钥文件
+//
+// 明文是 JSON 对象，密钥名为点分隔的路径（如 valkey.password 对应 {"valkey": {"password": ...}}）。
+// 加密文件只在首次读取时解密，解密后的值会注册到日志脱敏钩子。
+type Store struct {
+	path           string
+	recipientsPath string
+	identityPath   string
+	logger         *logrus.Logger
+	data           map[string]interface{}
+	loaded         bool
+}
+
+// NewStore 创建配置目录中的密钥存储，并为 logger 安装脱敏钩子
+func NewStore(configDir string, logger *logrus.Logger) *Store {
+	InstallRedactor(logger)
+	return &Store{
+		path:           filepath.Join(configDir, SecretsFile),
+		recipientsPath: filepath.Join(configDir, RecipientsFile),
+		identityPath:   IdentityPath(),
+		logger:         logger,
+	}
+}
+
+// IdentityPath 返回 age 身份文件路径: $DOTFILES_AGE_IDENTITY，默认 $XDG_CONFIG_HOME/dotfiles/age/keys.txt
+func IdentityPath() string {
+	if path := os.Getenv("DOTFILES_AGE_IDENTITY"); path != "" {
+		return path
+	}
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		home, _ := os.UserHomeDir()
+		configHome = filepath.Join(home, ".config")
+	}
+	return filepath.Join(configHome, "dotfiles", "age", "keys.txt")
+}
+
+// Path 加密文件路径
+func (s *Store) Path() string {
+	return s.path
+}
+
+// IdentityPath 使用的身份文件路径
+func (s *Store) IdentityPath() string {
+	return s.identityPath
+}
+
+// Exists 加密文件是否存在
+func (s *Store) Exists() bool {
+	_, err := os.Stat(s.path)
+	return err == nil
+}
+
+// HasReferences 判断字符串中是否包含密钥引用
+func HasReferences(value string) bool {
+	return referencePattern.MatchString(value)
+}
+
+// Resolve 将字符串中的 {{ secret "名称" }} 替换为密钥值；不包含引用时不会解密文件
+func (s *Store) Resolve(value string) (string, error) {
+	var resolveErr error
+	resolved := referencePattern.ReplaceAllStringFunc(value, func(match string) string {
+		name := referencePattern.FindStringSubmatch(match)[1]
+		secret, err := s.Get(name)
+		if err != nil && resolveErr == nil {
+			resolveErr = err
+		}
+		return secret
+	})
+	if resolveErr != nil {
+		return "", resolveErr
+	}
+	return resolved, nil
+}
+
+// Get 读取密钥值
+func (s *Store) Get(name string) (string, error) {
+	if err := s.load(); err != nil {
+		return "", err
+	}
+
+	value, ok := lookup(s.data, name)
+	if !ok {
+		return "", fmt.Errorf("%s: %w", name, ErrNotFound)
+	}
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case map[string]interface{}:
+		return "", fmt.Errorf("%s 是分组，包含: %s", name, strings.Join(sortedKeys(v), ", "))
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
+
+// Names 返回所有密钥名（不包含值）
+func (s *Store) Names() ([]string, error) {
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	var names []string
+	collectNames(s.data, "", &names)
+	sort.Strings(names)
+	return names, nil
+}
+
+// Set 设置密钥值并重新加密；加密文件不存在时创建
+func (s *Store) Set(name, value string) error {
+	if err := validateName(name); err != nil {
+		return err
+	}
+	if err := s.loadOrEmpty(); err != nil {
+		return err
+	}
+
+	parts := strings.Split(name, ".")
+	object := s.data
+	for i, part := range parts[:len(parts)-1] {
+		child, exists := object[part]
+		if !exists {
+			next := make(map[string]interface{})
+			object[part] = next
+			object = next
+			continue
+		}
+		next, ok := child.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s 已经是一个值，不能作为分组", strings.Join(parts[:i+1], "."))
+		}
+		object = next
+	}
+	last := parts[len(parts)-1]
+	if existing, ok := object[last].(map[string]interface{}); ok {
+		return fmt.Errorf("%s 是分组（包含 %s），不能直接赋值", name, strings.Join(sortedKeys(existing), ", "))
+	}
+	object[last] = value
+	registerSecret(value)
+
+	return s.save()
+}
+
+// Plaintext 返回解密后的 JSON（用于编辑），加密文件不存在时返回空对象
+func (s *Store) Plaintext() ([]byte, error) {
+	if err := s.loadOrEmpty(); err != nil {
+		return nil, err
+	}
+	return marshalData(s.data)
+}
+
+// SetPlaintext 用编辑后的 JSON 替换全部内容并重新加密
+func (s *Store) SetPlaintext(plaintext []byte) error {
+	var data map[string]interface{}
+	if err := json.Unmarshal(plaintext, &data); err != nil {
+		return fmt.Errorf("密钥文件必须是 JSON 对象: %w", err)
+	}
+	if data == nil {
+		data = make(map[string]interface{})
+	}
+	s.data = data
+	s.loaded = true
+	registerValues(data)
+	return s.save()
+}
+
+// Rekey 更新接收者列表（add 添加、remove 移除公钥）后用全部接收者重新加密
+func (s *Store) Rekey(add, remove []string) (int, error) {
+	if err := s.load(); err != nil {
+		return 0, err
+	}
+
+	recipients, err := s.recipientLines()
+	if err != nil {
+		return 0, err
+	}
+	for _, recipient := range add {
+		if _, err := age.ParseX25519Recipient(recipient); err != nil {
+			return 0, fmt.Errorf("无效的 age 公钥 %s: %w", recipient, err)
+		}
+		if !contains(recipients, recipient) {
+			recipients = append(recipients, recipient)
+		}
+	}
+	kept := recipients[:0]
+	for _, recipient := range recipients {
+		if !contains(remove, recipient) {
+			kept = append(kept, recipient)
+		}
+	}
+	if len(kept) == 0 {
+		return 0, fmt.Errorf("至少需要保留一个接收者")
+	}
+
+	if err := writeRecipients(s.recipientsPath, kept); err != nil {
+		return 0, err
+	}
+	if err := s.save(); err != nil {
+		return 0, err
+	}
+	return len(kept), nil
+}
+
+// load 解密加密文件
+func (s *Store) load() error {
+	if s.loaded {
+		return nil
+	}
+
+	file, err := os.Open(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("%w: %s，使用 dotfiles secret set 创建", ErrNoStore, s.path)
+		}
+		return err
+	}
+	defer file.Close()
+
+	identities, err := s.identities()
+	if err != nil {
+		return err
+	}
+
+	buffered := bufio.NewReader(file)
+	var src io.Reader = buffered
+	if header, _ := buffered.Peek(len(armor.Header)); string(header) == armor.Header {
+		src = armor.NewReader(buffered)
+	}
+	reader, err := age.Decrypt(src, identities...)
+	if err != nil {
+		return fmt.Errorf("解密 %s 失败（身份文件 %s）: %w", s.path, s.identityPath, err)
+	}
+	plaintext, err := io.ReadAll(reader)
+	if err != nil {
+		return fmt.Errorf("解密 %s 失败: %w", s.path, err)
+	}
+
+	var data map[string]interface{}
+	if err := json.Unmarshal(plaintext, &data); err != nil {
+		return fmt.Errorf("密钥文件内容不是 JSON 对象: %w", err)
+	}
+	if data == nil {
+		data = make(map[string]interface{})
+	}
+	registerValues(data)
+	s.data = data
+	s.loaded = true
+	s.logger.Debugf("已解密密钥文件: %s", s.path)
+	return nil
+}
+
+// loadOrEmpty 加密文件不存在时使用空内容
+func (s *Store) loadOrEmpty() error {
+	if !s.Exists() {
+		if !s.loaded {
+			s.data = make(map[string]interface{})
+			s.loaded = true
+		}
+		return nil
+	}
+	return s.load()
+}
+
+// save 加密写入（先写临时文件再重命名）
+func (s *Store) save() error {
+	recipients, err := s.recipients()
+	if err != nil {
+		return err
+	}
+	plaintext, err := marshalData(s.data)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	armorWriter := armor.NewWriter(&buf)
+	writer, err := age.Encrypt(armorWriter, recipients...)
+	if err != nil {
+		return fmt.Errorf("加密失败: %w", err)
+	}
+	if _, err := writer.Write(plaintext); err != nil {
+		return fmt.Errorf("加密失败: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("加密失败: %w", err)
+	}
+	if err := armorWriter.Close(); err != nil {
+		return fmt.Errorf("加密失败: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("写入 %s 失败: %w", s.path, err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("写入 %s 失败: %w", s.path, err)
+	}
+	s.logger.Debugf("已加密写入 %s（%d 个接收者）", s.path, len(recipients))
+	return nil
+}
+
+// identities 读取身份文件
+func (s *Store) identities() ([]age.Identity, error) {
+	file, err := os.Open(s.identityPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("age 身份文件 %s 不存在（可通过 DOTFILES_AGE_IDENTITY 指定）", s.identityPath)
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	identities, err := age.ParseIdentities(file)
+	if err != nil {
+		return nil, fmt.Errorf("解析身份文件 %s 失败: %w", s.identityPath, err)
+	}
+	return identities, nil
+}
+
+// recipients 返回加密使用的接收者：接收者文件不存在时使用身份文件对应的公钥并创建接收者文件
+func (s *Store) recipients() ([]age.Recipient, error) {
+	lines, err := s.recipientLines()
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("%s 中没有接收者", s.recipientsPath)
+	}
+	recipients, err := age.ParseRecipients(strings.NewReader(strings.Join(lines, "\n")))
+	if err != nil {
+		return nil, fmt.Errorf("解析 %s 失败: %w", s.recipientsPath, err)
+	}
+	return recipients, nil
+}
+
+// recipientLines 读取接收者文件中的公钥；文件不存在时从身份文件生成（身份文件也不存在时生成新的身份）
+func (s *Store) recipientLines() ([]string, error) {
+	data, err := os.ReadFile(s.recipientsPath)
+	if err == nil {
+		var lines []string
+		for _, line := range strings.Split(string(data), "\n") {
+			if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+				lines = append(lines, line)
+			}
+		}
+		return lines, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	if _, err := os.Stat(s.identityPath); os.IsNotExist(err) {
+		if err := generateIdentity(s.identityPath); err != nil {
+			return nil, err
+		}
+		s.logger.Warnf("已生成新的 age 身份文件 %s，请妥善备份，丢失后无法解密密钥", s.identityPath)
+	}
+	identities, err := s.identities()
+	if err != nil {
+		return nil, err
+	}
+	var lines []string
+	for _, identity := range identities {
+		if x25519, ok := identity.(*age.X25519Identity); ok {
+			lines = append(lines, x25519.Recipient().String())
+		}
+	}
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("身份文件 %s 中没有 X25519 身份", s.identityPath)
+	}
+	if err := writeRecipients(s.recipientsPath, lines); err != nil {
+		return nil, err
+	}
+	s.logger.Infof("已创建接收者文件 %s", s.recipientsPath)
+	return lines, nil
+}
+
+// generateIdentity 生成新的 X25519 身份文件（权限 0600）
+func generateIdentity(path string) error {
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		return fmt.Errorf("生成 age 身份失败: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	content := fmt.Sprintf("# public key: %s\n%s\n", identity.Recipient(), identity)
+	return os.WriteFile(path, []byte(content), 0600)
+}
+
+// writeRecipients 写入接收者文件
+func writeRecipients(path string, recipients []string) error {
+	content := "# dotfiles secret 的 age 接收者公钥，每行一个；修改后运行 dotfiles secret rekey\n" + strings.Join(recipients, "\n") + "\n"
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("写入 %s 失败: %w", path, err)
+	}
+	return nil
+}
+
+// validateName 密钥名由点分隔的非空段组成
+func validateName(name string) error {
+	if name == "" {
+		return fmt.Errorf("密钥名不能为空")
+	}
+	for _, part := range strings.Split(name, ".") {
+		if part == "" {
+			return fmt.Errorf("无效的密钥名 %q", name)
+		}
+	}
+	return nil
+}
+
+// lookup 按点分隔的路径查找值
+func lookup(data map[string]interface{}, name string) (interface{}, bool) {
+	var current interface{} = data
+	for _, part := range strings.Split(name, ".") {
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if current, ok = object[part]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// collectNames 收集所有叶子节点的路径
+func collectNames(data map[string]interface{}, prefix string, names *[]string) {
+	for key, value := range data {
+		name := key
+		if prefix != "" {
+			name = prefix + "." + key
+		}
+		if child, ok := value.(map[string]interface{}); ok {
+			collectNames(child, name, names)
+			continue
+		}
+		*names = append(*names, name)
+	}
+}
+
+func marshalData(data map[string]interface{}) ([]byte, error) {
+	plaintext, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("序列化密钥失败: %w", err)
+	}
+	return append(plaintext, '\n'), nil
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -6,7 +6,6 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
-	"regexp"
 	"runtime"
 	"strings"
 	"text/template"
@@ -81,6 +80,12 @@ func (e *Engine) createContextFuncMap(context *TemplateContext) template.FuncMap
 		"getVersionManagerEnv": func(vmConfig map[string]interface{}, envKey string) string { // 获取版本管理器环境变量
 			return getVersionManagerEnv(vmConfig, envKey)
 		},
+		"secret": func(name string) (string, error) { // 读取 age 加密的密钥
+			if context.Config == nil || context.Config.Secrets == nil {
+				return "", fmt.Errorf("密钥存储未初始化，无法读取 %s", name)
+			}
+			return context.Config.Secrets.Get(name)
+		},
 	}
 
 	return contextFuncMap
@@ -111,6 +116,7 @@ func (e *Engine) loadTemplate(templateType TemplateType) error {
 	extendedFuncMap["isWSL"] = func() bool { return false }
 	extendedFuncMap["getActiveProxy"] = func() map[string]interface{} { return nil }
 	extendedFuncMap["getVersionManagerEnv"] = func(vmConfig map[string]interface{}, envKey string) string { return "" }
+	extendedFuncMap["secret"] = func(name string) (string, error) { return "", nil }
 
 	// 创建并解析模板
 	tmpl, err := template.New(string(templateType)).
@@ -333,32 +339,17 @@ func xdgPath(xdgType string, ctx *TemplateContext) string {
 	return pathValue.Get(platform)
 }
 
-func expandEnv(input string) string {
-	// 处理bash风格的默认值语法 ${VAR:-default}
-	if strings.Contains(input, "${") && strings.Contains(input, ":-") {
-		// 使用正则表达式匹配 ${VAR:-default} 模式
-		re := regexp.MustCompile(`\$\{([^}:]+):-([^}]*)\}`)
-		result := re.ReplaceAllStringFunc(input, func(match string) string {
-			// 提取变量名和默认值
-			parts := re.FindStringSubmatch(match)
-			if len(parts) == 3 {
-				varName := parts[1]
-				defaultValue := parts[2]
-
-				// 获取环境变量值
-				if envValue := os.Getenv(varName); envValue != "" {
-					return envValue
-				}
-				return defaultValue
-			}
-			return match
-		})
-		// 继续处理剩余的环境变量
-		return os.ExpandEnv(result)
-	}
+// expandEnv 展开环境变量，支持 ${VAR:-默认值}、${VAR:+替代值} 和 ${VAR:?错误信息}，必需变量未设置时模板执行失败
+func expandEnv(input string) (string, error) {
+	return config.ExpandEnv(input)
+}
 
-	// 标准环境变量展开
-	return os.ExpandEnv(input)
+// expandEnvOrKeep 展开环境变量，失败时（如必需变量未设置）保留原值，由生成的 shell 配置在运行时报告
+func expandEnvOrKeep(input string) string {
+	if expanded, err := expandEnv(input); err == nil {
+		return expanded
+	}
+	return input
 }
 
 func quote(str string) string {
@@ -438,7 +429,7 @@ func getPlatformValue(pathValue interface{}) string {
 	switch v := pathValue.(type) {
 	case string:
 		// 直接返回字符串值，需要先展开环境变量
-		return expandEnv(v)
+		return expandEnvOrKeep(v)
 	case config.PathValue:
 		// 使用PathValue的Get方法
 		platform := "linux"
@@ -449,7 +440,7 @@ func getPlatformValue(pathValue interface{}) string {
 			platform = "macos"
 		}
 		result := v.Get(platform)
-		return expandEnv(result)
+		return expandEnvOrKeep(result)
 	case map[string]interface{}:
 		// 处理从JSON反序列化的map（版本管理器的平台特定配置）
 		// 对于shell配置，优先使用zsh平台
@@ -463,7 +454,7 @@ func getPlatformValue(pathValue interface{}) string {
 		for _, platform := range platformKeys {
 			if val, exists := v[platform]; exists {
 				if str, ok := val.(string); ok && str != "" {
-					return expandEnv(str)
+					return expandEnvOrKeep(str)
 				}
 			}
 		}
@@ -471,14 +462,14 @@ func getPlatformValue(pathValue interface{}) string {
 		// 尝试获取默认值
 		if val, exists := v["default"]; exists {
 			if str, ok := val.(string); ok && str != "" {
-				return expandEnv(str)
+				return expandEnvOrKeep(str)
 			}
 		}
 
 		// 最后尝试linux平台（向后兼容）
 		if val, exists := v["linux"]; exists {
 			if str, ok := val.(string); ok && str != "" {
-				return expandEnv(str)
+				return expandEnvOrKeep(str)
 			}
 		}
 	}
@@ -515,7 +506,10 @@ func getActiveProxy(context *TemplateContext) map[string]interface{} {
 	}
 
 	// 展开环境变量获取活跃的profile名称
-	activeProfile := expandEnv(context.ZshConfig.Proxy.ActiveProfile)
+	activeProfile, err := expandEnv(context.ZshConfig.Proxy.ActiveProfile)
+	if err != nil {
+		return nil
+	}
 
 	// 从类型化的Profiles中获取配置
 	if profile, exists := context.ZshConfig.Proxy.Profiles[activeProfile]; exists {
@@ -118,11 +118,17 @@ func (g *Generator) generateSingleConfig(templateType TemplateType, context *Tem
 
 // createTemplateContext 创建模板上下文
 func (g *Generator) createTemplateContext() *TemplateContext {
+	// 只生成 when 条件满足当前平台的函数
+	functions := g.config.Functions
+	if functions != nil {
+		functions = functions.ForPlatform(g.platformInfo)
+	}
+	
 	return &TemplateContext{
 		Platform:    g.platformInfo,
 		Config:      g.config,
 		ZshConfig:   g.config.ZshConfig,
-		Functions:   g.config.Functions,
+		Functions:   functions,
 		User:        g.config.User,
 		Paths:       g.config.Paths,
 		Features:    g.config.Features,
@@ -168,7 +174,11 @@ func (g *Generator) getOutputPath(templateType TemplateType, customOutputDir str
 			if configHome == "" {
 				configHome = "$HOME/.config"
 			}
-			defaultDir = filepath.Join(os.ExpandEnv(configHome), "zsh")
+			expanded, err := config.ExpandEnv(configHome)
+			if err != nil {
+				return "", fmt.Errorf("展开 XDG 配置目录失败: %w", err)
+			}
+			defaultDir = filepath.Join(expanded, "zsh")
 		} else {
 			defaultDir = "$HOME"
 		}
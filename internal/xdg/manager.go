@@ -42,6 +42,34 @@ func (m *Manager) GetXDGPath(dirType XDGDirectory) (string, error) {
 	return m.expandPath(defaultPath), nil
 }
 
+// ExportEnvironment 为未设置的XDG环境变量导出默认值
+//
+// 配置中形如 $XDG_CACHE_HOME/npm 的值和子进程（cargo、npm、pipx 等）都依赖这些变量。
+func (m *Manager) ExportEnvironment() error {
+	envVars := map[XDGDirectory]string{
+		ConfigHome: "XDG_CONFIG_HOME",
+		DataHome:   "XDG_DATA_HOME",
+		StateHome:  "XDG_STATE_HOME",
+		CacheHome:  "XDG_CACHE_HOME",
+	}
+	
+	for dirType, envVar := range envVars {
+		if os.Getenv(envVar) != "" {
+			continue
+		}
+		path, err := m.GetXDGPath(dirType)
+		if err != nil {
+			return err
+		}
+		if err := os.Setenv(envVar, path); err != nil {
+			return fmt.Errorf("设置 %s 失败: %w", envVar, err)
+		}
+		m.logger.Debugf("导出默认 %s=%s", envVar, path)
+	}
+	
+	return nil
+}
+
 // EnsureDirectories 确保所有XDG目录存在
 func (m *Manager) EnsureDirectories() error {
 	directories := []XDGDirectory{
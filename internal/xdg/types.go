@@ -3,8 +3,10 @@ package xdg
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
+	"github.com/bbq191/dotfiles-go/internal/config"
 	"github.com/sirupsen/logrus"
 )
 
@@ -144,9 +146,13 @@ func NewManager(logger *logrus.Logger, platform string) *Manager {
 
 // 内部辅助方法
 func (m *Manager) expandPath(path string) string {
-	// 展开环境变量和用户目录
-	expanded := os.ExpandEnv(path)
-	if expanded[0] == '~' {
+	// 展开环境变量（支持 ${VAR:-默认值} 等 POSIX 参数展开）和用户目录
+	expanded, err := config.ExpandEnv(path)
+	if err != nil {
+		m.logger.Warnf("展开路径 %s 失败: %v", path, err)
+		expanded = path
+	}
+	if strings.HasPrefix(expanded, "~") {
 		home, _ := os.UserHomeDir()
 		expanded = filepath.Join(home, expanded[1:])
 	}